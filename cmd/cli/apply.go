@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integr8ly/smtp-service/pkg/batch"
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "create, refresh or delete the api keys of the clusters listed in a manifest, outputting their secrets as yaml",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			exitError("failed to get file flag", exitCodeErrUnknown)
+		}
+		concurrency, err := cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			exitError("failed to get concurrency flag", exitCodeErrUnknown)
+		}
+		rate, err := cmd.Flags().GetFloat64("rate")
+		if err != nil {
+			exitError("failed to get rate flag", exitCodeErrUnknown)
+		}
+		secretName, err := cmd.Flags().GetString("secret-name")
+		if err != nil {
+			exitError("failed to get secret name flag", exitCodeErrUnknown)
+		}
+		resultsFile, err := cmd.Flags().GetString("results-file")
+		if err != nil {
+			exitError("failed to get results file flag", exitCodeErrUnknown)
+		}
+		if file == "" {
+			exitError("a manifest must be provided with --file, use - to read from stdin", exitCodeErrValidation)
+		}
+		if concurrency < 1 {
+			exitError("concurrency must be at least 1", exitCodeErrValidation)
+		}
+		raw, err := readInput(file)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to read manifest %s: %v", file, err), errExitCode(err, exitCodeErrKnown))
+		}
+		manifest, err := batch.ParseManifest(raw)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid manifest: %v", err), exitCodeErrValidation)
+		}
+		// validate the client configuration once, before any cluster is handled
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		clientFactory := func(item *batch.Item) (smtpdetails.Client, error) {
+			opts := []sendgrid.ClientOption{sendgrid.WithAPIKeyScopes(item.Scopes)}
+			if item.IPPool != "" {
+				opts = append(opts, sendgrid.WithIPPool(item.IPPool))
+			}
+			return setupSMTPDetailsClient(logger, opts...)
+		}
+		ifExists, err := cmd.Flags().GetString("if-exists")
+		if err != nil {
+			exitError("failed to get if exists flag", exitCodeErrUnknown)
+		}
+		existsPolicy, err := smtpdetails.ParseExistsPolicy(ifExists)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid if exists policy: %v", err), exitCodeErrValidation)
+		}
+		config := &batch.Config{
+			IfExists:      existsPolicy,
+			Concurrency:   concurrency,
+			Rate:          rate,
+			SecretName:    secretName,
+			SecretOptions: []smtpdetails.SecretOption{smtpdetails.WithSecretKeys(getSecretKeys(cmd))},
+		}
+		runner, err := batch.NewRunner(clientFactory, config, logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create runner: %v", err), errExitCode(err, exitCodeErrKnown))
+		}
+		results := runner.Run(manifest)
+		failed := 0
+		for _, result := range results {
+			if result.Status == batch.StatusFailed {
+				failed++
+				continue
+			}
+			if result.Action == batch.ActionDelete {
+				forgetCluster(result.ClusterID)
+				continue
+			}
+			if result.Status == batch.StatusSucceeded {
+				recordCluster(smtpDetailsClient, result.ClusterID, result.Action == batch.ActionRefresh || result.Existed)
+			}
+		}
+		if resultsFile != "" {
+			resultsJSON, err := json.MarshalIndent(results, "", "    ")
+			if err != nil {
+				exitError(fmt.Sprintf("failed to marshal results: %v", err), exitCodeErrUnknown)
+			}
+			if err := ioutil.WriteFile(resultsFile, resultsJSON, 0644); err != nil {
+				exitError(fmt.Sprintf("failed to write results file %s: %v", resultsFile, err), errExitCode(err, exitCodeErrUnknown))
+			}
+		}
+		w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CLUSTER\tACTION\tSTATUS\tERROR")
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.ClusterID, result.Action, result.Status, result.Error)
+		}
+		w.Flush()
+		var out bytes.Buffer
+		if err := batch.WriteSecrets(&out, results); err != nil {
+			exitError(fmt.Sprintf("failed to output secrets: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if failed > 0 {
+			exitFailure(out.String(), fmt.Sprintf("%d of %d clusters failed", failed, len(results)), exitCodeErrKnown)
+		}
+		exitSuccess(out.String())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringP("file", "f", "", "Manifest listing the clusters to create, refresh or delete, in json or yaml format, use - to read from stdin")
+	applyCmd.Flags().Int("concurrency", batch.DefaultConcurrency, "Number of clusters handled in parallel")
+	applyCmd.Flags().Float64("rate", 0, "Maximum number of clusters started per second, unlimited if 0")
+	applyCmd.Flags().StringP("secret-name", "s", defaultOutputSecretName, "Name of the output secrets of clusters without a secret name")
+	applyCmd.Flags().String("if-exists", string(smtpdetails.ExistsPolicyError), fmt.Sprintf("Policy of the create action when the api key of a cluster already exists, one of %v", smtpdetails.ExistsPolicies))
+	applyCmd.Flags().String("results-file", "", "File to write the result of each cluster to in json format")
+	addSecretKeyFlags(applyCmd)
+	addDryRunFlag(applyCmd)
+}
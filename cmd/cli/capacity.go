@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/spf13/cobra"
+)
+
+// capacityCmd represents the capacity command
+var capacityCmd = &cobra.Command{
+	Use:   "capacity",
+	Short: "report the sub users, api keys, ip addresses and credits of each sendgrid account, exiting non-zero when a limit is nearly reached",
+	Run: func(cmd *cobra.Command, args []string) {
+		threshold, err := cmd.Flags().GetFloat64("threshold")
+		if err != nil {
+			exitError("failed to get threshold flag", exitCodeErrUnknown)
+		}
+		if threshold <= 0 || threshold > 1 {
+			exitError(fmt.Sprintf("invalid threshold %v, must be greater than 0 and at most 1", threshold), exitCodeErrValidation)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		capacities, err := smtpDetailsClient.Capacity(threshold)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to get capacity: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		var output string
+		if flagOutput == outputJSON {
+			capacitiesJSON, err := json.MarshalIndent(capacities, "", "    ")
+			if err != nil {
+				exitError(fmt.Sprintf("failed to marshal capacity: %v", err), exitCodeErrUnknown)
+			}
+			output = string(capacitiesJSON)
+		} else {
+			output, err = formatCapacities(capacities)
+			if err != nil {
+				exitError(fmt.Sprintf("failed to format capacity: %v", err), exitCodeErrUnknown)
+			}
+		}
+		warnings := 0
+		for _, capacity := range capacities {
+			warnings += len(capacity.Warnings)
+		}
+		if warnings > 0 {
+			exitFailure(output, fmt.Sprintf("%d capacity warnings, at least %.0f%% of a limit is in use", warnings, threshold*100), exitCodeErrQuotaExceeded)
+		}
+		exitSuccess(output)
+	},
+}
+
+//formatCapacities Format the capacity of each account as a table, followed by its warnings
+func formatCapacities(capacities []*sendgrid.Capacity) (string, error) {
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tSUB USERS\tMAX KEYS PER SUB USER\tIPS\tCREDITS USED\tWARNINGS")
+	for _, capacity := range capacities {
+		subUsers := fmt.Sprintf("%d/%d", capacity.SubUsers, capacity.MaxSubUsers)
+		if capacity.MaxSubUsers == 0 {
+			subUsers = fmt.Sprintf("%d/unlimited", capacity.SubUsers)
+		}
+		credits := "-"
+		if capacity.Credits != nil {
+			credits = fmt.Sprintf("%d/%d", capacity.Credits.Used, capacity.Credits.Total)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%d (%d warming up)\t%s\t%d\n", capacity.Account, subUsers, capacity.APIKeys, capacity.MaxAPIKeys, capacity.IPs, capacity.WarmupIPs, credits, len(capacity.Warnings))
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	for _, capacity := range capacities {
+		if len(capacity.Warnings) > 0 {
+			fmt.Fprintf(&out, "\nwarnings for account %s:\n  %s\n", capacity.Account, strings.Join(capacity.Warnings, "\n  "))
+		}
+	}
+	return out.String(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(capacityCmd)
+	capacityCmd.Flags().Float64("threshold", sendgrid.DefaultCapacityThreshold, "Fraction of a limit in use at which a warning is raised, e.g. 0.8 for 80%")
+}
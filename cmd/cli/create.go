@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
 	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
 	"github.com/spf13/cobra"
 )
@@ -14,36 +15,133 @@ var createCmd = &cobra.Command{
 	Short: "create sendgrid sub user and api key associated with [cluster id]",
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		var clientOpts []sendgrid.ClientOption
+		monitorEmail, err := cmd.Flags().GetString("monitor-email")
 		if err != nil {
-			exitError("failed to setup smtp details client", exitCodeErrUnknown)
+			exitError("failed to get monitor email flag", exitCodeErrUnknown)
 		}
-		smtpDetails, err := smtpDetailsClient.Create(args[0])
+		if monitorEmail != "" {
+			monitorFrequency, err := cmd.Flags().GetInt("monitor-frequency")
+			if err != nil {
+				exitError("failed to get monitor frequency flag", exitCodeErrUnknown)
+			}
+			clientOpts = append(clientOpts, sendgrid.WithSubUserMonitor(monitorEmail, monitorFrequency))
+		}
+		webhookURL, err := cmd.Flags().GetString("webhook-url")
 		if err != nil {
-			if smtpdetails.IsAlreadyExistsError(err) {
-				exitError(fmt.Sprintf("api key for cluster %s already exists", args[0]), exitCodeErrKnown)
+			exitError("failed to get webhook url flag", exitCodeErrUnknown)
+		}
+		if webhookURL != "" {
+			webhookEvents, err := cmd.Flags().GetStringSlice("webhook-events")
+			if err != nil {
+				exitError("failed to get webhook events flag", exitCodeErrUnknown)
 			}
-			exitError(fmt.Sprintf("unknown error: %v", err), exitCodeErrUnknown)
+			clientOpts = append(clientOpts, sendgrid.WithEventWebhook(webhookURL, webhookEvents))
 		}
-		logger.Debug("smtp details created successfully, converting to secret")
-		secretName, err := cmd.Flags().GetString("secret-name")
+		tier, err := cmd.Flags().GetString("tier")
+		if err != nil {
+			exitError("failed to get tier flag", exitCodeErrUnknown)
+		}
+		if tier != "" {
+			tierPoolPairs, err := cmd.Flags().GetStringSlice("tier-ip-pools")
+			if err != nil {
+				exitError("failed to get tier ip pools flag", exitCodeErrUnknown)
+			}
+			tierPools, err := sendgrid.ParseTierIPPools(tierPoolPairs)
+			if err != nil {
+				exitError(fmt.Sprintf("invalid tier ip pools: %v", err), exitCodeErrValidation)
+			}
+			pool, err := tierPools.Pool(tier)
+			if err != nil {
+				exitError(fmt.Sprintf("invalid tier: %v", err), exitCodeErrValidation)
+			}
+			clientOpts = append(clientOpts, sendgrid.WithIPPool(pool))
+		}
+		excludeWarmupIPs, err := cmd.Flags().GetBool("exclude-warmup-ips")
+		if err != nil {
+			exitError("failed to get exclude warmup ips flag", exitCodeErrUnknown)
+		}
+		if excludeWarmupIPs {
+			clientOpts = append(clientOpts, sendgrid.WithExcludeWarmupIPs())
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger, clientOpts...)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		ifExists, err := cmd.Flags().GetString("if-exists")
 		if err != nil {
-			exitError("failed to get secret name flag", exitCodeErrUnknown)
+			exitError("failed to get if exists flag", exitCodeErrUnknown)
 		}
-		if secretName == "" {
-			logger.Infof("secret name is blank, using default name %s", defaultOutputSecretName)
-			secretName = defaultOutputSecretName
+		existsPolicy, err := smtpdetails.ParseExistsPolicy(ifExists)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid if exists policy: %v", err), exitCodeErrValidation)
 		}
-		smtpSecret := smtpdetails.ConvertSMTPDetailsToSecret(smtpDetails, secretName)
-		smtpJSON, err := json.MarshalIndent(smtpSecret, "", "    ")
+		smtpDetails, existed, err := smtpdetails.CreateWithPolicy(smtpDetailsClient, args[0], existsPolicy)
 		if err != nil {
-			exitError(fmt.Sprintf("error converting details to secret: %v", err), exitCodeErrUnknown)
+			if smtpdetails.IsAlreadyExistsError(err) {
+				exitError(fmt.Sprintf("api key for cluster %s already exists, use --if-exists skip or rotate to re-run create", args[0]), exitCodeErrAlreadyExists)
+			}
+			if smtpdetails.IsQuotaExceededError(err) {
+				exitError(fmt.Sprintf("quota exceeded for cluster %s, see the capacity command: %v", args[0], err), exitCodeErrQuotaExceeded)
+			}
+			exitError(fmt.Sprintf("unknown error: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if existed && existsPolicy == smtpdetails.ExistsPolicySkip {
+			existingJSON, err := json.MarshalIndent(newExistingOutput(args[0], smtpDetails), "", "    ")
+			if err != nil {
+				exitError(fmt.Sprintf("failed to marshal existing smtp details: %v", err), exitCodeErrUnknown)
+			}
+			exitSuccess(string(existingJSON))
 		}
-		exitSuccess(string(smtpJSON))
+		recordCluster(smtpDetailsClient, args[0], existed)
+		verify, err := cmd.Flags().GetBool("verify")
+		if err != nil {
+			exitError("failed to get verify flag", exitCodeErrUnknown)
+		}
+		if verify {
+			verifySMTPDetails(cmd, smtpDetails)
+		}
+		logger.Debug("smtp details created successfully, converting to secret")
+		outputSecret(cmd, smtpDetails)
 	},
 }
 
+//existingOutput Metadata of the existing smtp details of a cluster, output by the skip exists policy as the api key
+//cannot be retrieved
+type existingOutput struct {
+	ClusterID string              `json:"clusterId"`
+	Status    string              `json:"status"`
+	APIKey    string              `json:"apiKey"`
+	Host      string              `json:"host"`
+	Port      int                 `json:"port"`
+	TLSMode   smtpdetails.TLSMode `json:"tlsMode"`
+	Username  string              `json:"username"`
+}
+
+func newExistingOutput(clusterID string, smtpDetails *smtpdetails.SMTPDetails) *existingOutput {
+	return &existingOutput{
+		ClusterID: clusterID,
+		Status:    "exists",
+		APIKey:    smtpDetails.ID,
+		Host:      smtpDetails.Host,
+		Port:      smtpDetails.Port,
+		TLSMode:   smtpDetails.GetTLSMode(),
+		Username:  smtpDetails.Username,
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(createCmd)
-	createCmd.Flags().StringP("secret-name", "s", defaultOutputSecretName, "Name of the output secret")
+	createCmd.Flags().String("if-exists", string(smtpdetails.ExistsPolicyError), fmt.Sprintf("Policy when the api key of the cluster already exists, one of %v, skip outputs the existing details without the api key and rotate generates a new api key", smtpdetails.ExistsPolicies))
+	createCmd.Flags().String("monitor-email", "", "Email address to attach to the sub user as a monitor, disabled if blank")
+	createCmd.Flags().Int("monitor-frequency", defaultMonitorFrequency, "Number of emails sent between each copy sent to the monitor email")
+	createCmd.Flags().String("webhook-url", "", "Event webhook url to configure for the sub user, {{.ClusterID}} is replaced with the cluster id, disabled if blank")
+	createCmd.Flags().StringSlice("webhook-events", sendgrid.DefaultEventWebhookEvents, "Event types posted to the event webhook")
+	createCmd.Flags().Bool("exclude-warmup-ips", false, "Fail instead of assigning an ip address that is warming up when no other ip address is available")
+	createCmd.Flags().String("tier", "", "Tier of the cluster, the sub user is assigned an ip address from the ip pool declared for the tier")
+	createCmd.Flags().StringSlice("tier-ip-pools", splitEnvList(sendgrid.EnvTierIPPools), fmt.Sprintf("IP pool declarations in the format tier=pool, defaults to the %s env var", sendgrid.EnvTierIPPools))
+	addSecretOutputFlags(createCmd)
+	createCmd.Flags().Bool("verify", false, "Authenticate against the smtp server with the new api key before outputting the secret")
+	addVerifyFlags(createCmd)
+	addDryRunFlag(createCmd)
 }
@@ -15,18 +15,21 @@ var deleteCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
 		if err != nil {
-			exitError("failed to setup smtp details client", exitCodeErrUnknown)
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
 		}
 		if err := smtpDetailsClient.Delete(args[0]); err != nil {
 			if smtpdetails.IsNotExistError(err) {
-				exitError(fmt.Sprintf("api key for cluster %s does not exist: %+v", args[0], err), exitCodeErrKnown)
+				forgetCluster(args[0])
+				exitError(fmt.Sprintf("api key for cluster %s does not exist: %+v", args[0], err), exitCodeErrNotFound)
 			}
-			exitError(fmt.Sprintf("failed to delete api key %v", err), exitCodeErrUnknown)
+			exitError(fmt.Sprintf("failed to delete api key %v", err), errExitCode(err, exitCodeErrUnknown))
 		}
+		forgetCluster(args[0])
 		exitSuccess("api key deleted")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
+	addDryRunFlag(deleteCmd)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	exitCodeErrNotFound      = 3
+	exitCodeErrAlreadyExists = 4
+	exitCodeErrAuth          = 5
+	exitCodeErrForbidden     = 6
+	exitCodeErrRateLimited   = 7
+	exitCodeErrQuotaExceeded = 8
+	exitCodeErrNetwork       = 9
+	exitCodeErrValidation    = 10
+)
+
+//exitCodeErrorTypes Error type reported in the json output for each exit code
+var exitCodeErrorTypes = map[int]string{
+	exitCodeErrKnown:         "known",
+	exitCodeErrUnknown:       "unknown",
+	exitCodeErrNotFound:      "not_found",
+	exitCodeErrAlreadyExists: "already_exists",
+	exitCodeErrAuth:          "auth_failure",
+	exitCodeErrForbidden:     "forbidden",
+	exitCodeErrRateLimited:   "rate_limited",
+	exitCodeErrQuotaExceeded: "quota_exceeded",
+	exitCodeErrNetwork:       "network",
+	exitCodeErrValidation:    "validation",
+}
+
+//errorType Machine-readable type of the error behind an exit code
+func errorType(code int) string {
+	if t, ok := exitCodeErrorTypes[code]; ok {
+		return t
+	}
+	return exitCodeErrorTypes[exitCodeErrUnknown]
+}
+
+//errExitCode Exit code of the type of an error, or the fallback code if the error is not typed
+func errExitCode(err error, fallback int) int {
+	cause := errors.Cause(err)
+	switch {
+	case smtpdetails.IsNotExistError(cause), sendgrid.IsNotExistError(cause), store.IsNotExistError(cause), kubernetes.IsNotExistError(cause):
+		return exitCodeErrNotFound
+	case smtpdetails.IsAlreadyExistsError(cause), sendgrid.IsAlreadyExistsError(cause):
+		return exitCodeErrAlreadyExists
+	case smtpdetails.IsAuthenticationError(cause):
+		return exitCodeErrAuth
+	case smtpdetails.IsQuotaExceededError(cause):
+		return exitCodeErrQuotaExceeded
+	case smtpdetails.IsValidationError(cause), smtpdetails.IsMissingSecretKeyError(cause), smtpdetails.IsInvalidSecretValueError(cause):
+		return exitCodeErrValidation
+	case smtpdetails.IsConnectionError(cause):
+		return exitCodeErrNetwork
+	}
+	if apiErr, ok := cause.(*sendgrid.APIError); ok {
+		switch apiErr.StatusCode {
+		case http.StatusBadRequest:
+			return exitCodeErrValidation
+		case http.StatusUnauthorized:
+			return exitCodeErrAuth
+		case http.StatusForbidden:
+			return exitCodeErrForbidden
+		case http.StatusNotFound:
+			return exitCodeErrNotFound
+		case http.StatusTooManyRequests:
+			return exitCodeErrRateLimited
+		}
+	}
+	if _, ok := cause.(net.Error); ok {
+		return exitCodeErrNetwork
+	}
+	return fallback
+}
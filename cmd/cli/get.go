@@ -15,14 +15,14 @@ var getCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
 		if err != nil {
-			exitError("failed to setup smtp details client", exitCodeErrUnknown)
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
 		}
 		smtpDetails, err := smtpDetailsClient.Get(args[0])
 		if err != nil {
 			if smtpdetails.IsNotExistError(err) {
-				exitError(fmt.Sprintf("api key for cluster %s not found", args[0]), exitCodeErrKnown)
+				exitError(fmt.Sprintf("api key for cluster %s not found", args[0]), exitCodeErrNotFound)
 			}
-			exitError(fmt.Sprintf("unknown error: %v", err), exitCodeErrUnknown)
+			exitError(fmt.Sprintf("unknown error: %v", err), errExitCode(err, exitCodeErrUnknown))
 		}
 		exitSuccess(smtpDetails.ID)
 	},
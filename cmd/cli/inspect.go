@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+const redactedPassword = "REDACTED"
+
+//inspectOutput SMTP details read from a secret, with the password redacted unless requested
+type inspectOutput struct {
+	Host     string              `json:"host"`
+	Port     int                 `json:"port"`
+	TLS      bool                `json:"tls"`
+	TLSMode  smtpdetails.TLSMode `json:"tlsMode"`
+	Username string              `json:"username"`
+	Password string              `json:"password"`
+}
+
+// inspectCmd represents the inspect command
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "read and validate the smtp details of a secret manifest in json or yaml format",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			exitError("failed to get file flag", exitCodeErrUnknown)
+		}
+		if file == "" {
+			exitError("a secret manifest must be provided with --file, use - to read from stdin", exitCodeErrValidation)
+		}
+		manifest, err := readInput(file)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to read secret manifest %s: %v", file, err), errExitCode(err, exitCodeErrKnown))
+		}
+		secret, err := smtpdetails.ParseSecret(manifest)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid secret manifest: %v", err), exitCodeErrValidation)
+		}
+		smtpDetails, err := smtpdetails.ConvertSecretToSMTPDetails(secret, getSecretKeys(cmd))
+		if err != nil {
+			if smtpdetails.IsMissingSecretKeyError(err) || smtpdetails.IsInvalidSecretValueError(err) {
+				exitError(fmt.Sprintf("invalid smtp details: %v", err), exitCodeErrValidation)
+			}
+			exitError(fmt.Sprintf("unknown error: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		verify, err := cmd.Flags().GetBool("verify")
+		if err != nil {
+			exitError("failed to get verify flag", exitCodeErrUnknown)
+		}
+		if verify {
+			verifySMTPDetails(cmd, smtpDetails)
+		}
+		showPassword, err := cmd.Flags().GetBool("show-password")
+		if err != nil {
+			exitError("failed to get show password flag", exitCodeErrUnknown)
+		}
+		output := &inspectOutput{
+			Host:     smtpDetails.Host,
+			Port:     smtpDetails.Port,
+			TLS:      smtpDetails.TLS,
+			TLSMode:  smtpDetails.GetTLSMode(),
+			Username: smtpDetails.Username,
+			Password: redactedPassword,
+		}
+		if showPassword {
+			output.Password = smtpDetails.Password
+		}
+		outputJSON, err := json.MarshalIndent(output, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("error converting details to json: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(string(outputJSON))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+	inspectCmd.Flags().StringP("file", "f", "", "Secret manifest to inspect, - to read from stdin")
+	addSecretKeyFlags(inspectCmd)
+	inspectCmd.Flags().Bool("show-password", false, "Output the password instead of redacting it")
+	inspectCmd.Flags().Bool("verify", false, "Authenticate against the smtp server with the details of the secret")
+	addVerifyFlags(inspectCmd)
+}
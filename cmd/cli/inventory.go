@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "list the clusters tracked in the state file with their sub users, api keys and key ages",
+	Run: func(cmd *cobra.Command, args []string) {
+		records, err := openStore(true).List()
+		if err != nil {
+			exitError(fmt.Sprintf("failed to list clusters of state file: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		now := time.Now()
+		fmt.Fprintln(w, "CLUSTER\tPROVIDER\tACCOUNT\tSUB USER\tKEY ID\tSCOPES\tCREATED\tKEY AGE")
+		for _, record := range records {
+			account := record.Account
+			if account == "" {
+				account = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", record.ClusterID, record.Provider, account, record.SubUser, record.KeyID, strings.Join(record.Scopes, ","), record.CreatedAt.Format(time.RFC3339), formatKeyAge(now.Sub(record.KeyIssuedAt())))
+		}
+		if err := w.Flush(); err != nil {
+			exitError(fmt.Sprintf("failed to format clusters: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(out.String())
+	},
+}
+
+// inventoryReconcileCmd represents the inventory reconcile command
+var inventoryReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "compare the state file against the sendgrid sub users, reporting unmanaged and missing clusters",
+	Run: func(cmd *cobra.Command, args []string) {
+		update, err := cmd.Flags().GetBool("update")
+		if err != nil {
+			exitError("failed to get update flag", exitCodeErrUnknown)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		entries, err := store.Reconcile(openStore(true), smtpDetailsClient, update, time.Now().UTC())
+		if err != nil {
+			exitError(fmt.Sprintf("failed to reconcile state file: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CLUSTER\tSTATUS")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%s\n", entry.ClusterID, entry.Status)
+		}
+		if err := w.Flush(); err != nil {
+			exitError(fmt.Sprintf("failed to format clusters: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(out.String())
+	},
+}
+
+//formatKeyAge Format the age of an api key in days
+func formatKeyAge(age time.Duration) string {
+	return fmt.Sprintf("%dd", int(age.Hours()/24))
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.AddCommand(inventoryReconcileCmd)
+	inventoryReconcileCmd.Flags().Bool("update", false, "Track unmanaged clusters, remove missing clusters and refresh the sub user and api key details of the state file")
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+// ipsCmd represents the ips command
+var ipsCmd = &cobra.Command{
+	Use:   "ips",
+	Short: "list the sendgrid ip addresses with their pools, warmup status and assigned sub user counts",
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		ips, err := accountClient.ListIPAddresses()
+		if err != nil {
+			exitError(fmt.Sprintf("failed to list ip addresses: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		now := time.Now()
+		fmt.Fprintln(w, "IP\tPOOLS\tWARMUP\tSUB USERS")
+		for _, ip := range ips {
+			warmup := "no"
+			if ip.Warmup {
+				warmup = fmt.Sprintf("day %d", ip.DaysInWarmup(now))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", ip.IP, strings.Join(ip.Pools, ","), warmup, len(ip.SubUsers))
+		}
+		if err := w.Flush(); err != nil {
+			exitError(fmt.Sprintf("failed to format ip addresses: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(out.String())
+	},
+}
+
+// ipsPoolCmd represents the ips pool command
+var ipsPoolCmd = &cobra.Command{
+	Use:   "pool [sub command]",
+	Short: "manage sendgrid ip pools",
+}
+
+// ipsPoolListCmd represents the ips pool list command
+var ipsPoolListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the sendgrid ip pools",
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		pools, err := accountClient.ListIPPools()
+		if err != nil {
+			exitError(fmt.Sprintf("failed to list ip pools: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		var names []string
+		for _, pool := range pools {
+			names = append(names, pool.Name)
+		}
+		exitSuccess(strings.Join(names, "\n"))
+	},
+}
+
+// ipsPoolCreateCmd represents the ips pool create command
+var ipsPoolCreateCmd = &cobra.Command{
+	Use:   "create [pool name]",
+	Short: "create a sendgrid ip pool named [pool name]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if _, err := accountClient.CreateIPPool(args[0]); err != nil {
+			exitError(fmt.Sprintf("failed to create ip pool: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("ip pool created")
+	},
+}
+
+// ipsPoolAddCmd represents the ips pool add command
+var ipsPoolAddCmd = &cobra.Command{
+	Use:   "add [pool name] [ip]",
+	Short: "add [ip] to the sendgrid ip pool named [pool name]",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if err := accountClient.AddIPToPool(args[0], args[1]); err != nil {
+			exitError(fmt.Sprintf("failed to add ip to pool: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("ip added to pool")
+	},
+}
+
+// ipsPoolRemoveCmd represents the ips pool remove command
+var ipsPoolRemoveCmd = &cobra.Command{
+	Use:   "remove [pool name] [ip]",
+	Short: "remove [ip] from the sendgrid ip pool named [pool name]",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if err := accountClient.RemoveIPFromPool(args[0], args[1]); err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("ip %s is not in pool %s", args[1], args[0]), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("failed to remove ip from pool: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("ip removed from pool")
+	},
+}
+
+// ipsWarmupCmd represents the ips warmup command
+var ipsWarmupCmd = &cobra.Command{
+	Use:   "warmup [sub command]",
+	Short: "manage the warmup of sendgrid ip addresses",
+}
+
+// ipsWarmupStartCmd represents the ips warmup start command
+var ipsWarmupStartCmd = &cobra.Command{
+	Use:   "start [ip]",
+	Short: "start warming up [ip]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if _, err := accountClient.StartIPWarmup(args[0]); err != nil {
+			exitError(fmt.Sprintf("failed to start ip warmup: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("ip warmup started")
+	},
+}
+
+// ipsWarmupStopCmd represents the ips warmup stop command
+var ipsWarmupStopCmd = &cobra.Command{
+	Use:   "stop [ip]",
+	Short: "stop warming up [ip]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if err := accountClient.StopIPWarmup(args[0]); err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("ip %s is not warming up", args[0]), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("failed to stop ip warmup: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("ip warmup stopped")
+	},
+}
+
+// ipsWarmupStatusCmd represents the ips warmup status command
+var ipsWarmupStatusCmd = &cobra.Command{
+	Use:   "status [ip]",
+	Short: "show the warmup status of [ip], or of all warming ip addresses if [ip] is not provided",
+	Run: func(cmd *cobra.Command, args []string) {
+		accountClient, err := setupAccountClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup account client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		var warmupIPs []*sendgrid.WarmupIP
+		if len(args) > 0 {
+			warmupIP, err := accountClient.GetIPWarmupStatus(args[0])
+			if err != nil {
+				if smtpdetails.IsNotExistError(err) {
+					exitSuccess(fmt.Sprintf("ip %s is not warming up", args[0]))
+				}
+				exitError(fmt.Sprintf("failed to get ip warmup status: %v", err), errExitCode(err, exitCodeErrUnknown))
+			}
+			warmupIPs = append(warmupIPs, warmupIP)
+		} else {
+			warmupIPs, err = accountClient.ListWarmupIPs()
+			if err != nil {
+				exitError(fmt.Sprintf("failed to list warmup ip addresses: %v", err), errExitCode(err, exitCodeErrUnknown))
+			}
+		}
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		now := time.Now()
+		fmt.Fprintln(w, "IP\tSTARTED\tDAYS")
+		for _, warmupIP := range warmupIPs {
+			started := time.Unix(int64(warmupIP.StartDate), 0).UTC().Format("2006-01-02")
+			fmt.Fprintf(w, "%s\t%s\t%d\n", warmupIP.IP, started, warmupIP.DaysInWarmup(now))
+		}
+		if err := w.Flush(); err != nil {
+			exitError(fmt.Sprintf("failed to format ip warmup status: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(out.String())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ipsCmd)
+	ipsCmd.PersistentFlags().StringVar(&flagAccount, "account", "", fmt.Sprintf("Name of the master account of the %s env var to manage the ip addresses of, defaults to the first account", sendgrid.EnvAccounts))
+	ipsCmd.AddCommand(ipsPoolCmd, ipsWarmupCmd)
+	ipsWarmupCmd.AddCommand(ipsWarmupStartCmd, ipsWarmupStopCmd, ipsWarmupStatusCmd)
+	ipsPoolCmd.AddCommand(ipsPoolListCmd, ipsPoolCreateCmd, ipsPoolAddCmd, ipsPoolRemoveCmd)
+	addDryRunFlag(ipsPoolCreateCmd)
+	addDryRunFlag(ipsPoolAddCmd)
+	addDryRunFlag(ipsPoolRemoveCmd)
+	addDryRunFlag(ipsWarmupStartCmd)
+	addDryRunFlag(ipsWarmupStopCmd)
+}
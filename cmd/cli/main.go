@@ -2,9 +2,14 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
@@ -13,11 +18,38 @@ import (
 
 const (
 	defaultOutputSecretName = "redhat-rhmi-smtp"
+	defaultMonitorFrequency = 1000
 	exitCodeErrKnown        = 1
 	exitCodeErrUnknown      = 2
+	envSMTPPassword         = "SMTP_PASSWORD"
+	envSMTPFrom             = "SMTP_FROM"
+	envSecretKeyPreset      = "SMTP_SECRET_KEY_PRESET"
+	envSecretKeyMap         = "SMTP_SECRET_KEY_MAP"
+	envIDPrefix             = "SMTP_ID_PREFIX"
+	envIDLowercase          = "SMTP_ID_LOWERCASE"
+	envIDHashLong           = "SMTP_ID_HASH_LONG"
+	envIDValidate           = "SMTP_ID_VALIDATE"
 )
 
 var flagDebug = false
+var flagRegion = ""
+var flagAPIHost = ""
+var flagStateFile = ""
+var flagDryRun = false
+var flagIDPrefix = ""
+var flagIDLowercase = false
+var flagIDHashLong = false
+var flagIDValidate = false
+var flagPlacement = ""
+var flagMaxSubUsers = 0
+var flagAccount = ""
+var flagRateLimitRetries = 0
+
+//idPolicy Mapping of cluster ids to sub user usernames built from the id flags
+var idPolicy *smtpdetails.IDPolicy
+
+//dryRunPlan Mutations planned by the smtp details clients of a dry run, nil unless --dry-run is used
+var dryRunPlan *sendgrid.Plan
 var logger = logrus.NewEntry(&logrus.Logger{
 	Out:          os.Stderr,
 	Formatter:    &logrus.TextFormatter{},
@@ -29,39 +61,134 @@ var logger = logrus.NewEntry(&logrus.Logger{
 var rootCmd = &cobra.Command{
 	Use:   "cli [sub command]",
 	Short: "commands for managing rhmi cluster api keys",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if strings.Contains(cmd.Use, "[cluster id]") && len(args) > 0 {
+			resultClusterID = args[0]
+		}
+	},
+}
+
+func setupSMTPDetailsClient(logger *logrus.Entry, opts ...sendgrid.ClientOption) (*sendgrid.ShardedClient, error) {
+	region, err := sendgrid.GetRegion(flagRegion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sendgrid region")
+	}
+	placement, err := sendgrid.ParsePlacement(flagPlacement)
+	if err != nil {
+		return nil, err
+	}
+	endpointOpts := []sendgrid.ClientOption{sendgrid.WithRegion(region)}
+	if flagAPIHost != "" {
+		endpointOpts = append(endpointOpts, sendgrid.WithAPIHost(flagAPIHost))
+	}
+	if dryRunPlan != nil {
+		endpointOpts = append(endpointOpts, sendgrid.WithDryRun(dryRunPlan))
+	}
+	endpointOpts = append(endpointOpts, sendgrid.WithIDPolicy(idPolicy), sendgrid.WithRateLimitRetries(flagRateLimitRetries))
+	smtpdetailsClient, err := sendgrid.NewDefaultShardedClient(logger, placement, flagMaxSubUsers, append(endpointOpts, opts...)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sendgrid details client")
+	}
+	return smtpdetailsClient, nil
+}
+
+//setupAccountClient Create the client of the master account selected by the account flag, for commands managing
+//resources of a single account such as ip addresses
+func setupAccountClient(logger *logrus.Entry) (*sendgrid.Client, error) {
+	smtpdetailsClient, err := setupSMTPDetailsClient(logger)
+	if err != nil {
+		return nil, err
+	}
+	return smtpdetailsClient.Account(flagAccount)
 }
 
-func exitSuccess(message string) {
-	fmt.Fprintf(os.Stdout, message)
-	os.Exit(0)
+//addDryRunFlag Add the dry run flag to a command mutating sendgrid resources
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Perform the read calls and output the planned mutations without executing them")
 }
 
-func exitError(message string, code int) {
-	fmt.Fprintf(os.Stderr, message)
-	os.Exit(code)
+//readInput Read a file, or stdin if path is -
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(path)
 }
 
-func setupSMTPDetailsClient(logger *logrus.Entry) (*sendgrid.Client, error) {
-	smtpdetailsClient, err := sendgrid.NewDefaultClient(logger)
+//canonicalClusterID Canonical form of a cluster id under the id policy, used to key the state file, the id is returned
+//unchanged if it is invalid
+func canonicalClusterID(id string) string {
+	canonical, err := idPolicy.Normalize(id)
 	if err != nil {
-		logger.Fatalf("failed to create sendgrid details client: %v", err)
-		return nil, errors.Wrap(err, "failed to setup sendgrid smtp details client")
+		return id
 	}
-	return smtpdetailsClient, nil
+	return canonical
+}
+
+//envBool Whether a boolean env var is set to true
+func envBool(env string) bool {
+	value, err := strconv.ParseBool(os.Getenv(env))
+	return err == nil && value
+}
+
+//envInt Value of an integer env var, zero if it is not set or invalid
+func envInt(env string) int {
+	value, err := strconv.Atoi(os.Getenv(env))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func splitEnvList(env string) []string {
+	value := os.Getenv(env)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
 }
 
 func init() {
 	cobra.OnInitialize(func() {
-		if flagDebug {
-			logger.Logger.SetLevel(logrus.DebugLevel)
+		if err := setupLogger(); err != nil {
+			exitError(fmt.Sprintf("invalid log flags: %v", err), exitCodeErrValidation)
+		}
+		if flagOutput != outputText && flagOutput != outputJSON {
+			exitError(fmt.Sprintf("invalid output %s, must be one of %s or %s", flagOutput, outputText, outputJSON), exitCodeErrValidation)
+		}
+		if flagDryRun {
+			dryRunPlan = &sendgrid.Plan{}
+		}
+		idPolicy = &smtpdetails.IDPolicy{}
+		if flagIDValidate {
+			idPolicy = sendgrid.NewIDPolicy()
+		} else if flagIDHashLong {
+			// hashing shortens ids to the maximum length, even if they are not validated otherwise
+			idPolicy.MaxLength = sendgrid.SubUserUsernameMaxLength
 		}
+		idPolicy.Prefix = flagIDPrefix
+		idPolicy.Lowercase = flagIDLowercase
+		idPolicy.HashLongIDs = flagIDHashLong
 	})
-	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Enable debug output to stderr")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Enable debug output to stderr, overrides --log-level")
+	rootCmd.PersistentFlags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "Level of the log output to stderr, one of trace, debug, info, warning, error or fatal")
+	rootCmd.PersistentFlags().StringVar(&flagLogFormat, "log-format", flagLogFormat, fmt.Sprintf("Format of the log output to stderr, one of %s or %s", logFormatText, logFormatJSON))
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", flagOutput, fmt.Sprintf("Format of the command result, %s or %s, json writes a result envelope with the status, cluster id and error to stdout", outputText, outputJSON))
+	rootCmd.PersistentFlags().StringVar(&flagRegion, "region", os.Getenv(sendgrid.EnvRegion), fmt.Sprintf("SendGrid region selecting the api and smtp hosts, global or eu, defaults to the %s env var", sendgrid.EnvRegion))
+	rootCmd.PersistentFlags().StringVar(&flagAPIHost, "api-host", os.Getenv(sendgrid.EnvAPIHost), fmt.Sprintf("SendGrid api host overriding the host of the region, defaults to the %s env var", sendgrid.EnvAPIHost))
+	rootCmd.PersistentFlags().StringVar(&flagIDPrefix, "id-prefix", os.Getenv(envIDPrefix), fmt.Sprintf("Prefix prepended to cluster ids to form sub user usernames, e.g. rhmi-, defaults to the %s env var", envIDPrefix))
+	rootCmd.PersistentFlags().BoolVar(&flagIDLowercase, "id-lowercase", envBool(envIDLowercase), fmt.Sprintf("Lowercase cluster ids before mapping them to sub user usernames, defaults to the %s env var", envIDLowercase))
+	rootCmd.PersistentFlags().BoolVar(&flagIDValidate, "id-validate", envBool(envIDValidate), fmt.Sprintf("Reject cluster ids that are not valid sub user usernames before any request is sent, defaults to the %s env var", envIDValidate))
+	rootCmd.PersistentFlags().BoolVar(&flagIDHashLong, "id-hash-long", envBool(envIDHashLong), fmt.Sprintf("Shorten cluster ids too long for a sub user username with a hash instead of rejecting them, defaults to the %s env var", envIDHashLong))
+	rootCmd.PersistentFlags().StringVar(&flagPlacement, "placement", os.Getenv(sendgrid.EnvPlacement), fmt.Sprintf("Policy placing the sub users of new clusters in one of the master accounts of the %s env var, one of %v, defaults to the %s env var or fill-first", sendgrid.EnvAccounts, sendgrid.Placements, sendgrid.EnvPlacement))
+	rootCmd.PersistentFlags().IntVar(&flagMaxSubUsers, "max-sub-users", envInt(sendgrid.EnvMaxSubUsers), fmt.Sprintf("Maximum number of sub users of each master account, 0 is unlimited, defaults to the %s env var", sendgrid.EnvMaxSubUsers))
+	rootCmd.PersistentFlags().IntVar(&flagRateLimitRetries, "rate-limit-retries", 0, "Number of times a sendgrid api request rate limited with a 429 response is retried, waiting until the rate limit resets")
+	rootCmd.PersistentFlags().StringVar(&flagMetricsFile, "metrics-file", "", "Path of a file the sendgrid api and operation metrics are written to when the cli exits, in the Prometheus text format")
+	rootCmd.PersistentFlags().StringVar(&flagStateFile, "state-file", os.Getenv(store.EnvStorePath), fmt.Sprintf("Path of the file tracking the clusters and api keys created by the cli, defaults to the %s env var", store.EnvStorePath))
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		exitError(err.Error(), exitCodeErrValidation)
 	}
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/integr8ly/smtp-service/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+var flagMetricsFile = ""
+
+//writeMetricsFile Dump the metrics of the cli to the metrics file before it exits, unless no metrics file is set
+func writeMetricsFile() {
+	if flagMetricsFile == "" {
+		return
+	}
+	if err := metrics.Default.WriteFile(flagMetricsFile); err != nil {
+		logger.Warnf("failed to write metrics file: %v", err)
+	}
+}
+
+//addMetricsAddressFlag Add the metrics address flag to a long running command
+func addMetricsAddressFlag(cmd *cobra.Command) {
+	cmd.Flags().String("metrics-address", "", fmt.Sprintf("Address to serve the sendgrid api and operation metrics on at %s, disabled if blank", metrics.Route))
+}
+
+//serveMetrics Serve the metrics at the address of the metrics address flag in the background, unless it is blank
+func serveMetrics(cmd *cobra.Command) {
+	address, err := cmd.Flags().GetString("metrics-address")
+	if err != nil {
+		exitError("failed to get metrics address flag", exitCodeErrUnknown)
+	}
+	if address == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metrics.Route, metrics.Default.Handler())
+	go func() {
+		logger.Infof("serving metrics on %s%s", address, metrics.Route)
+		if err := http.ListenAndServe(address, mux); err != nil {
+			exitError(fmt.Sprintf("metrics server stopped: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+// monitorCmd represents the monitor command
+var monitorCmd = &cobra.Command{
+	Use:   "monitor [sub command]",
+	Short: "manage the sendgrid sub user monitor associated with a cluster",
+}
+
+// monitorSetCmd represents the monitor set command
+var monitorSetCmd = &cobra.Command{
+	Use:   "set [cluster id]",
+	Short: "create or update the monitor of the sendgrid sub user associated with [cluster id]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		email, err := cmd.Flags().GetString("email")
+		if err != nil {
+			exitError("failed to get email flag", exitCodeErrUnknown)
+		}
+		frequency, err := cmd.Flags().GetInt("frequency")
+		if err != nil {
+			exitError("failed to get frequency flag", exitCodeErrUnknown)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		monitor, err := smtpDetailsClient.SetMonitor(args[0], email, frequency)
+		if err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("sub user for cluster %s does not exist", args[0]), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("failed to set monitor: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		monitorJSON, err := json.MarshalIndent(monitor, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("error converting monitor to json: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(string(monitorJSON))
+	},
+}
+
+// monitorGetCmd represents the monitor get command
+var monitorGetCmd = &cobra.Command{
+	Use:   "get [cluster id]",
+	Short: "get the monitor of the sendgrid sub user associated with [cluster id]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		monitor, err := smtpDetailsClient.GetMonitor(args[0])
+		if err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("monitor for cluster %s not found", args[0]), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("unknown error: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		monitorJSON, err := json.MarshalIndent(monitor, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("error converting monitor to json: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(string(monitorJSON))
+	},
+}
+
+// monitorDeleteCmd represents the monitor delete command
+var monitorDeleteCmd = &cobra.Command{
+	Use:   "delete [cluster id]",
+	Short: "delete the monitor of the sendgrid sub user associated with [cluster id]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if err := smtpDetailsClient.DeleteMonitor(args[0]); err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("monitor for cluster %s does not exist: %+v", args[0], err), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("failed to delete monitor %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("monitor deleted")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+	monitorCmd.AddCommand(monitorSetCmd, monitorGetCmd, monitorDeleteCmd)
+	monitorSetCmd.Flags().String("email", "", "Email address that receives the sampled messages")
+	monitorSetCmd.Flags().Int("frequency", defaultMonitorFrequency, "Number of emails sent between each copy sent to the monitor email")
+	if err := monitorSetCmd.MarkFlagRequired("email"); err != nil {
+		panic(err)
+	}
+	addDryRunFlag(monitorSetCmd)
+	addDryRunFlag(monitorDeleteCmd)
+}
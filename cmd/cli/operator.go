@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/integr8ly/smtp-service/pkg/operator"
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const defaultResyncPeriod = 5 * time.Minute
+
+// operatorCmd represents the operator command
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "reconcile SMTPCredential custom resources, creating, rotating and deleting their smtp details",
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			exitError("failed to get namespace flag", exitCodeErrUnknown)
+		}
+		kubeconfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			exitError("failed to get kubeconfig flag", exitCodeErrUnknown)
+		}
+		resyncPeriod, err := cmd.Flags().GetDuration("resync-period")
+		if err != nil {
+			exitError("failed to get resync period flag", exitCodeErrUnknown)
+		}
+		region, err := sendgrid.GetRegion(flagRegion)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid region: %v", err), exitCodeErrValidation)
+		}
+		placement, err := sendgrid.ParsePlacement(flagPlacement)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid placement: %v", err), exitCodeErrValidation)
+		}
+		kubeConfig, err := kubernetes.LoadConfig(kubeconfig)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to load kubernetes config: %v", err), errExitCode(err, exitCodeErrKnown))
+		}
+		kubeClient, err := kubernetes.NewClient(kubeConfig, logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create kubernetes client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		clientFactory := func(credential *operator.SMTPCredential) (smtpdetails.Client, error) {
+			if credential.Spec.Provider != "" && credential.Spec.Provider != sendgrid.ProviderName {
+				return nil, errors.New(fmt.Sprintf("unsupported provider %s", credential.Spec.Provider))
+			}
+			opts := []sendgrid.ClientOption{sendgrid.WithRegion(region), sendgrid.WithAPIKeyScopes(credential.Spec.Scopes), sendgrid.WithIDPolicy(idPolicy), sendgrid.WithRateLimitRetries(flagRateLimitRetries)}
+			if flagAPIHost != "" {
+				opts = append(opts, sendgrid.WithAPIHost(flagAPIHost))
+			}
+			return sendgrid.NewDefaultShardedClient(logger, placement, flagMaxSubUsers, opts...)
+		}
+		reconciler, err := operator.NewReconciler(kubeClient, clientFactory, logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create reconciler: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		serveMetrics(cmd)
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-signals
+			close(stop)
+		}()
+		logger.Infof("reconciling smtp credentials, namespace=%s resync=%s", namespace, resyncPeriod)
+		if err := operator.NewController(kubeClient, reconciler, namespace, resyncPeriod, logger).Run(stop); err != nil {
+			exitError(fmt.Sprintf("operator failed: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess("operator stopped")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(operatorCmd)
+	operatorCmd.Flags().StringP("namespace", "n", "", "Namespace to watch for SMTPCredentials, all namespaces if blank")
+	operatorCmd.Flags().String("kubeconfig", "", fmt.Sprintf("Path of the kubeconfig, defaults to the %s env var, ~/.kube/config or the in-cluster config", kubernetes.EnvKubeconfig))
+	addMetricsAddressFlag(operatorCmd)
+	operatorCmd.Flags().Duration("resync-period", defaultResyncPeriod, "Period after which all SMTPCredentials are reconciled, to rotate api keys and retry failures")
+}
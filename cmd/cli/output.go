@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outputText    = "text"
+	outputJSON    = "json"
+	logFormatText = "text"
+	logFormatJSON = "json"
+	statusSuccess = "success"
+	statusError   = "error"
+)
+
+var flagOutput = outputText
+var flagLogFormat = logFormatText
+var flagLogLevel = logrus.FatalLevel.String()
+
+//resultClusterID Cluster id argument of the executed command, included in the json output
+var resultClusterID = ""
+
+//result Envelope of the outcome of a command written to stdout with --output json
+type result struct {
+	Status    string      `json:"status"`
+	ClusterID string      `json:"clusterId,omitempty"`
+	Result    interface{} `json:"result,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ExitCode  int         `json:"exitCode"`
+}
+
+//setupLogger Configure the format and level of the logger from the log flags
+func setupLogger() error {
+	switch flagLogFormat {
+	case logFormatText:
+		logger.Logger.SetFormatter(&logrus.TextFormatter{})
+	case logFormatJSON:
+		logger.Logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("invalid log format %s, must be one of %s or %s", flagLogFormat, logFormatText, logFormatJSON)
+	}
+	level, err := logrus.ParseLevel(flagLogLevel)
+	if err != nil {
+		return err
+	}
+	if flagDebug {
+		level = logrus.DebugLevel
+	}
+	logger.Logger.SetLevel(level)
+	return nil
+}
+
+//resultValue Embed output as json if it is valid json, otherwise as a string
+func resultValue(output string) interface{} {
+	trimmed := bytes.TrimSpace([]byte(output))
+	if len(trimmed) > 0 && json.Valid(trimmed) {
+		return json.RawMessage(trimmed)
+	}
+	return output
+}
+
+//exitWith Write the output and error message of a command, as text or as a json result, and exit
+func exitWith(output, message string, code int) {
+	if flagOutput == outputJSON {
+		r := &result{Status: statusSuccess, ClusterID: resultClusterID, ExitCode: code}
+		if output != "" {
+			r.Result = resultValue(output)
+		}
+		if code != 0 {
+			r.Status = statusError
+			r.ErrorType = errorType(code)
+			r.Error = message
+		}
+		resultJSON, err := json.MarshalIndent(r, "", "    ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal result: %v", err)
+			exit(exitCodeErrUnknown)
+		}
+		fmt.Fprintln(os.Stdout, string(resultJSON))
+		exit(code)
+	}
+	if output != "" {
+		fmt.Fprint(os.Stdout, output)
+	}
+	if message != "" {
+		fmt.Fprint(os.Stderr, message)
+	}
+	exit(code)
+}
+
+func exitSuccess(message string) {
+	if dryRunPlan != nil {
+		exitDryRun()
+	}
+	exitWith(message, "", 0)
+}
+
+//exitDryRun Output the mutations planned by a dry run instead of the result of the command
+func exitDryRun() {
+	mutations := dryRunPlan.Mutations()
+	if flagOutput == outputJSON {
+		planned := make([]string, 0, len(mutations))
+		for _, m := range mutations {
+			planned = append(planned, m.String())
+		}
+		plannedJSON, err := json.Marshal(map[string][]string{"plannedMutations": planned})
+		if err != nil {
+			exitError(fmt.Sprintf("failed to marshal planned mutations: %v", err), exitCodeErrUnknown)
+		}
+		exitWith(string(plannedJSON), "", 0)
+	}
+	if len(mutations) == 0 {
+		fmt.Fprintln(os.Stdout, "dry run, no mutations planned")
+		exit(0)
+	}
+	fmt.Fprintln(os.Stdout, "dry run, planned mutations:")
+	for _, m := range mutations {
+		fmt.Fprintf(os.Stdout, "  %s\n", m)
+	}
+	exit(0)
+}
+
+func exitError(message string, code int) {
+	exitWith("", message, code)
+}
+
+//exitFailure Exit with an error while still outputting the partial result of the command
+func exitFailure(output, message string, code int) {
+	exitWith(output, message, code)
+}
+
+//exit Write the metrics file and exit with the code
+func exit(code int) {
+	writeMetricsFile()
+	os.Exit(code)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/integr8ly/smtp-service/pkg/prune"
+	"github.com/spf13/cobra"
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "report and delete sendgrid sub users of clusters missing from a list of known clusters",
+	Run: func(cmd *cobra.Command, args []string) {
+		knownClusters, err := cmd.Flags().GetString("known-clusters")
+		if err != nil {
+			exitError("failed to get known clusters flag", exitCodeErrUnknown)
+		}
+		pattern, err := cmd.Flags().GetString("pattern")
+		if err != nil {
+			exitError("failed to get pattern flag", exitCodeErrUnknown)
+		}
+		minAge, err := cmd.Flags().GetDuration("min-age")
+		if err != nil {
+			exitError("failed to get min age flag", exitCodeErrUnknown)
+		}
+		confirm, err := cmd.Flags().GetBool("confirm")
+		if err != nil {
+			exitError("failed to get confirm flag", exitCodeErrUnknown)
+		}
+		allSubUsers, err := cmd.Flags().GetBool("all-subusers")
+		if err != nil {
+			exitError("failed to get all subusers flag", exitCodeErrUnknown)
+		}
+		if knownClusters == "" {
+			exitError("a list of known cluster ids must be provided with --known-clusters, use - to read from stdin", exitCodeErrValidation)
+		}
+		raw, err := readInput(knownClusters)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to read known clusters %s: %v", knownClusters, err), errExitCode(err, exitCodeErrKnown))
+		}
+		known, err := prune.ParseKnownClusters(bytes.NewReader(raw))
+		if err != nil {
+			exitError(fmt.Sprintf("invalid known clusters: %v", err), exitCodeErrValidation)
+		}
+		// sub users are listed by canonical id, known ids must be compared in the same form
+		for i, id := range known {
+			known[i] = canonicalClusterID(id)
+		}
+		if minAge > 0 && flagStateFile == "" {
+			exitError("--min-age requires a state file tracking the age of clusters, see --state-file", exitCodeErrValidation)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		pruner, err := prune.NewPruner(smtpDetailsClient, smtpDetailsClient, openStore(false), logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create pruner: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		report, err := pruner.Prune(known, &prune.Config{
+			Pattern:     pattern,
+			MinAge:      minAge,
+			Confirm:     confirm,
+			Scoped:      flagIDPrefix != "",
+			AllSubUsers: allSubUsers,
+		})
+		if err != nil {
+			exitError(fmt.Sprintf("failed to prune: %v", err), errExitCode(err, exitCodeErrKnown))
+		}
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("failed to marshal prune report: %v", err), exitCodeErrUnknown)
+		}
+		if report.Failed > 0 {
+			exitFailure(string(reportJSON)+"\n", fmt.Sprintf("failed to delete %d orphan clusters", report.Failed), exitCodeErrKnown)
+		}
+		exitSuccess(string(reportJSON))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().StringP("known-clusters", "f", "", "File listing the ids of all live clusters, one per line, use - to read from stdin")
+	pruneCmd.Flags().String("pattern", "", "Only prune orphan clusters with an id matching the glob pattern, e.g. test-*")
+	pruneCmd.Flags().Duration("min-age", 0, "Only prune orphan clusters created at least this long ago, requires a state file")
+	pruneCmd.Flags().Bool("confirm", false, "Delete the orphan clusters, otherwise they are only reported")
+	addDryRunFlag(pruneCmd)
+	pruneCmd.Flags().Bool("all-subusers", false, "Allow --confirm to delete orphans without --id-prefix, when every sub user of the account is a candidate")
+}
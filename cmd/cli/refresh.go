@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
@@ -16,33 +15,31 @@ var refreshCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
 		if err != nil {
-			exitError("failed to setup smtp details client", exitCodeErrUnknown)
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
 		}
 		smtpDetails, err := smtpDetailsClient.Refresh(args[0])
 		if err != nil {
 			if smtpdetails.IsNotExistError(err) {
-				exitError(fmt.Sprintf("cannot create api key for cluster that does not exist, cluster=%s, use the create command", args[0]), exitCodeErrKnown)
+				exitError(fmt.Sprintf("cannot create api key for cluster that does not exist, cluster=%s, use the create command", args[0]), exitCodeErrNotFound)
 			}
-			exitError(fmt.Sprintf("failed to delete api key %v: ", err), exitCodeErrUnknown)
+			exitError(fmt.Sprintf("failed to delete api key %v: ", err), errExitCode(err, exitCodeErrUnknown))
 		}
-		secretName, err := cmd.Flags().GetString("secret-name")
+		recordCluster(smtpDetailsClient, args[0], true)
+		verify, err := cmd.Flags().GetBool("verify")
 		if err != nil {
-			exitError("failed to get secret name flag", exitCodeErrUnknown)
+			exitError("failed to get verify flag", exitCodeErrUnknown)
 		}
-		if secretName == "" {
-			logger.Infof("secret name is blank, using default name %s", defaultOutputSecretName)
-			secretName = defaultOutputSecretName
+		if verify {
+			verifySMTPDetails(cmd, smtpDetails)
 		}
-		smtpSecret := smtpdetails.ConvertSMTPDetailsToSecret(smtpDetails, secretName)
-		smtpJSON, err := json.MarshalIndent(smtpSecret, "", "    ")
-		if err != nil {
-			exitError(fmt.Sprintf("error converting details to secret: %v", err), exitCodeErrUnknown)
-		}
-		exitSuccess(string(smtpJSON))
+		outputSecret(cmd, smtpDetails)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(refreshCmd)
-	refreshCmd.Flags().StringP("secret-name", "s", defaultOutputSecretName, "Name of the output secret")
+	addSecretOutputFlags(refreshCmd)
+	refreshCmd.Flags().Bool("verify", false, "Authenticate against the smtp server with the new api key before outputting the secret")
+	addVerifyFlags(refreshCmd)
+	addDryRunFlag(refreshCmd)
 }
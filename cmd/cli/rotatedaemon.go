@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/integr8ly/smtp-service/pkg/rotation"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	sinkStdout     = "stdout"
+	sinkDir        = "dir"
+	sinkKubernetes = "kubernetes"
+)
+
+// rotateDaemonCmd represents the rotate-daemon command
+var rotateDaemonCmd = &cobra.Command{
+	Use:   "rotate-daemon",
+	Short: "periodically rotate api keys older than a max age and emit the new secrets to a sink",
+	Run: func(cmd *cobra.Command, args []string) {
+		maxAge, err := cmd.Flags().GetDuration("max-age")
+		if err != nil {
+			exitError("failed to get max age flag", exitCodeErrUnknown)
+		}
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			exitError("failed to get interval flag", exitCodeErrUnknown)
+		}
+		once, err := cmd.Flags().GetBool("once")
+		if err != nil {
+			exitError("failed to get once flag", exitCodeErrUnknown)
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			exitError("failed to get dry run flag", exitCodeErrUnknown)
+		}
+		concurrency, err := cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			exitError("failed to get concurrency flag", exitCodeErrUnknown)
+		}
+		rotateUnknown, err := cmd.Flags().GetBool("rotate-unknown")
+		if err != nil {
+			exitError("failed to get rotate unknown flag", exitCodeErrUnknown)
+		}
+		if maxAge <= 0 {
+			exitError("max age must be greater than zero", exitCodeErrValidation)
+		}
+		if concurrency < 1 {
+			exitError("concurrency must be at least 1", exitCodeErrValidation)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		sink, fallback := getRotationSinks(cmd)
+		config := &rotation.Config{
+			MaxAge:        maxAge,
+			Concurrency:   concurrency,
+			DryRun:        dryRun,
+			RotateUnknown: rotateUnknown,
+			Fallback:      fallback,
+		}
+		rotator, err := rotation.NewRotator(smtpDetailsClient, smtpDetailsClient, openStore(true), sink, config, logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create rotator: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		if once {
+			results, err := rotator.RunOnce()
+			if err != nil {
+				exitError(fmt.Sprintf("rotation failed: %v", err), errExitCode(err, exitCodeErrUnknown))
+			}
+			resultsJSON, err := json.MarshalIndent(results, "", "    ")
+			if err != nil {
+				exitError(fmt.Sprintf("failed to marshal rotation results: %v", err), exitCodeErrUnknown)
+			}
+			failed := 0
+			for _, result := range results {
+				if result.Action == rotation.ActionFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				exitFailure(string(resultsJSON)+"\n", fmt.Sprintf("failed to rotate api keys of %d clusters", failed), exitCodeErrKnown)
+			}
+			exitSuccess(string(resultsJSON))
+		}
+		// results of every run are logged, make sure they are visible unless a level is chosen
+		if !flagDebug && !cmd.Flags().Changed("log-level") {
+			logger.Logger.SetLevel(logrus.InfoLevel)
+		}
+		serveMetrics(cmd)
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-signals
+			close(stop)
+		}()
+		logger.Infof("rotating api keys older than %s every %s, dry-run=%t", maxAge, interval, dryRun)
+		rotator.Run(interval, stop)
+		exitSuccess("rotate daemon stopped")
+	},
+}
+
+//getRotationSinks Create the sink selected by the sink flags and the fallback sink writing to the fallback dir
+func getRotationSinks(cmd *cobra.Command) (rotation.Sink, rotation.Sink) {
+	sink, err := cmd.Flags().GetString("sink")
+	if err != nil {
+		exitError("failed to get sink flag", exitCodeErrUnknown)
+	}
+	secretName, err := cmd.Flags().GetString("secret-name")
+	if err != nil {
+		exitError("failed to get secret name flag", exitCodeErrUnknown)
+	}
+	nameTemplate, err := rotation.ParseSecretTemplate(secretName)
+	if err != nil {
+		exitError(fmt.Sprintf("invalid secret name: %v", err), exitCodeErrValidation)
+	}
+	keysOpt := smtpdetails.WithSecretKeys(getSecretKeys(cmd))
+	fallbackDir, err := cmd.Flags().GetString("fallback-dir")
+	if err != nil {
+		exitError("failed to get fallback dir flag", exitCodeErrUnknown)
+	}
+	if fallbackDir == "" {
+		statePath := flagStateFile
+		if statePath == "" {
+			statePath = store.DefaultStorePath
+		}
+		fallbackDir = filepath.Dir(statePath)
+	}
+	fallback, err := rotation.NewDirSink(fallbackDir, nameTemplate, keysOpt)
+	if err != nil {
+		exitError(fmt.Sprintf("invalid fallback dir: %v", err), exitCodeErrValidation)
+	}
+	switch sink {
+	case sinkStdout:
+		return rotation.NewWriterSink(os.Stdout, nameTemplate, keysOpt), fallback
+	case sinkDir:
+		outputDir, err := cmd.Flags().GetString("output-dir")
+		if err != nil {
+			exitError("failed to get output dir flag", exitCodeErrUnknown)
+		}
+		dirSink, err := rotation.NewDirSink(outputDir, nameTemplate, keysOpt)
+		if err != nil {
+			exitError(fmt.Sprintf("invalid output dir: %v", err), exitCodeErrValidation)
+		}
+		return dirSink, fallback
+	case sinkKubernetes:
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			exitError("failed to get namespace flag", exitCodeErrUnknown)
+		}
+		kubeconfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			exitError("failed to get kubeconfig flag", exitCodeErrUnknown)
+		}
+		force, err := cmd.Flags().GetBool("force-conflicts")
+		if err != nil {
+			exitError("failed to get force conflicts flag", exitCodeErrUnknown)
+		}
+		var namespaceTemplate *rotation.SecretTemplate
+		if namespace != "" {
+			if namespaceTemplate, err = rotation.ParseSecretTemplate(namespace); err != nil {
+				exitError(fmt.Sprintf("invalid namespace: %v", err), exitCodeErrValidation)
+			}
+		}
+		kubeConfig, err := kubernetes.LoadConfig(kubeconfig)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to load kubernetes config: %v", err), errExitCode(err, exitCodeErrKnown))
+		}
+		kubeClient, err := kubernetes.NewClient(kubeConfig, logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create kubernetes client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		return rotation.NewKubernetesSink(kubeClient, namespaceTemplate, nameTemplate, force, keysOpt), fallback
+	}
+	exitError(fmt.Sprintf("invalid sink %s, must be one of %s, %s or %s", sink, sinkStdout, sinkDir, sinkKubernetes), exitCodeErrValidation)
+	return nil, nil
+}
+
+func init() {
+	rootCmd.AddCommand(rotateDaemonCmd)
+	rotateDaemonCmd.Flags().Duration("max-age", rotation.DefaultMaxAge, "Age after which the api key of a cluster is rotated")
+	rotateDaemonCmd.Flags().Duration("interval", rotation.DefaultInterval, "Interval between rotation runs")
+	rotateDaemonCmd.Flags().Bool("once", false, "Run a single rotation and output the result of each cluster instead of running as a daemon")
+	addMetricsAddressFlag(rotateDaemonCmd)
+	// the daemon has its own dry run, a plan of mutations is only output when a command exits and rotating through the
+	// recording client would emit secrets of api keys that were never created
+	rotateDaemonCmd.Flags().Bool("dry-run", false, "Report the clusters that would be rotated without rotating them or updating the state file")
+	rotateDaemonCmd.Flags().Int("concurrency", rotation.DefaultConcurrency, "Number of clusters rotated in parallel")
+	rotateDaemonCmd.Flags().Bool("rotate-unknown", false, "Rotate clusters missing from the state file, instead of tracking their api key age from the time they are first seen")
+	rotateDaemonCmd.Flags().String("sink", sinkStdout, fmt.Sprintf("Destination of the secrets of rotated api keys, one of %s, %s or %s", sinkStdout, sinkDir, sinkKubernetes))
+	rotateDaemonCmd.Flags().String("output-dir", "", fmt.Sprintf("Directory the %s sink writes a <cluster id>.json secret to", sinkDir))
+	rotateDaemonCmd.Flags().String("fallback-dir", "", "Directory a <cluster id>.json secret is written to when the sink fails to emit it, defaults to the directory of the state file")
+	rotateDaemonCmd.Flags().StringP("secret-name", "s", rotation.DefaultSecretNameTemplate, "Template of the secret name, the cluster id is available as {{.ClusterID}}")
+	addSecretKeyFlags(rotateDaemonCmd)
+	rotateDaemonCmd.Flags().StringP("namespace", "n", "", fmt.Sprintf("Template of the namespace the %s sink applies secrets in, defaults to the namespace of the kubeconfig context or pod", sinkKubernetes))
+	rotateDaemonCmd.Flags().String("kubeconfig", "", fmt.Sprintf("Path of the kubeconfig used by the %s sink, defaults to the %s env var, ~/.kube/config or the in-cluster config", sinkKubernetes, kubernetes.EnvKubeconfig))
+	rotateDaemonCmd.Flags().Bool("force-conflicts", false, "Take ownership of secret fields managed by another field manager when applying")
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+//addSecretKeyFlags Add flags customising the secret data keys to a command
+func addSecretKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("key-preset", os.Getenv(envSecretKeyPreset), fmt.Sprintf("Secret data key preset, one of %s, defaults to the %s env var", strings.Join(smtpdetails.SecretKeyPresetNames(), ", "), envSecretKeyPreset))
+	cmd.Flags().StringSlice("key-map", splitEnvList(envSecretKeyMap), fmt.Sprintf("Secret data key overrides in the format detail=key e.g. host=SMTP_HOST, a blank key omits the detail, defaults to the %s env var", envSecretKeyMap))
+}
+
+//addSecretOutputFlags Add flags for outputting or applying the secret of created smtp details to a command
+func addSecretOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("secret-name", "s", defaultOutputSecretName, "Name of the output secret")
+	addSecretKeyFlags(cmd)
+	cmd.Flags().Bool("apply", false, "Create or update the secret in a Kubernetes cluster with server-side apply instead of outputting it")
+	cmd.Flags().StringP("namespace", "n", "", "Namespace to apply the secret in, defaults to the namespace of the kubeconfig context or pod")
+	cmd.Flags().String("kubeconfig", "", fmt.Sprintf("Path of the kubeconfig used to apply the secret, defaults to the %s env var, ~/.kube/config or the in-cluster config", kubernetes.EnvKubeconfig))
+	cmd.Flags().Bool("force-conflicts", false, "Take ownership of secret fields managed by another field manager when applying")
+}
+
+//getSecretKeys Resolve the secret data keys from the key preset and key map flags
+func getSecretKeys(cmd *cobra.Command) *smtpdetails.SecretKeys {
+	preset, err := cmd.Flags().GetString("key-preset")
+	if err != nil {
+		exitError("failed to get key preset flag", exitCodeErrUnknown)
+	}
+	keyMap, err := cmd.Flags().GetStringSlice("key-map")
+	if err != nil {
+		exitError("failed to get key map flag", exitCodeErrUnknown)
+	}
+	keys, err := smtpdetails.GetSecretKeyPreset(preset)
+	if err != nil {
+		exitError(fmt.Sprintf("invalid key preset: %v", err), exitCodeErrValidation)
+	}
+	keys, err = smtpdetails.ParseSecretKeyMap(keys, keyMap)
+	if err != nil {
+		exitError(fmt.Sprintf("invalid key map: %v", err), exitCodeErrValidation)
+	}
+	return keys
+}
+
+//outputSecret Convert smtp details to a secret and either output it or apply it to a cluster
+func outputSecret(cmd *cobra.Command, smtpDetails *smtpdetails.SMTPDetails) {
+	if dryRunPlan != nil {
+		exitDryRun()
+	}
+	secretName, err := cmd.Flags().GetString("secret-name")
+	if err != nil {
+		exitError("failed to get secret name flag", exitCodeErrUnknown)
+	}
+	if secretName == "" {
+		logger.Infof("secret name is blank, using default name %s", defaultOutputSecretName)
+		secretName = defaultOutputSecretName
+	}
+	smtpSecret := smtpdetails.ConvertSMTPDetailsToSecret(smtpDetails, secretName, smtpdetails.WithSecretKeys(getSecretKeys(cmd)))
+	apply, err := cmd.Flags().GetBool("apply")
+	if err != nil {
+		exitError("failed to get apply flag", exitCodeErrUnknown)
+	}
+	if !apply {
+		smtpJSON, err := json.MarshalIndent(smtpSecret, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("error converting details to secret: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(string(smtpJSON))
+	}
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		exitError("failed to get namespace flag", exitCodeErrUnknown)
+	}
+	kubeconfig, err := cmd.Flags().GetString("kubeconfig")
+	if err != nil {
+		exitError("failed to get kubeconfig flag", exitCodeErrUnknown)
+	}
+	force, err := cmd.Flags().GetBool("force-conflicts")
+	if err != nil {
+		exitError("failed to get force conflicts flag", exitCodeErrUnknown)
+	}
+	kubeConfig, err := kubernetes.LoadConfig(kubeconfig)
+	if err != nil {
+		exitError(fmt.Sprintf("failed to load kubernetes config: %v", err), errExitCode(err, exitCodeErrKnown))
+	}
+	kubeClient, err := kubernetes.NewClient(kubeConfig, logger)
+	if err != nil {
+		exitError(fmt.Sprintf("failed to create kubernetes client: %v", err), errExitCode(err, exitCodeErrUnknown))
+	}
+	applied, err := kubeClient.ApplySecret(namespace, smtpSecret, force)
+	if err != nil {
+		if kubernetes.IsConflictError(err) {
+			exitError(fmt.Sprintf("%v, use --force-conflicts to take ownership of the fields", err), exitCodeErrKnown)
+		}
+		exitError(fmt.Sprintf("failed to apply secret: %v", err), errExitCode(err, exitCodeErrUnknown))
+	}
+	exitSuccess(fmt.Sprintf("secret %s/%s applied", applied.Namespace, applied.Name))
+}
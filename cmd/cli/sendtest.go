@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+// sendTestCmd represents the send-test command
+var sendTestCmd = &cobra.Command{
+	Use:   "send-test [cluster id]",
+	Short: "send a diagnostic email using the api key associated with [cluster id] and report the smtp transcript",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			exitError("failed to get to flag", exitCodeErrUnknown)
+		}
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			exitError("failed to get from flag", exitCodeErrUnknown)
+		}
+		if to == "" || from == "" {
+			exitError(fmt.Sprintf("--to and --from must be provided, --from defaults to the %s env var", envSMTPFrom), exitCodeErrValidation)
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			exitError("failed to get timeout flag", exitCodeErrUnknown)
+		}
+		insecureAuth, err := cmd.Flags().GetBool("insecure-auth")
+		if err != nil {
+			exitError("failed to get insecure auth flag", exitCodeErrUnknown)
+		}
+		smtpDetails := getClusterSMTPDetails(cmd, args[0])
+		msg, err := smtpdetails.NewTestMessage(args[0], from, to, smtpDetails)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to create test message: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		result, err := smtpdetails.NewSender(timeout, nil, insecureAuth).Send(smtpDetails, msg)
+		transcript := strings.Join(result.Transcript, "\n")
+		if err != nil {
+			exitError(fmt.Sprintf("%s\nfailed to send test message: %v", transcript, err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess(fmt.Sprintf("%s\nmessage id: %s\nresponse: %s\n", transcript, result.MessageID, result.Response))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sendTestCmd)
+	sendTestCmd.Flags().String("to", "", "Recipient of the diagnostic email")
+	sendTestCmd.Flags().String("from", os.Getenv(envSMTPFrom), fmt.Sprintf("Sender of the diagnostic email, must be a verified sender identity, defaults to the %s env var", envSMTPFrom))
+	sendTestCmd.Flags().String("password", os.Getenv(envSMTPPassword), fmt.Sprintf("API key of the cluster to authenticate with, defaults to the %s env var", envSMTPPassword))
+	sendTestCmd.Flags().Duration("timeout", smtpdetails.DefaultVerifyTimeout, "Time allowed for the smtp session")
+	addInsecureAuthFlag(sendTestCmd)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/integr8ly/smtp-service/pkg/events"
+	"github.com/integr8ly/smtp-service/pkg/prune"
+	"github.com/spf13/cobra"
+)
+
+// serveEventsCmd represents the serve-events command
+var serveEventsCmd = &cobra.Command{
+	Use:   "serve-events",
+	Short: "receive sendgrid event webhook posts and expose per cluster delivery event counters",
+	Run: func(cmd *cobra.Command, args []string) {
+		listenAddress, err := cmd.Flags().GetString("listen-address")
+		if err != nil {
+			exitError("failed to get listen address flag", exitCodeErrUnknown)
+		}
+		skipVerify, err := cmd.Flags().GetBool("skip-verify")
+		if err != nil {
+			exitError("failed to get skip verify flag", exitCodeErrUnknown)
+		}
+		maxAge, err := cmd.Flags().GetDuration("max-age")
+		if err != nil {
+			exitError("failed to get max age flag", exitCodeErrUnknown)
+		}
+		var verifier *events.Verifier
+		if skipVerify {
+			logger.Warn("event webhook signature verification is disabled")
+		} else {
+			publicKey := os.Getenv(events.EnvPublicKey)
+			if publicKey == "" {
+				exitError(fmt.Sprintf("%s env var must be defined unless --skip-verify is used", events.EnvPublicKey), exitCodeErrValidation)
+			}
+			verifier, err = events.NewVerifier(publicKey, maxAge)
+			if err != nil {
+				exitError(fmt.Sprintf("invalid event webhook public key: %v", err), exitCodeErrValidation)
+			}
+		}
+		knownClusters, err := cmd.Flags().GetString("known-clusters")
+		if err != nil {
+			exitError("failed to get known clusters flag", exitCodeErrUnknown)
+		}
+		var opts []events.HandlerOption
+		if knownClusters != "" {
+			raw, err := readInput(knownClusters)
+			if err != nil {
+				exitError(fmt.Sprintf("failed to read known clusters %s: %v", knownClusters, err), errExitCode(err, exitCodeErrKnown))
+			}
+			known, err := prune.ParseKnownClusters(bytes.NewReader(raw))
+			if err != nil {
+				exitError(fmt.Sprintf("invalid known clusters: %v", err), exitCodeErrValidation)
+			}
+			opts = append(opts, events.WithKnownClusters(known))
+		} else {
+			logger.Warn("no known clusters provided, events are attributed to the unsigned cluster id of the request path")
+		}
+		handler := events.NewHandler(verifier, events.NewAggregator(), logger, opts...)
+		logger.Infof("listening for event webhook posts on %s", listenAddress)
+		if err := http.ListenAndServe(listenAddress, handler); err != nil {
+			exitError(fmt.Sprintf("event receiver stopped: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveEventsCmd)
+	serveEventsCmd.Flags().String("listen-address", ":8080", "Address the event receiver listens on")
+	serveEventsCmd.Flags().Duration("max-age", events.DefaultMaxAge, "Maximum age of the timestamp of a signed event webhook post, older posts are rejected as replays, 0 accepts any age")
+	serveEventsCmd.Flags().StringP("known-clusters", "f", "", "File listing the ids of the clusters events are attributed to, one per line, events of other clusters are counted as unknown, use - to read from stdin")
+	serveEventsCmd.Flags().Bool("skip-verify", false, "Accept event webhook posts without a valid signature")
+}
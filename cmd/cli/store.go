@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/store"
+)
+
+//openStore Open the store of the state file flag, if no state file is set the default state file is used when required,
+//otherwise nil is returned and the clusters are not tracked. The state file is read only in a dry run.
+func openStore(required bool) store.Store {
+	if flagStateFile == "" && !required {
+		return nil
+	}
+	if dryRunPlan != nil {
+		return &dryRunStore{Store: store.NewFileStore(flagStateFile)}
+	}
+	return store.NewFileStore(flagStateFile)
+}
+
+//dryRunStore Store reading the state file and ignoring changes to it, so a dry run leaves it untouched
+type dryRunStore struct {
+	store.Store
+}
+
+//Put Ignore the record
+func (s *dryRunStore) Put(record *store.Record) error {
+	return nil
+}
+
+//Delete Ignore the deletion
+func (s *dryRunStore) Delete(clusterID string) error {
+	return nil
+}
+
+//recordCluster Track the api key of a cluster in the state file, if one is set, keyed by its canonical id. The api key
+//has been issued at this point, so a failure is reported without failing the command.
+func recordCluster(inventory store.Inventory, id string, rotated bool) {
+	s := openStore(false)
+	if s == nil || dryRunPlan != nil {
+		return
+	}
+	id = canonicalClusterID(id)
+	now := time.Now().UTC()
+	record, err := s.Get(id)
+	if err != nil {
+		if !store.IsNotExistError(err) {
+			logger.Warnf("failed to record cluster %s in state file: %v", id, err)
+			return
+		}
+		record = &store.Record{ClusterID: id, CreatedAt: now}
+	}
+	if rotated {
+		record.RotatedAt = now
+	} else {
+		record.CreatedAt = now
+		record.RotatedAt = time.Time{}
+	}
+	described, err := inventory.Describe(id)
+	if err != nil {
+		logger.Warnf("failed to describe cluster %s: %v", id, err)
+	} else {
+		record.UpdateFrom(described)
+	}
+	if err := s.Put(record); err != nil {
+		logger.Warnf("failed to record cluster %s in state file: %v", id, err)
+	}
+}
+
+//forgetCluster Remove a cluster from the state file, if one is set
+func forgetCluster(id string) {
+	s := openStore(false)
+	if s == nil || dryRunPlan != nil {
+		return
+	}
+	id = canonicalClusterID(id)
+	if err := s.Delete(id); err != nil && !store.IsNotExistError(err) {
+		logger.Warnf("failed to remove cluster %s from state file: %v", id, err)
+	}
+}
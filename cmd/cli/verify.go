@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [cluster id]",
+	Short: "verify the api key associated with [cluster id] can authenticate against the smtp server, without sending mail",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		smtpDetails := getClusterSMTPDetails(cmd, args[0])
+		verifySMTPDetails(cmd, smtpDetails)
+		exitSuccess(fmt.Sprintf("smtp details for cluster %s verified against %s:%d", args[0], smtpDetails.Host, smtpDetails.Port))
+	},
+}
+
+//getClusterSMTPDetails Retrieve the smtp details of a cluster, using the password flag as the api key
+func getClusterSMTPDetails(cmd *cobra.Command, clusterID string) *smtpdetails.SMTPDetails {
+	password, err := cmd.Flags().GetString("password")
+	if err != nil {
+		exitError("failed to get password flag", exitCodeErrUnknown)
+	}
+	if password == "" {
+		exitError(fmt.Sprintf("api keys cannot be retrieved after creation, password must be provided with --password or the %s env var", envSMTPPassword), exitCodeErrValidation)
+	}
+	smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+	if err != nil {
+		exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+	}
+	smtpDetails, err := smtpDetailsClient.Get(clusterID)
+	if err != nil {
+		if smtpdetails.IsNotExistError(err) {
+			exitError(fmt.Sprintf("api key for cluster %s not found", clusterID), exitCodeErrNotFound)
+		}
+		exitError(fmt.Sprintf("unknown error: %v", err), errExitCode(err, exitCodeErrUnknown))
+	}
+	smtpDetails.Password = password
+	return smtpDetails
+}
+
+//verifySMTPDetails Authenticate against the smtp server of the details, exiting on failure
+func verifySMTPDetails(cmd *cobra.Command, smtpDetails *smtpdetails.SMTPDetails) {
+	if dryRunPlan != nil {
+		logger.Debug("skipping verification of the placeholder api key of a dry run")
+		return
+	}
+	timeout, err := cmd.Flags().GetDuration("verify-timeout")
+	if err != nil {
+		exitError("failed to get verify timeout flag", exitCodeErrUnknown)
+	}
+	insecureAuth, err := cmd.Flags().GetBool("insecure-auth")
+	if err != nil {
+		exitError("failed to get insecure auth flag", exitCodeErrUnknown)
+	}
+	logger.Debugf("verifying smtp details against %s:%d", smtpDetails.Host, smtpDetails.Port)
+	if err := smtpdetails.NewVerifier(timeout, nil, insecureAuth).Verify(smtpDetails); err != nil {
+		if smtpdetails.IsAuthenticationError(err) || smtpdetails.IsConnectionError(err) {
+			exitError(fmt.Sprintf("smtp details verification failed: %v", err), errExitCode(err, exitCodeErrKnown))
+		}
+		exitError(fmt.Sprintf("unknown error verifying smtp details: %v", err), errExitCode(err, exitCodeErrUnknown))
+	}
+}
+
+//addVerifyFlags Add the flags of verifySMTPDetails to a command
+func addVerifyFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("verify-timeout", smtpdetails.DefaultVerifyTimeout, "Time allowed for connecting and authenticating against the smtp server")
+	addInsecureAuthFlag(cmd)
+}
+
+//addInsecureAuthFlag Add the flag allowing credentials to be sent to an smtp server without tls
+func addInsecureAuthFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("insecure-auth", false, "Authenticate even if tls could not be negotiated, sending the api key in cleartext")
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().String("password", os.Getenv(envSMTPPassword), fmt.Sprintf("API key of the cluster to authenticate with, defaults to the %s env var", envSMTPPassword))
+	addVerifyFlags(verifyCmd)
+}
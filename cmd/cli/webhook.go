@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/spf13/cobra"
+)
+
+// webhookCmd represents the webhook command
+var webhookCmd = &cobra.Command{
+	Use:   "webhook [sub command]",
+	Short: "manage the sendgrid event webhook of the sub user associated with a cluster",
+}
+
+// webhookGetCmd represents the webhook get command
+var webhookGetCmd = &cobra.Command{
+	Use:   "get [cluster id]",
+	Short: "get the event webhook settings of the sendgrid sub user associated with [cluster id]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		settings, err := smtpDetailsClient.GetEventWebhook(args[0])
+		if err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("sub user for cluster %s does not exist", args[0]), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("unknown error: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		settingsJSON, err := json.MarshalIndent(settings, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("error converting event webhook settings to json: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(string(settingsJSON))
+	},
+}
+
+// webhookSetCmd represents the webhook set command
+var webhookSetCmd = &cobra.Command{
+	Use:   "set [cluster id]",
+	Short: "enable the event webhook of the sendgrid sub user associated with [cluster id]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		urlTemplate, err := cmd.Flags().GetString("url")
+		if err != nil {
+			exitError("failed to get url flag", exitCodeErrUnknown)
+		}
+		events, err := cmd.Flags().GetStringSlice("events")
+		if err != nil {
+			exitError("failed to get events flag", exitCodeErrUnknown)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		settings, err := smtpDetailsClient.SetEventWebhook(args[0], urlTemplate, events)
+		if err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("sub user for cluster %s does not exist", args[0]), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("failed to set event webhook: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		settingsJSON, err := json.MarshalIndent(settings, "", "    ")
+		if err != nil {
+			exitError(fmt.Sprintf("error converting event webhook settings to json: %v", err), exitCodeErrUnknown)
+		}
+		exitSuccess(string(settingsJSON))
+	},
+}
+
+// webhookTestCmd represents the webhook test command
+var webhookTestCmd = &cobra.Command{
+	Use:   "test [cluster id]",
+	Short: "send a test event to the event webhook of the sendgrid sub user associated with [cluster id]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		urlTemplate, err := cmd.Flags().GetString("url")
+		if err != nil {
+			exitError("failed to get url flag", exitCodeErrUnknown)
+		}
+		smtpDetailsClient, err := setupSMTPDetailsClient(logger)
+		if err != nil {
+			exitError(fmt.Sprintf("failed to setup smtp details client: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		url, err := smtpDetailsClient.TestEventWebhook(args[0], urlTemplate)
+		if err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				exitError(fmt.Sprintf("no event webhook found for cluster %s: %v", args[0], err), exitCodeErrNotFound)
+			}
+			exitError(fmt.Sprintf("failed to test event webhook: %v", err), errExitCode(err, exitCodeErrUnknown))
+		}
+		exitSuccess(fmt.Sprintf("test event sent to %s", url))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookGetCmd, webhookSetCmd, webhookTestCmd)
+	webhookSetCmd.Flags().String("url", "", "Event webhook url, {{.ClusterID}} is replaced with the cluster id")
+	webhookSetCmd.Flags().StringSlice("events", sendgrid.DefaultEventWebhookEvents, "Event types posted to the event webhook")
+	if err := webhookSetCmd.MarkFlagRequired("url"); err != nil {
+		panic(err)
+	}
+	webhookTestCmd.Flags().String("url", "", "Event webhook url to send the test event to, defaults to the configured url")
+	addDryRunFlag(webhookSetCmd)
+	addDryRunFlag(webhookTestCmd)
+}
@@ -21,4 +21,5 @@ require (
 	gopkg.in/yaml.v2 v2.2.8 // indirect
 	k8s.io/api v0.17.2
 	k8s.io/apimachinery v0.17.2
+	sigs.k8s.io/yaml v1.2.0
 )
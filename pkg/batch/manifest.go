@@ -0,0 +1,96 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+//Action Operation applied to a cluster of a manifest
+type Action string
+
+//Options Options of a cluster in a manifest, blank options use the defaults of the manifest
+type Options struct {
+	Scopes     []string `json:"scopes,omitempty"`
+	IPPool     string   `json:"ipPool,omitempty"`
+	SecretName string   `json:"secretName,omitempty"`
+	Namespace  string   `json:"namespace,omitempty"`
+}
+
+//Item A cluster of a manifest
+type Item struct {
+	ID     string `json:"id"`
+	Action Action `json:"action,omitempty"`
+	Options
+}
+
+//UnmarshalJSON Accept either a cluster id or a cluster with options, rejecting unknown options
+func (i *Item) UnmarshalJSON(raw []byte) error {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		*i = Item{ID: id}
+		return nil
+	}
+	type item Item
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode((*item)(i))
+}
+
+//Manifest A list of clusters to create, refresh or delete
+type Manifest struct {
+	Defaults Options `json:"defaults,omitempty"`
+	Clusters []*Item `json:"clusters"`
+}
+
+//ParseManifest Parse a JSON or YAML manifest, applying the defaults of the manifest to its clusters
+func ParseManifest(raw []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := yaml.UnmarshalStrict(raw, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest")
+	}
+	if len(manifest.Clusters) == 0 {
+		return nil, errors.New("manifest does not list any clusters")
+	}
+	seen := map[string]bool{}
+	for n, item := range manifest.Clusters {
+		if item == nil || item.ID == "" {
+			return nil, errors.New(fmt.Sprintf("cluster %d of manifest has no id", n))
+		}
+		if seen[item.ID] {
+			return nil, errors.New(fmt.Sprintf("cluster %s is listed more than once", item.ID))
+		}
+		seen[item.ID] = true
+		if item.Action == "" {
+			item.Action = ActionCreate
+		}
+		if !isValidAction(item.Action) {
+			return nil, errors.New(fmt.Sprintf("invalid action %s for cluster %s, must be one of %v", item.Action, item.ID, Actions))
+		}
+		if len(item.Scopes) == 0 {
+			item.Scopes = manifest.Defaults.Scopes
+		}
+		if item.IPPool == "" {
+			item.IPPool = manifest.Defaults.IPPool
+		}
+		if item.SecretName == "" {
+			item.SecretName = manifest.Defaults.SecretName
+		}
+		if item.Namespace == "" {
+			item.Namespace = manifest.Defaults.Namespace
+		}
+	}
+	return manifest, nil
+}
+
+func isValidAction(action Action) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package batch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []*Item
+		wantErr  bool
+	}{
+		{
+			name: "should apply defaults to clusters",
+			manifest: `
+defaults:
+  scopes: [mail.send]
+  ipPool: rhmi-prod
+  secretName: redhat-rhmi-smtp
+  namespace: redhat-rhmi-operator
+clusters:
+- cluster-a
+- id: cluster-b
+  action: refresh
+  scopes: [mail.send, stats.read]
+  ipPool: rhmi-trial
+  secretName: smtp
+  namespace: smtp-ns
+- id: cluster-c
+  action: delete
+`,
+			want: []*Item{
+				{ID: "cluster-a", Action: ActionCreate, Options: Options{Scopes: []string{"mail.send"}, IPPool: "rhmi-prod", SecretName: "redhat-rhmi-smtp", Namespace: "redhat-rhmi-operator"}},
+				{ID: "cluster-b", Action: ActionRefresh, Options: Options{Scopes: []string{"mail.send", "stats.read"}, IPPool: "rhmi-trial", SecretName: "smtp", Namespace: "smtp-ns"}},
+				{ID: "cluster-c", Action: ActionDelete, Options: Options{Scopes: []string{"mail.send"}, IPPool: "rhmi-prod", SecretName: "redhat-rhmi-smtp", Namespace: "redhat-rhmi-operator"}},
+			},
+		},
+		{
+			name:     "should parse json",
+			manifest: `{"clusters": ["cluster-a"]}`,
+			want:     []*Item{{ID: "cluster-a", Action: ActionCreate}},
+		},
+		{
+			name:     "should fail without clusters",
+			manifest: `defaults: {}`,
+			wantErr:  true,
+		},
+		{
+			name:     "should fail on cluster without id",
+			manifest: "clusters:\n- action: create\n",
+			wantErr:  true,
+		},
+		{
+			name:     "should fail on duplicate cluster",
+			manifest: "clusters:\n- cluster-a\n- id: cluster-a\n",
+			wantErr:  true,
+		},
+		{
+			name:     "should fail on invalid action",
+			manifest: "clusters:\n- id: cluster-a\n  action: rotate\n",
+			wantErr:  true,
+		},
+		{
+			name:     "should fail on unknown field",
+			manifest: "clusters:\n- id: cluster-a\n  ipPol: rhmi-prod\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseManifest([]byte(tt.manifest))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Clusters, tt.want) {
+				for i := range got.Clusters {
+					t.Logf("got %+v", got.Clusters[i])
+				}
+				t.Fatalf("ParseManifest() clusters mismatch")
+			}
+		})
+	}
+}
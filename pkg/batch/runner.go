@@ -0,0 +1,158 @@
+package batch
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+//Status Outcome of the action of a cluster
+type Status string
+
+//ClientFactory Create the client handling a cluster of a manifest, configured with the options of the cluster
+type ClientFactory func(item *Item) (smtpdetails.Client, error)
+
+//Config Configuration of a Runner
+type Config struct {
+	//Concurrency Number of clusters handled in parallel
+	Concurrency int
+	//Rate Maximum number of clusters started per second, unlimited if zero
+	Rate float64
+	//SecretName Name of the secrets of clusters without a secret name
+	SecretName string
+	//SecretOptions Options applied to every secret
+	SecretOptions []smtpdetails.SecretOption
+	//IfExists Policy of the create action when the cluster already exists
+	IfExists smtpdetails.ExistsPolicy
+}
+
+//Result Outcome of the action of a cluster
+type Result struct {
+	ClusterID  string        `json:"clusterId"`
+	Action     Action        `json:"action"`
+	Status     Status        `json:"status"`
+	Existed    bool          `json:"existed,omitempty"`
+	SecretName string        `json:"secretName,omitempty"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Secret     *apiv1.Secret `json:"-"`
+}
+
+//Runner Apply the actions of the clusters of a manifest concurrently, continuing past failures
+type Runner struct {
+	factory ClientFactory
+	config  *Config
+	logger  *logrus.Entry
+}
+
+//NewRunner Create a Runner
+func NewRunner(factory ClientFactory, config *Config, logger *logrus.Entry) (*Runner, error) {
+	if factory == nil {
+		return nil, errors.New("factory must be defined")
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Rate < 0 {
+		return nil, errors.New("rate must not be negative")
+	}
+	return &Runner{factory: factory, config: config, logger: logger}, nil
+}
+
+//Run Apply the action of every cluster of the manifest, returning the results in the order of the manifest
+func (r *Runner) Run(manifest *Manifest) []*Result {
+	items := manifest.Clusters
+	results := make([]*Result, len(items))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < r.config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = r.apply(items[i])
+			}
+		}()
+	}
+	var throttle <-chan time.Time
+	if r.config.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / r.config.Rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+	for i := range items {
+		if throttle != nil && i > 0 {
+			<-throttle
+		}
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+//apply Apply the action of a single cluster
+func (r *Runner) apply(item *Item) *Result {
+	result := &Result{ClusterID: item.ID, Action: item.Action, Status: StatusFailed}
+	client, err := r.factory(item)
+	if err != nil {
+		result.Error = errors.Wrap(err, "failed to create client").Error()
+		return result
+	}
+	r.logger.Debugf("applying action %s to cluster %s", item.Action, item.ID)
+	var details *smtpdetails.SMTPDetails
+	switch item.Action {
+	case ActionCreate:
+		details, result.Existed, err = smtpdetails.CreateWithPolicy(client, item.ID, r.config.IfExists)
+	case ActionRefresh:
+		details, err = client.Refresh(item.ID)
+	case ActionDelete:
+		err = client.Delete(item.ID)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if result.Existed && r.config.IfExists == smtpdetails.ExistsPolicySkip {
+		result.Status = StatusSkipped
+		return result
+	}
+	result.Status = StatusSucceeded
+	if details == nil {
+		return result
+	}
+	result.SecretName = item.SecretName
+	if result.SecretName == "" {
+		result.SecretName = r.config.SecretName
+	}
+	result.Namespace = item.Namespace
+	result.Secret = smtpdetails.ConvertSMTPDetailsToSecret(details, result.SecretName, r.config.SecretOptions...)
+	result.Secret.Namespace = item.Namespace
+	return result
+}
+
+//WriteSecrets Write the secrets of the results as a multi-document YAML stream
+func WriteSecrets(w io.Writer, results []*Result) error {
+	for _, result := range results {
+		if result.Secret == nil {
+			continue
+		}
+		secretYAML, err := yaml.Marshal(result.Secret)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal secret of cluster %s", result.ClusterID)
+		}
+		if _, err := io.WriteString(w, "---\n"+string(secretYAML)); err != nil {
+			return errors.Wrapf(err, "failed to write secret of cluster %s", result.ClusterID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package batch
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func newMockLogger() *logrus.Entry {
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+func newMockSMTPDetails(id string) *smtpdetails.SMTPDetails {
+	return &smtpdetails.SMTPDetails{ID: id, Host: "smtp.example.com", Port: 587, Username: "apikey", Password: "test"}
+}
+
+func newMockClient(failID string) *smtpdetails.ClientMock {
+	return &smtpdetails.ClientMock{
+		CreateFunc: func(id string) (*smtpdetails.SMTPDetails, error) {
+			if id == failID {
+				return nil, errors.New("test")
+			}
+			return newMockSMTPDetails(id), nil
+		},
+		RefreshFunc: func(id string) (*smtpdetails.SMTPDetails, error) {
+			return newMockSMTPDetails(id), nil
+		},
+		DeleteFunc: func(id string) error {
+			return nil
+		},
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	manifest := &Manifest{Clusters: []*Item{
+		{ID: "create", Action: ActionCreate, Options: Options{Namespace: "ns"}},
+		{ID: "fail", Action: ActionCreate},
+		{ID: "refresh", Action: ActionRefresh, Options: Options{SecretName: "custom"}},
+		{ID: "delete", Action: ActionDelete},
+	}}
+	var mu sync.Mutex
+	var factoryItems []string
+	factory := func(item *Item) (smtpdetails.Client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		factoryItems = append(factoryItems, item.ID)
+		return newMockClient("fail"), nil
+	}
+	r, err := NewRunner(factory, &Config{Concurrency: 2, SecretName: "default"}, newMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := r.Run(manifest)
+	if len(factoryItems) != len(manifest.Clusters) {
+		t.Fatalf("expected a client per cluster, got %v", factoryItems)
+	}
+	want := []struct {
+		id         string
+		status     Status
+		secretName string
+	}{
+		{id: "create", status: StatusSucceeded, secretName: "default"},
+		{id: "fail", status: StatusFailed},
+		{id: "refresh", status: StatusSucceeded, secretName: "custom"},
+		{id: "delete", status: StatusSucceeded},
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.ClusterID != w.id || got.Status != w.status || got.SecretName != w.secretName {
+			t.Fatalf("result %d got = %+v, want %+v", i, got, w)
+		}
+		if (got.Secret != nil) != (w.secretName != "") {
+			t.Fatalf("result %s secret = %v, want secret %t", got.ClusterID, got.Secret, w.secretName != "")
+		}
+	}
+	if results[0].Secret.Namespace != "ns" {
+		t.Fatalf("expected secret in namespace ns, got %s", results[0].Secret.Namespace)
+	}
+	var out bytes.Buffer
+	if err := WriteSecrets(&out, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(out.String(), "---\n"); n != 2 {
+		t.Fatalf("expected 2 secret documents, got %d:\n%s", n, out.String())
+	}
+	if !strings.Contains(out.String(), "name: custom") || !strings.Contains(out.String(), "namespace: ns") {
+		t.Fatalf("unexpected secrets:\n%s", out.String())
+	}
+}
+
+func TestRunner_Run_FactoryError(t *testing.T) {
+	factory := func(item *Item) (smtpdetails.Client, error) {
+		return nil, errors.New("test")
+	}
+	r, err := NewRunner(factory, nil, newMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := r.Run(&Manifest{Clusters: []*Item{{ID: "test", Action: ActionCreate}}})
+	if results[0].Status != StatusFailed || results[0].Error == "" {
+		t.Fatalf("expected failed result, got %+v", results[0])
+	}
+}
+
+func TestRunner_Run_Rate(t *testing.T) {
+	factory := func(item *Item) (smtpdetails.Client, error) {
+		return newMockClient(""), nil
+	}
+	r, err := NewRunner(factory, &Config{Concurrency: 3, Rate: 50}, newMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Now()
+	r.Run(&Manifest{Clusters: []*Item{{ID: "a", Action: ActionCreate}, {ID: "b", Action: ActionCreate}, {ID: "c", Action: ActionCreate}}})
+	// the first cluster starts straight away, the others 20ms apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected rate limit to spread clusters over at least 40ms, took %s", elapsed)
+	}
+}
+
+func TestNewRunner(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory ClientFactory
+		config  *Config
+		wantErr bool
+	}{
+		{
+			name: "should create runner with default config",
+			factory: func(item *Item) (smtpdetails.Client, error) {
+				return nil, nil
+			},
+		},
+		{
+			name:    "should fail without factory",
+			wantErr: true,
+		},
+		{
+			name: "should fail on negative rate",
+			factory: func(item *Item) (smtpdetails.Client, error) {
+				return nil, nil
+			},
+			config:  &Config{Rate: -1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRunner(tt.factory, tt.config, newMockLogger())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRunner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && r.config.Concurrency != DefaultConcurrency {
+				t.Fatalf("expected default concurrency, got %d", r.config.Concurrency)
+			}
+		})
+	}
+}
+
+func TestRunner_Run_IfExists(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      smtpdetails.ExistsPolicy
+		wantStatus  Status
+		wantSecret  bool
+		wantExisted bool
+	}{
+		{name: "should fail with error policy", policy: smtpdetails.ExistsPolicyError, wantStatus: StatusFailed, wantExisted: true},
+		{name: "should skip with skip policy", policy: smtpdetails.ExistsPolicySkip, wantStatus: StatusSkipped, wantExisted: true},
+		{name: "should refresh with rotate policy", policy: smtpdetails.ExistsPolicyRotate, wantStatus: StatusSucceeded, wantSecret: true, wantExisted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := func(item *Item) (smtpdetails.Client, error) {
+				client := newMockClient("")
+				client.CreateFunc = func(id string) (*smtpdetails.SMTPDetails, error) {
+					return nil, &smtpdetails.AlreadyExistsError{Message: "test"}
+				}
+				client.GetFunc = func(id string) (*smtpdetails.SMTPDetails, error) {
+					return newMockSMTPDetails(id), nil
+				}
+				return client, nil
+			}
+			r, err := NewRunner(factory, &Config{IfExists: tt.policy}, newMockLogger())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := r.Run(&Manifest{Clusters: []*Item{{ID: "test", Action: ActionCreate}}})[0]
+			if result.Status != tt.wantStatus || result.Existed != tt.wantExisted || (result.Secret != nil) != tt.wantSecret {
+				t.Fatalf("got result %+v, want status %s existed %t secret %t", result, tt.wantStatus, tt.wantExisted, tt.wantSecret)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package batch
+
+const (
+	//DefaultConcurrency Number of clusters handled in parallel
+	DefaultConcurrency = 4
+
+	//ActionCreate Create the SMTP details of a cluster
+	ActionCreate Action = "create"
+	//ActionRefresh Rotate the API key of a cluster
+	ActionRefresh Action = "refresh"
+	//ActionDelete Delete the SMTP details of a cluster
+	ActionDelete Action = "delete"
+
+	//StatusSucceeded The action of the cluster succeeded
+	StatusSucceeded Status = "succeeded"
+	//StatusFailed The action of the cluster failed
+	StatusFailed Status = "failed"
+	//StatusSkipped The cluster already exists and was skipped by the exists policy
+	StatusSkipped Status = "skipped"
+)
+
+//Actions All supported actions
+var Actions = []Action{ActionCreate, ActionRefresh, ActionDelete}
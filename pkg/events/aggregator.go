@@ -0,0 +1,96 @@
+package events
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//Aggregator Thread-safe per-cluster counters of tracked delivery events
+type Aggregator struct {
+	mu       sync.RWMutex
+	counters map[string]*Counters
+}
+
+//NewAggregator Create a new Aggregator with no recorded events
+func NewAggregator() *Aggregator {
+	return &Aggregator{counters: map[string]*Counters{}}
+}
+
+//Record Count an event against a cluster, returns false if the event type is not tracked. Once MaxClusters clusters
+//are tracked, events of new clusters are counted against UnknownCluster.
+func (a *Aggregator) Record(clusterID string, e *Event) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	counters, ok := a.counters[clusterID]
+	if !ok && len(a.counters) >= MaxClusters {
+		clusterID = UnknownCluster
+		counters, ok = a.counters[clusterID]
+	}
+	if !ok {
+		counters = &Counters{}
+	}
+	switch e.Event {
+	case EventDelivered:
+		counters.Delivered++
+	case EventBounce:
+		counters.Bounce++
+	case EventDropped:
+		counters.Dropped++
+	case EventSpamReport:
+		counters.SpamReport++
+	default:
+		return false
+	}
+	a.counters[clusterID] = counters
+	return true
+}
+
+//Snapshot Copy of the current counters keyed by cluster ID
+func (a *Aggregator) Snapshot() map[string]Counters {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	snapshot := make(map[string]Counters, len(a.counters))
+	for clusterID, counters := range a.counters {
+		snapshot[clusterID] = *counters
+	}
+	return snapshot
+}
+
+//WritePrometheus Write the current counters to w in the Prometheus text exposition format
+func (a *Aggregator) WritePrometheus(w io.Writer) error {
+	snapshot := a.Snapshot()
+	clusterIDs := make([]string, 0, len(snapshot))
+	for clusterID := range snapshot {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Strings(clusterIDs)
+	if _, err := fmt.Fprintf(w, "# HELP %s Number of SendGrid delivery events received per cluster.\n# TYPE %s counter\n", MetricEventsTotal, MetricEventsTotal); err != nil {
+		return err
+	}
+	for _, clusterID := range clusterIDs {
+		counters := snapshot[clusterID]
+		for _, sample := range []struct {
+			event string
+			value uint64
+		}{
+			{EventDelivered, counters.Delivered},
+			{EventBounce, counters.Bounce},
+			{EventDropped, counters.Dropped},
+			{EventSpamReport, counters.SpamReport},
+		} {
+			if _, err := fmt.Fprintf(w, "%s{cluster=\"%s\",event=\"%s\"} %d\n", MetricEventsTotal, escapeLabelValue(clusterID), sample.event, sample.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
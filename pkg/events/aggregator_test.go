@@ -0,0 +1,74 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAggregator_Record(t *testing.T) {
+	a := NewAggregator()
+	for _, e := range []string{EventDelivered, EventDelivered, EventBounce, EventDropped, EventSpamReport} {
+		if !a.Record("test", &Event{Event: e}) {
+			t.Errorf("Record() event %s not tracked", e)
+		}
+	}
+	if a.Record("test", &Event{Event: "open"}) {
+		t.Error("Record() untracked event open was counted")
+	}
+	want := map[string]Counters{
+		"test": {Delivered: 2, Bounce: 1, Dropped: 1, SpamReport: 1},
+	}
+	if got := a.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() got = %v, want %v", got, want)
+	}
+}
+
+func TestAggregator_Record_UntrackedEventDoesNotCreateCluster(t *testing.T) {
+	a := NewAggregator()
+	a.Record("test", &Event{Event: "open"})
+	if got := a.Snapshot(); len(got) != 0 {
+		t.Errorf("Snapshot() got = %v, want no clusters", got)
+	}
+}
+
+func TestAggregator_Record_MaxClusters(t *testing.T) {
+	a := NewAggregator()
+	for i := 0; i < MaxClusters; i++ {
+		a.Record(fmt.Sprintf("test-%d", i), &Event{Event: EventDelivered})
+	}
+	a.Record("new", &Event{Event: EventBounce})
+	a.Record("test-0", &Event{Event: EventBounce})
+	got := a.Snapshot()
+	if _, ok := got["new"]; ok {
+		t.Fatal("Record() tracked a cluster beyond the max clusters")
+	}
+	if got[UnknownCluster].Bounce != 1 || got["test-0"].Bounce != 1 {
+		t.Fatalf("Snapshot() got unknown = %v, test-0 = %v", got[UnknownCluster], got["test-0"])
+	}
+}
+
+func TestAggregator_WritePrometheus(t *testing.T) {
+	a := NewAggregator()
+	a.Record("b", &Event{Event: EventBounce})
+	a.Record(`a"1`, &Event{Event: EventDelivered})
+	var got bytes.Buffer
+	if err := a.WritePrometheus(&got); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	want := `# HELP smtp_service_events_total Number of SendGrid delivery events received per cluster.
+# TYPE smtp_service_events_total counter
+smtp_service_events_total{cluster="a\"1",event="delivered"} 1
+smtp_service_events_total{cluster="a\"1",event="bounce"} 0
+smtp_service_events_total{cluster="a\"1",event="dropped"} 0
+smtp_service_events_total{cluster="a\"1",event="spamreport"} 0
+smtp_service_events_total{cluster="b",event="delivered"} 0
+smtp_service_events_total{cluster="b",event="bounce"} 1
+smtp_service_events_total{cluster="b",event="dropped"} 0
+smtp_service_events_total{cluster="b",event="spamreport"} 0
+`
+	if got.String() != want {
+		t.Errorf("WritePrometheus() got = %s, want %s", got.String(), want)
+	}
+}
@@ -0,0 +1,17 @@
+package events
+
+//SignatureError Error to indicate an event webhook request signature is invalid
+type SignatureError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *SignatureError) Error() string {
+	return e.Message
+}
+
+//IsSignatureError Compare check for SignatureError
+func IsSignatureError(err error) bool {
+	_, ok := err.(*SignatureError)
+	return ok
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/integr8ly/smtp-service/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+var _ http.Handler = &Handler{}
+
+//Handler HTTP handler receiving SendGrid event webhook posts and exposing the aggregated counters
+type Handler struct {
+	verifier   *Verifier
+	aggregator *Aggregator
+	known      map[string]bool
+	mux        *http.ServeMux
+	logger     *logrus.Entry
+}
+
+//HandlerOption Optional configuration of a Handler
+type HandlerOption func(h *Handler)
+
+//WithKnownClusters Only attribute events to the provided clusters, events of any other cluster are counted against
+//UnknownCluster. The cluster ID in the request path is not covered by the signature, so without known clusters any
+//client able to reach the receiver can attribute signed events to an arbitrary cluster.
+func WithKnownClusters(ids []string) HandlerOption {
+	return func(h *Handler) {
+		h.known = map[string]bool{}
+		for _, id := range ids {
+			h.known[id] = true
+		}
+	}
+}
+
+//NewHandler Create a new Handler, requests are not verified if verifier is nil
+func NewHandler(verifier *Verifier, aggregator *Aggregator, logger *logrus.Entry, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		verifier:   verifier,
+		aggregator: aggregator,
+		mux:        http.NewServeMux(),
+		logger:     logger.WithField(LogFieldEventReceiver, "sendgrid"),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc(RouteEvents, h.handleEvents)
+	h.mux.HandleFunc(RouteEvents+"/", h.handleEvents)
+	h.mux.HandleFunc(RouteStats, h.handleStats)
+	h.mux.HandleFunc(RouteMetrics, h.handleMetrics)
+	return h
+}
+
+//ServeHTTP Route a request to the events, stats or metrics handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxPayloadSize))
+	if err != nil {
+		// the body exceeded the limit, or the client went away and will not see the response
+		h.logger.Errorf("failed to read event webhook payload: %v", err)
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if h.verifier != nil {
+		if err := h.verifier.Verify(payload, r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp)); err != nil {
+			h.logger.Warnf("rejecting event webhook request: %v", err)
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+	}
+	var events []*Event
+	if err := json.Unmarshal(payload, &events); err != nil {
+		h.logger.Warnf("failed to unmarshal event webhook payload: %v", err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	pathClusterID := strings.Trim(strings.TrimPrefix(r.URL.Path, RouteEvents), "/")
+	for _, e := range events {
+		clusterID := h.attributeEvent(pathClusterID, e)
+		if !h.aggregator.Record(clusterID, e) {
+			h.logger.Debugf("ignoring untracked event type %s for cluster %s", e.Event, clusterID)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.aggregator.Snapshot()); err != nil {
+		h.logger.Errorf("failed to write stats: %v", err)
+	}
+}
+
+//handleMetrics Write the event counters followed by the metrics of the default registry, e.g. of the SendGrid API
+//requests of the process
+func (h *Handler) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := h.aggregator.WritePrometheus(w); err != nil {
+		h.logger.Errorf("failed to write metrics: %v", err)
+		return
+	}
+	if err := metrics.Default.WritePrometheus(w); err != nil {
+		h.logger.Errorf("failed to write metrics: %v", err)
+	}
+}
+
+//attributeEvent Find the cluster an event belongs to, the cluster ID in the request path takes precedence over the
+//first category of the event. Clusters missing from the known clusters, if any, are replaced by UnknownCluster.
+func (h *Handler) attributeEvent(pathClusterID string, e *Event) string {
+	clusterID := pathClusterID
+	if clusterID == "" {
+		for _, category := range e.Category {
+			if category != "" {
+				clusterID = category
+				break
+			}
+		}
+	}
+	if clusterID == "" || (h.known != nil && !h.known[clusterID]) {
+		return UnknownCluster
+	}
+	return clusterID
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func newMockLogger() *logrus.Entry {
+	return logrus.WithField("test", "test")
+}
+
+func newSignedRequest(t *testing.T, path, payload, signature string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(payload))
+	req.Header.Set(HeaderSignature, signature)
+	req.Header.Set(HeaderTimestamp, mockTimestamp)
+	return req
+}
+
+func TestHandler_Events(t *testing.T) {
+	key, publicKey := newMockKey(t)
+	verifier := newMockVerifier(t, publicKey)
+	payload := `[
+		{"email":"a@email.com","event":"delivered","category":"from-category"},
+		{"email":"b@email.com","event":"bounce","category":["", "from-category"]},
+		{"email":"c@email.com","event":"dropped"},
+		{"email":"d@email.com","event":"open"}
+	]`
+	tests := []struct {
+		name       string
+		verifier   *Verifier
+		opts       []HandlerOption
+		req        *http.Request
+		wantStatus int
+		want       map[string]Counters
+	}{
+		{
+			name:       "signed events attributed by category",
+			verifier:   verifier,
+			req:        newSignedRequest(t, RouteEvents, payload, signPayload(t, key, mockTimestamp, payload)),
+			wantStatus: http.StatusNoContent,
+			want: map[string]Counters{
+				"from-category": {Delivered: 1, Bounce: 1},
+				UnknownCluster:  {Dropped: 1},
+			},
+		},
+		{
+			name:       "signed events attributed by path",
+			verifier:   verifier,
+			req:        newSignedRequest(t, RouteEvents+"/test", payload, signPayload(t, key, mockTimestamp, payload)),
+			wantStatus: http.StatusNoContent,
+			want: map[string]Counters{
+				"test": {Delivered: 1, Bounce: 1, Dropped: 1},
+			},
+		},
+		{
+			name:       "signed events of unknown clusters attributed to unknown",
+			verifier:   verifier,
+			opts:       []HandlerOption{WithKnownClusters([]string{"from-category"})},
+			req:        newSignedRequest(t, RouteEvents+"/spoofed", payload, signPayload(t, key, mockTimestamp, payload)),
+			wantStatus: http.StatusNoContent,
+			want: map[string]Counters{
+				UnknownCluster: {Delivered: 1, Bounce: 1, Dropped: 1},
+			},
+		},
+		{
+			name:       "signed events of known clusters attributed by category",
+			verifier:   verifier,
+			opts:       []HandlerOption{WithKnownClusters([]string{"from-category"})},
+			req:        newSignedRequest(t, RouteEvents, payload, signPayload(t, key, mockTimestamp, payload)),
+			wantStatus: http.StatusNoContent,
+			want: map[string]Counters{
+				"from-category": {Delivered: 1, Bounce: 1},
+				UnknownCluster:  {Dropped: 1},
+			},
+		},
+		{
+			name:       "invalid signature rejected",
+			verifier:   verifier,
+			req:        newSignedRequest(t, RouteEvents, payload, signPayload(t, key, mockTimestamp, "[]")),
+			wantStatus: http.StatusForbidden,
+			want:       map[string]Counters{},
+		},
+		{
+			name:       "unsigned events accepted without verifier",
+			req:        httptest.NewRequest(http.MethodPost, RouteEvents+"/test", strings.NewReader(payload)),
+			wantStatus: http.StatusNoContent,
+			want: map[string]Counters{
+				"test": {Delivered: 1, Bounce: 1, Dropped: 1},
+			},
+		},
+		{
+			name:       "invalid payload rejected",
+			req:        httptest.NewRequest(http.MethodPost, RouteEvents, strings.NewReader("not json")),
+			wantStatus: http.StatusBadRequest,
+			want:       map[string]Counters{},
+		},
+		{
+			name:       "oversized payload rejected",
+			req:        httptest.NewRequest(http.MethodPost, RouteEvents, strings.NewReader(strings.Repeat(" ", MaxPayloadSize+1))),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			want:       map[string]Counters{},
+		},
+		{
+			name:       "non-post request rejected",
+			req:        httptest.NewRequest(http.MethodGet, RouteEvents, nil),
+			wantStatus: http.StatusMethodNotAllowed,
+			want:       map[string]Counters{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := NewAggregator()
+			h := NewHandler(tt.verifier, aggregator, newMockLogger(), tt.opts...)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := aggregator.Snapshot(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServeHTTP() counters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_StatsAndMetrics(t *testing.T) {
+	aggregator := NewAggregator()
+	aggregator.Record("test", &Event{Event: EventDelivered})
+	h := NewHandler(nil, aggregator, newMockLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteStats, nil))
+	var stats map[string]Counters
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("stats response is not json: %v", err)
+	}
+	if want := (map[string]Counters{"test": {Delivered: 1}}); !reflect.DeepEqual(stats, want) {
+		t.Errorf("stats got = %v, want %v", stats, want)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteMetrics, nil))
+	if !strings.Contains(rec.Body.String(), `smtp_service_events_total{cluster="test",event="delivered"} 1`) {
+		t.Errorf("metrics got = %s, want delivered counter for cluster test", rec.Body.String())
+	}
+
+	metrics.Default.NewCounter("test_handler_total", "Test counter.").Inc()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteMetrics, nil))
+	if !strings.Contains(rec.Body.String(), "test_handler_total 1\n") {
+		t.Errorf("metrics got = %s, want counter of the default registry", rec.Body.String())
+	}
+}
@@ -0,0 +1,41 @@
+package events
+
+import "time"
+
+const (
+	//HeaderSignature SendGrid signed event webhook header containing the base64 encoded ECDSA signature
+	HeaderSignature = "X-Twilio-Email-Event-Webhook-Signature"
+	//HeaderTimestamp SendGrid signed event webhook header containing the timestamp included in the signature
+	HeaderTimestamp = "X-Twilio-Email-Event-Webhook-Timestamp"
+	//EnvPublicKey Name of the env var to retrieve the SendGrid signed event webhook public key
+	EnvPublicKey = "SENDGRID_WEBHOOK_PUBLIC_KEY"
+	//RouteEvents HTTP route accepting event webhook posts, optionally followed by /{cluster id}
+	RouteEvents = "/events"
+	//RouteStats HTTP route exposing the per-cluster event counters as JSON
+	RouteStats = "/stats"
+	//RouteMetrics HTTP route exposing the per-cluster event counters in the Prometheus text format
+	RouteMetrics = "/metrics"
+	//UnknownCluster Cluster ID used for events that cannot be attributed to a cluster
+	UnknownCluster = "unknown"
+	//MetricEventsTotal Prometheus metric name of the per-cluster event counters
+	MetricEventsTotal = "smtp_service_events_total"
+	//DefaultMaxAge Default max age of the timestamp of a signed event webhook request
+	DefaultMaxAge = 10 * time.Minute
+	//MaxClusters Maximum number of clusters counters are kept for, bounding the memory and metrics of the receiver
+	MaxClusters = 10000
+	//MaxPayloadSize Maximum size in bytes of an event webhook request body
+	MaxPayloadSize = 10 << 20
+	//LogFieldEventReceiver Logging field name for a description of the event receiver
+	LogFieldEventReceiver = "smtp_service_event_receiver"
+)
+
+const (
+	//EventDelivered Event type sent when a message has been accepted by the receiving server
+	EventDelivered = "delivered"
+	//EventBounce Event type sent when the receiving server permanently rejected a message
+	EventBounce = "bounce"
+	//EventDropped Event type sent when a message will not be delivered
+	EventDropped = "dropped"
+	//EventSpamReport Event type sent when a recipient marked a message as spam
+	EventSpamReport = "spamreport"
+)
@@ -0,0 +1,41 @@
+package events
+
+import (
+	"encoding/json"
+)
+
+//Event A single SendGrid delivery event, from https://sendgrid.com/docs/for-developers/tracking-events/event/
+type Event struct {
+	Email       string     `json:"email"`
+	Timestamp   int64      `json:"timestamp"`
+	Event       string     `json:"event"`
+	SGEventID   string     `json:"sg_event_id"`
+	SGMessageID string     `json:"sg_message_id"`
+	Category    Categories `json:"category"`
+}
+
+//Categories Categories of an event, SendGrid sends a single category as a string and multiple as a list
+type Categories []string
+
+//UnmarshalJSON Accept both a single category string and a list of categories
+func (c *Categories) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*c = Categories{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*c = multiple
+	return nil
+}
+
+//Counters Number of tracked delivery events received for a cluster
+type Counters struct {
+	Delivered  uint64 `json:"delivered"`
+	Bounce     uint64 `json:"bounce"`
+	Dropped    uint64 `json:"dropped"`
+	SpamReport uint64 `json:"spamreport"`
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ecdsaSignature ASN.1 structure of an ECDSA signature
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+//Verifier Verifies the ECDSA signature of signed SendGrid event webhook requests, rejecting requests with a timestamp
+//older than the max age so a captured request cannot be replayed
+type Verifier struct {
+	publicKey *ecdsa.PublicKey
+	maxAge    time.Duration
+	now       func() time.Time
+}
+
+//NewVerifier Create a new Verifier from the base64 encoded public key shown in the SendGrid mail settings, a zero
+//maxAge accepts timestamps of any age
+func NewVerifier(publicKey string, maxAge time.Duration) (*Verifier, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an ecdsa public key")
+	}
+	if maxAge < 0 {
+		return nil, errors.New("max age must not be negative")
+	}
+	return &Verifier{publicKey: ecdsaKey, maxAge: maxAge, now: time.Now}, nil
+}
+
+//Verify Check the signature and timestamp headers of a request match it's payload, and the timestamp is within the
+//max age
+func (v *Verifier) Verify(payload []byte, signature, timestamp string) error {
+	if signature == "" || timestamp == "" {
+		return &SignatureError{Message: "signature and timestamp headers must be defined"}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return &SignatureError{Message: "timestamp is not a unix time"}
+	}
+	// the clocks of SendGrid and the receiver may differ, so timestamps in the future are allowed by the max age too
+	if age := v.now().Sub(time.Unix(unix, 0)); v.maxAge > 0 && (age > v.maxAge || age < -v.maxAge) {
+		return &SignatureError{Message: fmt.Sprintf("timestamp is %s from now, exceeding the max age of %s", age, v.maxAge)}
+	}
+	sigDER, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return &SignatureError{Message: "signature is not valid base64"}
+	}
+	sig := &ecdsaSignature{}
+	if rest, err := asn1.Unmarshal(sigDER, sig); err != nil || len(rest) != 0 {
+		return &SignatureError{Message: "signature is not a valid ecdsa signature"}
+	}
+	hash := sha256.Sum256(append([]byte(timestamp), payload...))
+	if !ecdsa.Verify(v.publicKey, hash[:], sig.R, sig.S) {
+		return &SignatureError{Message: "signature does not match payload"}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package events
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+const (
+	mockTimestamp = "1600000000"
+	mockPayload   = `[{"email":"test@email.com","timestamp":1600000000,"event":"delivered","category":"test"}]`
+)
+
+func newMockKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return key, base64.StdEncoding.EncodeToString(der)
+}
+
+//newMockVerifier Verifier of the public key with the default max age, at the time of the mock timestamp
+func newMockVerifier(t *testing.T, publicKey string) *Verifier {
+	verifier, err := NewVerifier(publicKey, DefaultMaxAge)
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	verifier.now = func() time.Time {
+		return time.Unix(1600000000, 0)
+	}
+	return verifier
+}
+
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, timestamp, payload string) string {
+	hash := sha256.Sum256([]byte(timestamp + payload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign payload: %v", err)
+	}
+	sig, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	if err != nil {
+		t.Fatalf("failed to marshal signature: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestNewVerifier(t *testing.T) {
+	_, publicKey := newMockKey(t)
+	tests := []struct {
+		name      string
+		publicKey string
+		maxAge    time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "valid public key",
+			publicKey: publicKey,
+		},
+		{
+			name:      "invalid base64",
+			publicKey: "not base64!",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid der",
+			publicKey: base64.StdEncoding.EncodeToString([]byte("test")),
+			wantErr:   true,
+		},
+		{
+			name:      "negative max age",
+			publicKey: publicKey,
+			maxAge:    -time.Minute,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewVerifier(tt.publicKey, tt.maxAge)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	key, publicKey := newMockKey(t)
+	otherKey, _ := newMockKey(t)
+	verifier := newMockVerifier(t, publicKey)
+	tests := []struct {
+		name      string
+		payload   string
+		signature string
+		timestamp string
+		wantErr   bool
+	}{
+		{
+			name:      "valid signature",
+			payload:   mockPayload,
+			signature: signPayload(t, key, mockTimestamp, mockPayload),
+			timestamp: mockTimestamp,
+		},
+		{
+			name:      "payload modified",
+			payload:   mockPayload + " ",
+			signature: signPayload(t, key, mockTimestamp, mockPayload),
+			timestamp: mockTimestamp,
+			wantErr:   true,
+		},
+		{
+			name:      "timestamp modified",
+			payload:   mockPayload,
+			signature: signPayload(t, key, mockTimestamp, mockPayload),
+			timestamp: "1600000001",
+			wantErr:   true,
+		},
+		{
+			name:      "timestamp older than max age",
+			payload:   mockPayload,
+			signature: signPayload(t, key, "1599999000", mockPayload),
+			timestamp: "1599999000",
+			wantErr:   true,
+		},
+		{
+			name:      "timestamp too far in the future",
+			payload:   mockPayload,
+			signature: signPayload(t, key, "1600001000", mockPayload),
+			timestamp: "1600001000",
+			wantErr:   true,
+		},
+		{
+			name:      "timestamp within max age",
+			payload:   mockPayload,
+			signature: signPayload(t, key, "1599999700", mockPayload),
+			timestamp: "1599999700",
+		},
+		{
+			name:      "timestamp not a number",
+			payload:   mockPayload,
+			signature: signPayload(t, key, "now", mockPayload),
+			timestamp: "now",
+			wantErr:   true,
+		},
+		{
+			name:      "signed by another key",
+			payload:   mockPayload,
+			signature: signPayload(t, otherKey, mockTimestamp, mockPayload),
+			timestamp: mockTimestamp,
+			wantErr:   true,
+		},
+		{
+			name:      "signature not defined",
+			payload:   mockPayload,
+			timestamp: mockTimestamp,
+			wantErr:   true,
+		},
+		{
+			name:      "signature not asn1",
+			payload:   mockPayload,
+			signature: base64.StdEncoding.EncodeToString([]byte("test")),
+			timestamp: mockTimestamp,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.Verify([]byte(tt.payload), tt.signature, tt.timestamp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !IsSignatureError(err) {
+				t.Errorf("Verify() error = %v, want SignatureError", err)
+			}
+		})
+	}
+}
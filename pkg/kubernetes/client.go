@@ -0,0 +1,231 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//Client Minimal Kubernetes API client for managing SMTP secrets and custom resources
+type Client struct {
+	config      *Config
+	httpClient  *http.Client
+	watchClient *http.Client
+	logger      *logrus.Entry
+}
+
+//WatchEvent An event received from a watch, the object is decoded by the caller
+type WatchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+//Watcher A stream of events from a watch
+type Watcher struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+}
+
+//NewClient Create a new Client from a Config
+func NewClient(config *Config, logger *logrus.Entry) (*Client, error) {
+	if config == nil || config.Host == "" {
+		return nil, errors.New("kubernetes config must define a host")
+	}
+	transport := &http.Transport{TLSClientConfig: config.TLSConfig, Proxy: http.ProxyFromEnvironment}
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: transport,
+		},
+		// watches are bounded by the timeoutSeconds of the request instead of the client
+		watchClient: &http.Client{Transport: transport},
+		logger:      logger.WithField(LogFieldKubernetesClient, config.Host),
+	}, nil
+}
+
+//ApplySecret Create or update a secret in a namespace with server-side apply, a blank namespace uses the namespace of
+//the config. Fields owned by another field manager cause a ConflictError unless force is set
+func (c *Client) ApplySecret(namespace string, secret *apiv1.Secret, force bool) (*apiv1.Secret, error) {
+	if namespace == "" {
+		namespace = c.config.Namespace
+	}
+	applied := secret.DeepCopy()
+	applied.Namespace = namespace
+	body, err := json.Marshal(applied)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal secret")
+	}
+	query := url.Values{}
+	query.Set("fieldManager", FieldManager)
+	query.Set("force", strconv.FormatBool(force))
+	route := fmt.Sprintf(APIRouteSecret, url.PathEscape(namespace), url.PathEscape(secret.Name)) + "?" + query.Encode()
+	c.logger.Debugf("applying secret %s in namespace %s", secret.Name, namespace)
+	respBody, err := c.do(http.MethodPatch, route, ContentTypeApplyPatch, body)
+	if err != nil {
+		if IsConflictError(err) {
+			return nil, &ConflictError{Message: fmt.Sprintf("failed to apply secret %s in namespace %s: %s", secret.Name, namespace, err.Error())}
+		}
+		return nil, errors.Wrapf(err, "failed to apply secret %s in namespace %s", secret.Name, namespace)
+	}
+	result := &apiv1.Secret{}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal applied secret")
+	}
+	return result, nil
+}
+
+//GetSecret Retrieve a secret in a namespace, a blank namespace uses the namespace of the config
+func (c *Client) GetSecret(namespace, name string) (*apiv1.Secret, error) {
+	if namespace == "" {
+		namespace = c.config.Namespace
+	}
+	respBody, err := c.do(http.MethodGet, fmt.Sprintf(APIRouteSecret, url.PathEscape(namespace), url.PathEscape(name)), "", nil)
+	if err != nil {
+		if IsNotExistError(err) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "failed to get secret %s in namespace %s", name, namespace)
+	}
+	result := &apiv1.Secret{}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal secret")
+	}
+	return result, nil
+}
+
+//Namespace The default namespace of the config
+func (c *Client) Namespace() string {
+	return c.config.Namespace
+}
+
+//Get Retrieve the resource at an API route, decoded into out
+func (c *Client) Get(route string, out interface{}) error {
+	respBody, err := c.do(http.MethodGet, route, "", nil)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(json.Unmarshal(respBody, out), "failed to unmarshal resource")
+}
+
+//Patch Patch the resource at an API route with a patch of the content type, the result is decoded into out
+func (c *Client) Patch(route, contentType string, patch interface{}, out interface{}) error {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal patch")
+	}
+	respBody, err := c.do(http.MethodPatch, route, contentType, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(respBody, out), "failed to unmarshal resource")
+}
+
+//Watch Watch the resources of a list API route from a resource version, the server ends the watch after timeout
+func (c *Client) Watch(route, resourceVersion string, timeout time.Duration) (*Watcher, error) {
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("allowWatchBookmarks", "true")
+	query.Set("timeoutSeconds", strconv.Itoa(int(timeout.Seconds())))
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+	req, err := c.newRequest(http.MethodGet, route+"?"+query.Encode(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.watchClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform request")
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, statusError(resp.StatusCode, respBody)
+	}
+	return &Watcher{body: resp.Body, decoder: json.NewDecoder(resp.Body)}, nil
+}
+
+//Next Wait for the next event, io.EOF is returned when the watch ends
+func (w *Watcher) Next() (*WatchEvent, error) {
+	event := &WatchEvent{}
+	if err := w.decoder.Decode(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+//Close Stop the watch
+func (w *Watcher) Close() error {
+	return w.body.Close()
+}
+
+func (c *Client) newRequest(method, route, contentType string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.config.Host, "/")+route, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.config.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.BearerToken)
+	} else if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	return req, nil
+}
+
+//do Perform a request against the API server, mapping error statuses to typed errors
+func (c *Client) do(method, route, contentType string, body []byte) ([]byte, error) {
+	req, err := c.newRequest(method, route, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform request")
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response")
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return respBody, nil
+	}
+	return nil, statusError(resp.StatusCode, respBody)
+}
+
+//statusError Map an error status returned by the API server to a typed error
+func statusError(code int, respBody []byte) error {
+	message := string(respBody)
+	status := &v1.Status{}
+	if err := json.Unmarshal(respBody, status); err == nil && status.Message != "" {
+		message = status.Message
+	}
+	switch code {
+	case http.StatusConflict:
+		return &ConflictError{Message: message}
+	case http.StatusNotFound:
+		return &NotExistError{Message: message}
+	default:
+		return errors.New(fmt.Sprintf("non-200 status code returned, code=%d message=%s", code, message))
+	}
+}
@@ -0,0 +1,273 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const mockToken = "test-token"
+
+func newMockLogger() *logrus.Entry {
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+//mockAPIServer Fake Kubernetes API server storing secrets and the field manager that last applied them
+type mockAPIServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	secrets  map[string]*apiv1.Secret
+	managers map[string]string
+}
+
+func newMockAPIServer(t *testing.T) *mockAPIServer {
+	s := &mockAPIServer{secrets: map[string]*apiv1.Secret{}, managers: map[string]string{}}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *mockAPIServer) writeStatus(w http.ResponseWriter, code int, message string) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(&v1.Status{Status: v1.StatusFailure, Code: int32(code), Message: message})
+}
+
+func (s *mockAPIServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+mockToken {
+		s.writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 6 || parts[2] != "namespaces" || parts[4] != "secrets" {
+		s.writeStatus(w, http.StatusNotFound, "the server could not find the requested resource")
+		return
+	}
+	key := parts[3] + "/" + parts[5]
+	switch r.Method {
+	case http.MethodGet:
+		secret, ok := s.secrets[key]
+		if !ok {
+			s.writeStatus(w, http.StatusNotFound, fmt.Sprintf("secrets %q not found", parts[5]))
+			return
+		}
+		json.NewEncoder(w).Encode(secret)
+	case http.MethodPatch:
+		if r.Header.Get("Content-Type") != ContentTypeApplyPatch {
+			s.writeStatus(w, http.StatusUnsupportedMediaType, "unsupported patch type")
+			return
+		}
+		manager := r.URL.Query().Get("fieldManager")
+		if owner, ok := s.managers[key]; ok && owner != manager && r.URL.Query().Get("force") != "true" {
+			s.writeStatus(w, http.StatusConflict, fmt.Sprintf("Apply failed with 1 conflict: conflict with %q: .data.password", owner))
+			return
+		}
+		secret := &apiv1.Secret{}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, secret); err != nil {
+			s.writeStatus(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		code := http.StatusOK
+		if _, ok := s.secrets[key]; !ok {
+			code = http.StatusCreated
+		}
+		s.secrets[key] = secret
+		s.managers[key] = manager
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(secret)
+	default:
+		s.writeStatus(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+//writeMockKubeconfig Write a kubeconfig pointing at the mock server, trusting its certificate
+func writeMockKubeconfig(t *testing.T, server *mockAPIServer, user string) string {
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test-cluster
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+contexts:
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+    namespace: test-ns
+users:
+- name: test-user
+  user:
+%s
+`, server.URL, base64.StdEncoding.EncodeToString(caPEM), user)
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func newMockSecret() *apiv1.Secret {
+	return &apiv1.Secret{
+		TypeMeta:   v1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: v1.ObjectMeta{Name: "smtp"},
+		Data:       map[string][]byte{"password": []byte("test")},
+	}
+}
+
+func TestClient_ApplySecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		owner     string
+		force     bool
+		errCheck  func(error) bool
+	}{
+		{
+			name: "should create secret in namespace of the context",
+		},
+		{
+			name:      "should update secret in namespace",
+			namespace: "other",
+			owner:     FieldManager,
+		},
+		{
+			name:     "should fail on conflict with another field manager",
+			owner:    "kubectl",
+			errCheck: IsConflictError,
+		},
+		{
+			name:  "should force conflicts",
+			owner: "kubectl",
+			force: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockAPIServer(t)
+			defer server.Close()
+			path := writeMockKubeconfig(t, server, "    token: "+mockToken)
+			defer os.RemoveAll(filepath.Dir(path))
+			expectedNamespace := tt.namespace
+			if expectedNamespace == "" {
+				expectedNamespace = "test-ns"
+			}
+			if tt.owner != "" {
+				server.secrets[expectedNamespace+"/smtp"] = newMockSecret()
+				server.managers[expectedNamespace+"/smtp"] = tt.owner
+			}
+			config, err := LoadKubeconfig(path, "")
+			if err != nil {
+				t.Fatalf("failed to load kubeconfig: %v", err)
+			}
+			client, err := NewClient(config, newMockLogger())
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			got, err := client.ApplySecret(tt.namespace, newMockSecret(), tt.force)
+			if tt.errCheck != nil {
+				if !tt.errCheck(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Namespace != expectedNamespace || string(got.Data["password"]) != "test" {
+				t.Fatalf("unexpected secret %v", got)
+			}
+			if server.managers[expectedNamespace+"/smtp"] != FieldManager {
+				t.Fatalf("expected secret to be applied by %s", FieldManager)
+			}
+			stored, err := client.GetSecret(tt.namespace, "smtp")
+			if err != nil {
+				t.Fatalf("failed to get secret: %v", err)
+			}
+			if stored.Name != "smtp" {
+				t.Fatalf("unexpected stored secret %v", stored)
+			}
+		})
+	}
+}
+
+func TestClient_GetSecretNotExist(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+	path := writeMockKubeconfig(t, server, "    token: "+mockToken)
+	defer os.RemoveAll(filepath.Dir(path))
+	config, err := LoadKubeconfig(path, "")
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	client, err := NewClient(config, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.GetSecret("", "missing"); !IsNotExistError(err) {
+		t.Fatalf("expected not exist error, got %v", err)
+	}
+}
+
+func TestClient_Watch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("watch") != "true" || query.Get("resourceVersion") != "10" || query.Get("timeoutSeconds") != "60" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		encoder := json.NewEncoder(w)
+		encoder.Encode(map[string]interface{}{"type": "ADDED", "object": newMockSecret()})
+		encoder.Encode(map[string]interface{}{"type": WatchEventDeleted, "object": newMockSecret()})
+	}))
+	defer server.Close()
+	client, err := NewClient(&Config{Host: server.URL}, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	watcher, err := client.Watch("/api/v1/secrets", "10", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer watcher.Close()
+	var types []string
+	for {
+		event, err := watcher.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		secret := &apiv1.Secret{}
+		if err := json.Unmarshal(event.Object, secret); err != nil || secret.Name != "smtp" {
+			t.Fatalf("unexpected object %s", event.Object)
+		}
+		types = append(types, event.Type)
+	}
+	if strings.Join(types, ",") != "ADDED,DELETED" {
+		t.Fatalf("unexpected events %v", types)
+	}
+}
@@ -0,0 +1,257 @@
+//Package kubernetes Minimal Kubernetes API client covering the secrets and custom resources of this service, without
+//depending on client-go. Kubeconfig support is limited to a single file with token, token file, basic auth or client
+//certificate credentials and an inline or file certificate authority. Exec and auth provider plugins, impersonation,
+//proxy-url and tls-server-name are rejected instead of being ignored, and only the first path of the KUBECONFIG env var
+//is read, as merging kubeconfig files is not supported. Use a service account token or a flattened kubeconfig, e.g.
+//from oc config view --flatten --minify, when one of these is needed.
+package kubernetes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+//Config Connection details of a Kubernetes API server
+type Config struct {
+	Host        string
+	BearerToken string
+	Username    string
+	Password    string
+	Namespace   string
+	TLSConfig   *tls.Config
+}
+
+//kubeconfig The subset of the kubeconfig format needed to connect to a cluster
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Clusters       []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server                   string `json:"server"`
+			CertificateAuthority     string `json:"certificate-authority"`
+			CertificateAuthorityData string `json:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+			ProxyURL                 string `json:"proxy-url"`
+			TLSServerName            string `json:"tls-server-name"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			User      string `json:"user"`
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Users []struct {
+		Name string `json:"name"`
+		User struct {
+			Token                 string      `json:"token"`
+			TokenFile             string      `json:"tokenFile"`
+			ClientCertificate     string      `json:"client-certificate"`
+			ClientCertificateData string      `json:"client-certificate-data"`
+			ClientKey             string      `json:"client-key"`
+			ClientKeyData         string      `json:"client-key-data"`
+			Username              string      `json:"username"`
+			Password              string      `json:"password"`
+			Exec                  interface{} `json:"exec"`
+			AuthProvider          interface{} `json:"auth-provider"`
+			Impersonate           string      `json:"as"`
+			ImpersonateGroups     []string    `json:"as-groups"`
+		} `json:"user"`
+	} `json:"users"`
+}
+
+//DefaultKubeconfigPath The kubeconfig path from the KUBECONFIG env var, or ~/.kube/config
+func DefaultKubeconfigPath() string {
+	if path := os.Getenv(EnvKubeconfig); path != "" {
+		return strings.Split(path, string(os.PathListSeparator))[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+//LoadConfig Load the kubeconfig at a path. A blank path uses the default kubeconfig, or the in-cluster config when
+//running in a pod and the default kubeconfig does not exist. An explicit path must exist, so a mistyped path never
+//silently falls back to the cluster the pod runs in.
+func LoadConfig(kubeconfigPath string) (*Config, error) {
+	if kubeconfigPath == "" {
+		kubeconfigPath = DefaultKubeconfigPath()
+		if _, err := os.Stat(kubeconfigPath); err != nil && os.Getenv(EnvServiceHost) != "" {
+			return InClusterConfig()
+		}
+	}
+	return LoadKubeconfig(kubeconfigPath, "")
+}
+
+//LoadKubeconfig Load the config of a context from a kubeconfig file, a blank context uses the current context
+func LoadKubeconfig(path, contextName string) (*Config, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read kubeconfig %s", path)
+	}
+	kc := &kubeconfig{}
+	if err := yaml.Unmarshal(raw, kc); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kubeconfig %s", path)
+	}
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	baseDir := filepath.Dir(path)
+	config := &Config{Namespace: DefaultNamespace}
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			if c.Context.Namespace != "" {
+				config.Namespace = c.Context.Namespace
+			}
+		}
+	}
+	if !found {
+		return nil, &NotExistError{Message: fmt.Sprintf("context %s does not exist in kubeconfig %s", contextName, path)}
+	}
+	tlsConfig := &tls.Config{}
+	found = false
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		if c.Cluster.ProxyURL != "" || c.Cluster.TLSServerName != "" {
+			return nil, errors.New(fmt.Sprintf("cluster %s uses proxy-url or tls-server-name, which is not supported", clusterName))
+		}
+		config.Host = c.Cluster.Server
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := readData(c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, baseDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read certificate authority of cluster %s", clusterName)
+		}
+		if ca != nil {
+			if tlsConfig.RootCAs, err = certPool(ca); err != nil {
+				return nil, errors.Wrapf(err, "invalid certificate authority of cluster %s", clusterName)
+			}
+		}
+	}
+	if !found {
+		return nil, &NotExistError{Message: fmt.Sprintf("cluster %s does not exist in kubeconfig %s", clusterName, path)}
+	}
+	found = false
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		found = true
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return nil, errors.New(fmt.Sprintf("user %s uses an exec or auth provider plugin, which is not supported, use a token or client certificate", userName))
+		}
+		if u.User.Impersonate != "" || len(u.User.ImpersonateGroups) > 0 {
+			return nil, errors.New(fmt.Sprintf("user %s uses impersonation, which is not supported", userName))
+		}
+		config.BearerToken = u.User.Token
+		if config.BearerToken == "" && u.User.TokenFile != "" {
+			token, err := ioutil.ReadFile(resolvePath(u.User.TokenFile, baseDir))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read token file of user %s", userName)
+			}
+			config.BearerToken = strings.TrimSpace(string(token))
+		}
+		config.Username = u.User.Username
+		config.Password = u.User.Password
+		cert, err := readData(u.User.ClientCertificateData, u.User.ClientCertificate, baseDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read client certificate of user %s", userName)
+		}
+		key, err := readData(u.User.ClientKeyData, u.User.ClientKey, baseDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read client key of user %s", userName)
+		}
+		if (cert == nil) != (key == nil) {
+			return nil, errors.New(fmt.Sprintf("user %s must define both a client certificate and a client key", userName))
+		}
+		if cert != nil {
+			keyPair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid client certificate of user %s", userName)
+			}
+			tlsConfig.Certificates = []tls.Certificate{keyPair}
+		}
+	}
+	// a context without a user connects anonymously
+	if !found && userName != "" {
+		return nil, &NotExistError{Message: fmt.Sprintf("user %s does not exist in kubeconfig %s", userName, path)}
+	}
+	config.TLSConfig = tlsConfig
+	return config, nil
+}
+
+//InClusterConfig Load the config from the service account mounted in a pod
+func InClusterConfig() (*Config, error) {
+	host, port := os.Getenv(EnvServiceHost), os.Getenv(EnvServicePort)
+	if host == "" || port == "" {
+		return nil, errors.New(fmt.Sprintf("not running in a cluster, %s and %s must be set", EnvServiceHost, EnvServicePort))
+	}
+	token, err := ioutil.ReadFile(filepath.Join(ServiceAccountDir, "token"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read service account token")
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(ServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read service account certificate authority")
+	}
+	rootCAs, err := certPool(ca)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid service account certificate authority")
+	}
+	namespace := DefaultNamespace
+	if ns, err := ioutil.ReadFile(filepath.Join(ServiceAccountDir, "namespace")); err == nil {
+		namespace = strings.TrimSpace(string(ns))
+	}
+	return &Config{
+		Host:        "https://" + net.JoinHostPort(host, port),
+		BearerToken: strings.TrimSpace(string(token)),
+		Namespace:   namespace,
+		TLSConfig:   &tls.Config{RootCAs: rootCAs},
+	}, nil
+}
+
+//readData Read base64 encoded inline data, or the file at path if no data is set
+func readData(data, path, baseDir string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return ioutil.ReadFile(resolvePath(path, baseDir))
+}
+
+//resolvePath Resolve paths in a kubeconfig relative to the directory of the kubeconfig
+func resolvePath(path, baseDir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+func certPool(pem []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found")
+	}
+	return pool, nil
+}
@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadKubeconfig(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+	tests := []struct {
+		name        string
+		user        string
+		context     string
+		missingUser bool
+		expectErr   bool
+		validate    func(t *testing.T, config *Config)
+	}{
+		{
+			name: "should load token, namespace and certificate authority",
+			user: "    token: " + mockToken,
+			validate: func(t *testing.T, config *Config) {
+				if config.Host != server.URL || config.BearerToken != mockToken || config.Namespace != "test-ns" {
+					t.Fatalf("unexpected config %v", config)
+				}
+				if config.TLSConfig.RootCAs == nil {
+					t.Fatal("expected certificate authority to be loaded")
+				}
+			},
+		},
+		{
+			name: "should load basic auth",
+			user: "    username: admin\n    password: secret",
+			validate: func(t *testing.T, config *Config) {
+				if config.Username != "admin" || config.Password != "secret" {
+					t.Fatalf("unexpected config %v", config)
+				}
+			},
+		},
+		{
+			name:      "should fail on exec plugin",
+			user:      "    exec:\n      command: oc",
+			expectErr: true,
+		},
+		{
+			name:      "should fail on impersonation",
+			user:      "    token: " + mockToken + "\n    as: admin",
+			expectErr: true,
+		},
+		{
+			name:      "should fail on client certificate without key",
+			user:      "    client-certificate-data: dGVzdA==",
+			expectErr: true,
+		},
+		{
+			name:      "should fail on missing context",
+			user:      "    token: " + mockToken,
+			context:   "missing",
+			expectErr: true,
+		},
+		{
+			name:        "should fail on missing user",
+			user:        "    token: " + mockToken,
+			missingUser: true,
+			expectErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeMockKubeconfig(t, server, tt.user)
+			defer os.RemoveAll(filepath.Dir(path))
+			if tt.missingUser {
+				raw, err := ioutil.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read kubeconfig: %v", err)
+				}
+				raw = []byte(strings.Replace(string(raw), "- name: test-user", "- name: other-user", 1))
+				if err := ioutil.WriteFile(path, raw, 0600); err != nil {
+					t.Fatalf("failed to write kubeconfig: %v", err)
+				}
+			}
+			config, err := LoadKubeconfig(path, tt.context)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if tt.missingUser && !IsNotExistError(err) {
+					t.Fatalf("expected not exist error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, config)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	// an explicit path must not fall back to the in-cluster config, even when running in a pod
+	os.Setenv(EnvServiceHost, "127.0.0.1")
+	defer os.Unsetenv(EnvServiceHost)
+	if _, err := LoadConfig(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected error for a missing explicit kubeconfig")
+	}
+}
+
+func TestInClusterConfig(t *testing.T) {
+	os.Unsetenv(EnvServiceHost)
+	if _, err := InClusterConfig(); err == nil {
+		t.Fatal("expected error outside of a cluster")
+	}
+}
@@ -0,0 +1,33 @@
+package kubernetes
+
+//ConflictError Error to indicate applied fields are owned by another field manager
+type ConflictError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+//IsConflictError Compare check for ConflictError
+func IsConflictError(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+//NotExistError Error to indicate a Kubernetes resource does not exist
+type NotExistError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *NotExistError) Error() string {
+	return e.Message
+}
+
+//IsNotExistError Compare check for NotExistError
+func IsNotExistError(err error) bool {
+	_, ok := err.(*NotExistError)
+	return ok
+}
@@ -0,0 +1,30 @@
+package kubernetes
+
+const (
+	//LogFieldKubernetesClient Logging key for specifying the Kubernetes API host
+	LogFieldKubernetesClient = "smtp_service_kubernetes_client"
+	//EnvKubeconfig Name of the env var to retrieve the kubeconfig path
+	EnvKubeconfig = "KUBECONFIG"
+	//EnvServiceHost Name of the env var set in pods with the Kubernetes API host
+	EnvServiceHost = "KUBERNETES_SERVICE_HOST"
+	//EnvServicePort Name of the env var set in pods with the Kubernetes API port
+	EnvServicePort = "KUBERNETES_SERVICE_PORT"
+	//ServiceAccountDir Directory the service account token, CA and namespace are mounted in pods
+	ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	//DefaultNamespace Namespace used when none is configured
+	DefaultNamespace = "default"
+	//FieldManager Field manager recorded by the Kubernetes API for fields applied by this service
+	FieldManager = "smtp-service"
+	//APIRouteSecret Kubernetes API route for a secret in a namespace
+	APIRouteSecret = "/api/v1/namespaces/%s/secrets/%s"
+	//ContentTypeApplyPatch Content type of a server-side apply request
+	ContentTypeApplyPatch = "application/apply-patch+yaml"
+	//ContentTypeMergePatch Content type of a JSON merge patch request
+	ContentTypeMergePatch = "application/merge-patch+json"
+	//WatchEventBookmark Type of watch event only carrying a new resource version
+	WatchEventBookmark = "BOOKMARK"
+	//WatchEventDeleted Type of watch event for a deleted resource
+	WatchEventDeleted = "DELETED"
+	//WatchEventError Type of watch event for an error, such as an expired resource version
+	WatchEventError = "ERROR"
+)
@@ -0,0 +1,284 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+//DefaultBuckets Upper bounds in seconds of the histogram buckets of request and operation durations
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+//Default Registry the instrumented packages register their metrics with
+var Default = NewRegistry()
+
+type metric interface {
+	metricName() string
+	write(w io.Writer) error
+}
+
+//Registry Counters and histograms exposed in the Prometheus text exposition format
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+//NewRegistry Create a new empty Registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+//NewCounter Register a new counter, the name must be unique within the registry
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, samples: map[string]*counterSample{}}
+	r.register(c)
+	return c
+}
+
+//NewHistogram Register a new histogram with buckets of the given upper bounds, the name must be unique within the
+//registry
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	h := &Histogram{name: name, help: help, labels: labels, buckets: sorted, samples: map[string]*histogramSample{}}
+	r.register(h)
+	return h
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[m.metricName()]; ok {
+		panic(fmt.Sprintf("metric %s is already registered", m.metricName()))
+	}
+	r.metrics[m.metricName()] = m
+}
+
+//WritePrometheus Write all metrics to w in the Prometheus text exposition format, sorted by name
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	metrics := make([]metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].metricName() < metrics[j].metricName()
+	})
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//WriteFile Write all metrics to the file at path in the Prometheus text exposition format
+func (r *Registry) WriteFile(path string) error {
+	var out bytes.Buffer
+	if err := r.WritePrometheus(&out); err != nil {
+		return errors.Wrap(err, "failed to format metrics")
+	}
+	if err := ioutil.WriteFile(path, out.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write metrics file %s", path)
+	}
+	return nil
+}
+
+//Handler HTTP handler exposing the metrics of the registry in the Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.WritePrometheus(w); err != nil {
+			http.Error(w, "failed to write metrics", http.StatusInternalServerError)
+		}
+	})
+}
+
+//Counter Metric counting events, partitioned by the values of its labels
+type Counter struct {
+	name    string
+	help    string
+	labels  []string
+	mu      sync.Mutex
+	samples map[string]*counterSample
+}
+
+type counterSample struct {
+	labelValues []string
+	value       float64
+}
+
+//Inc Increment the counter of the label values by one
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+//Add Increase the counter of the label values by v
+func (c *Counter) Add(v float64, labelValues ...string) {
+	key := sampleKey(c.name, c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sample, ok := c.samples[key]
+	if !ok {
+		sample = &counterSample{labelValues: append([]string{}, labelValues...)}
+		c.samples[key] = sample
+	}
+	sample.value += v
+}
+
+//Value Current value of the counter of the label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := sampleKey(c.name, c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sample, ok := c.samples[key]; ok {
+		return sample.value
+	}
+	return 0
+}
+
+func (c *Counter) metricName() string {
+	return c.name
+}
+
+func (c *Counter) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(c.samples))
+	for key := range c.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sample := c.samples[key]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, sample.labelValues), formatValue(sample.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Histogram Metric sampling observations such as durations into buckets, partitioned by the values of its labels
+type Histogram struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	samples map[string]*histogramSample
+}
+
+type histogramSample struct {
+	labelValues  []string
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+//Observe Add an observation to the histogram of the label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := sampleKey(h.name, h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sample, ok := h.samples[key]
+	if !ok {
+		sample = &histogramSample{labelValues: append([]string{}, labelValues...), bucketCounts: make([]uint64, len(h.buckets))}
+		h.samples[key] = sample
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			sample.bucketCounts[i]++
+		}
+	}
+	sample.count++
+	sample.sum += v
+}
+
+//Count Number of observations of the histogram of the label values
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := sampleKey(h.name, h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sample, ok := h.samples[key]; ok {
+		return sample.count
+	}
+	return 0
+}
+
+func (h *Histogram) metricName() string {
+	return h.name
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	bucketLabels := append(append([]string{}, h.labels...), "le")
+	keys := make([]string, 0, len(h.samples))
+	for key := range h.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sample := h.samples[key]
+		for i, bound := range h.buckets {
+			labels := formatLabels(bucketLabels, append(append([]string{}, sample.labelValues...), formatValue(bound)))
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, sample.bucketCounts[i]); err != nil {
+				return err
+			}
+		}
+		labels := formatLabels(bucketLabels, append(append([]string{}, sample.labelValues...), "+Inf"))
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, sample.count); err != nil {
+			return err
+		}
+		labels = formatLabels(h.labels, sample.labelValues)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.name, labels, formatValue(sample.sum), h.name, labels, sample.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//sampleKey Key of the sample of the label values, panics if the number of values does not match the labels as that
+//is a programming error
+func sampleKey(name string, labels, labelValues []string) string {
+	if len(labels) != len(labelValues) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", name, len(labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatLabels(labels, labelValues []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for i, label := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label, labelValueEscaper.Replace(labelValues[i])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
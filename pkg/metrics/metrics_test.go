@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry_WritePrometheus(t *testing.T) {
+	tests := []struct {
+		name   string
+		record func(r *Registry)
+		want   string
+	}{
+		{
+			name: "counter without samples should only write metadata",
+			record: func(r *Registry) {
+				r.NewCounter("test_total", "Test counter.", "route")
+			},
+			want: "# HELP test_total Test counter.\n# TYPE test_total counter\n",
+		},
+		{
+			name: "counter samples should be sorted by label values and escaped",
+			record: func(r *Registry) {
+				c := r.NewCounter("test_total", "Test counter.", "route", "status")
+				c.Inc("/b", "200")
+				c.Add(2, "/a", "500")
+				c.Inc("/b", "200")
+				c.Inc("/\"c\"", "200")
+			},
+			want: "# HELP test_total Test counter.\n# TYPE test_total counter\n" +
+				"test_total{route=\"/\\\"c\\\"\",status=\"200\"} 1\n" +
+				"test_total{route=\"/a\",status=\"500\"} 2\n" +
+				"test_total{route=\"/b\",status=\"200\"} 2\n",
+		},
+		{
+			name: "histogram should write cumulative buckets, sum and count",
+			record: func(r *Registry) {
+				h := r.NewHistogram("test_seconds", "Test histogram.", []float64{1, 0.1}, "route")
+				h.Observe(0.05, "/a")
+				h.Observe(0.5, "/a")
+				h.Observe(5, "/a")
+			},
+			want: "# HELP test_seconds Test histogram.\n# TYPE test_seconds histogram\n" +
+				"test_seconds_bucket{route=\"/a\",le=\"0.1\"} 1\n" +
+				"test_seconds_bucket{route=\"/a\",le=\"1\"} 2\n" +
+				"test_seconds_bucket{route=\"/a\",le=\"+Inf\"} 3\n" +
+				"test_seconds_sum{route=\"/a\"} 5.55\n" +
+				"test_seconds_count{route=\"/a\"} 3\n",
+		},
+		{
+			name: "metrics should be sorted by name",
+			record: func(r *Registry) {
+				r.NewCounter("b_total", "B.").Inc()
+				r.NewCounter("a_total", "A.").Inc()
+			},
+			want: "# HELP a_total A.\n# TYPE a_total counter\na_total 1\n# HELP b_total B.\n# TYPE b_total counter\nb_total 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.record(r)
+			var out bytes.Buffer
+			if err := r.WritePrometheus(&out); err != nil {
+				t.Fatalf("WritePrometheus() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("WritePrometheus() got = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_DuplicateName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewCounter() should panic on a duplicate name")
+		}
+	}()
+	r := NewRegistry()
+	r.NewCounter("test_total", "Test counter.")
+	r.NewHistogram("test_total", "Test histogram.", DefaultBuckets)
+}
+
+func TestCounter_LabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Inc() should panic on a label value mismatch")
+		}
+	}()
+	NewRegistry().NewCounter("test_total", "Test counter.", "route").Inc()
+}
+
+func TestRegistry_WriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	r := NewRegistry()
+	r.NewCounter("test_total", "Test counter.").Inc()
+	path := filepath.Join(dir, "metrics.prom")
+	if err := r.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read metrics file: %v", err)
+	}
+	if want := "# HELP test_total Test counter.\n# TYPE test_total counter\ntest_total 1\n"; string(got) != want {
+		t.Errorf("WriteFile() got = %q, want %q", string(got), want)
+	}
+	if err := r.WriteFile(filepath.Join(dir, "missing", "metrics.prom")); err == nil {
+		t.Errorf("WriteFile() to a missing directory should cause error")
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_total", "Test counter.").Inc()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Handler() got code %d content type %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte("test_total 1\n")) {
+		t.Errorf("Handler() got = %s, want test_total counter", rec.Body.String())
+	}
+}
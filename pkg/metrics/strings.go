@@ -0,0 +1,12 @@
+package metrics
+
+const (
+	//Route HTTP route exposing the metrics of a registry
+	Route = "/metrics"
+	//ContentType HTTP content type of the Prometheus text exposition format
+	ContentType = "text/plain; version=0.0.4"
+	//OutcomeSuccess Label value of an operation that succeeded
+	OutcomeSuccess = "success"
+	//OutcomeFailure Label value of an operation that failed
+	OutcomeFailure = "failure"
+)
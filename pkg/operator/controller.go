@@ -0,0 +1,128 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+//Controller Watches SMTPCredentials, reconciling them as they change and all of them every resync period so that
+//rotations become due and failures are retried
+type Controller struct {
+	kubeClient   *kubernetes.Client
+	reconciler   *Reconciler
+	namespace    string
+	resyncPeriod time.Duration
+	logger       *logrus.Entry
+}
+
+//NewController Create a new Controller, a blank namespace watches all namespaces
+func NewController(kubeClient *kubernetes.Client, reconciler *Reconciler, namespace string, resyncPeriod time.Duration, logger *logrus.Entry) *Controller {
+	return &Controller{
+		kubeClient:   kubeClient,
+		reconciler:   reconciler,
+		namespace:    namespace,
+		resyncPeriod: resyncPeriod,
+		logger:       logger,
+	}
+}
+
+//Run Reconcile SMTPCredentials until stop is closed
+func (c *Controller) Run(stop <-chan struct{}) error {
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		resourceVersion, err := c.resync()
+		if err != nil {
+			c.logger.Errorf("failed to list smtp credentials, retrying after %s: %v", c.resyncPeriod, err)
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(c.resyncPeriod):
+			}
+			continue
+		}
+		if err := c.watch(resourceVersion, stop); err != nil && err != io.EOF {
+			c.logger.Warnf("watch of smtp credentials ended: %v", err)
+		}
+	}
+}
+
+func (c *Controller) route() string {
+	if c.namespace == "" {
+		return APIRouteCredentials
+	}
+	return fmt.Sprintf(APIRouteNamespacedCredentials, c.namespace)
+}
+
+//resync Reconcile all SMTPCredentials, returning the resource version to watch from
+func (c *Controller) resync() (string, error) {
+	list := &SMTPCredentialList{}
+	if err := c.kubeClient.Get(c.route(), list); err != nil {
+		return "", err
+	}
+	c.logger.Debugf("resyncing %d smtp credentials", len(list.Items))
+	for i := range list.Items {
+		c.reconcile(&list.Items[i])
+	}
+	return list.ResourceVersion, nil
+}
+
+//watch Reconcile SMTPCredentials as they change until the watch times out after the resync period
+func (c *Controller) watch(resourceVersion string, stop <-chan struct{}) error {
+	watcher, err := c.kubeClient.Watch(c.route(), resourceVersion, c.resyncPeriod)
+	if err != nil {
+		c.logger.Errorf("failed to watch smtp credentials, resyncing after %s: %v", c.resyncPeriod, err)
+		select {
+		case <-stop:
+		case <-time.After(c.resyncPeriod):
+		}
+		return nil
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+		watcher.Close()
+	}()
+	for {
+		event, err := watcher.Next()
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+				return err
+			}
+		}
+		switch event.Type {
+		case kubernetes.WatchEventBookmark, kubernetes.WatchEventDeleted:
+			continue
+		case kubernetes.WatchEventError:
+			return errors.New(fmt.Sprintf("watch error event: %s", event.Object))
+		}
+		credential := &SMTPCredential{}
+		if err := json.Unmarshal(event.Object, credential); err != nil {
+			c.logger.Errorf("failed to decode smtp credential: %v", err)
+			continue
+		}
+		c.reconcile(credential)
+	}
+}
+
+func (c *Controller) reconcile(credential *SMTPCredential) {
+	if err := c.reconciler.Reconcile(credential); err != nil {
+		c.logger.WithField(LogFieldCredential, credential.Namespace+"/"+credential.Name).Errorf("failed to reconcile smtp credential: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+)
+
+func TestController_Run(t *testing.T) {
+	server := newMockAPIServer(newMockCredential())
+	defer server.Close()
+	server.watchEvents = []string{"MODIFIED", kubernetes.WatchEventBookmark}
+	client := newMockSMTPDetailsClient(false)
+	reconciler := newMockReconciler(t, server, client)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- NewController(reconciler.kubeClient, reconciler, "", time.Second, newMockLogger()).Run(stop)
+	}()
+	deadline := time.After(5 * time.Second)
+	for len(client.GetCalls()) < 2 {
+		select {
+		case <-deadline:
+			t.Fatal("expected credential to be reconciled on resync and watch event")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.CreateCalls()) != 1 {
+		t.Fatalf("expected smtp details to be created once, got %d", len(client.CreateCalls()))
+	}
+}
@@ -0,0 +1,240 @@
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//ClientFactory Create the smtpdetails.Client for the provider and scopes of an SMTPCredential
+type ClientFactory func(credential *SMTPCredential) (smtpdetails.Client, error)
+
+//Reconciler Drives an smtpdetails.Client to create, rotate and delete the SMTP details of SMTPCredentials
+type Reconciler struct {
+	kubeClient    *kubernetes.Client
+	clientFactory ClientFactory
+	logger        *logrus.Entry
+	now           func() time.Time
+}
+
+//NewReconciler Create a new Reconciler
+func NewReconciler(kubeClient *kubernetes.Client, clientFactory ClientFactory, logger *logrus.Entry) (*Reconciler, error) {
+	if kubeClient == nil {
+		return nil, errors.New("kubeClient must be defined")
+	}
+	if clientFactory == nil {
+		return nil, errors.New("clientFactory must be defined")
+	}
+	return &Reconciler{
+		kubeClient:    kubeClient,
+		clientFactory: clientFactory,
+		logger:        logger,
+		now:           time.Now,
+	}, nil
+}
+
+//Reconcile Bring the secret of an SMTPCredential in line with its spec, updating its status
+func (r *Reconciler) Reconcile(credential *SMTPCredential) error {
+	logger := r.logger.WithField(LogFieldCredential, credential.Namespace+"/"+credential.Name)
+	if credential.DeletionTimestamp != nil {
+		return r.finalize(credential, logger)
+	}
+	if !credential.HasFinalizer() {
+		logger.Debugf("adding finalizer %s", Finalizer)
+		if err := r.setFinalizers(credential, append(credential.Finalizers, Finalizer)); err != nil {
+			return err
+		}
+	}
+	status := credential.Status.DeepCopy()
+	status.ObservedGeneration = credential.Generation
+	err := r.reconcileSecret(credential, status, logger)
+	if patchErr := r.patchStatus(credential, status); patchErr != nil {
+		return patchErr
+	}
+	return err
+}
+
+//reconcileSecret Create the SMTP details and secret if either is missing, rotating them when due
+func (r *Reconciler) reconcileSecret(credential *SMTPCredential, status *SMTPCredentialStatus, logger *logrus.Entry) error {
+	now := r.now()
+	interval, err := credential.Spec.RotationInterval()
+	if err == nil && (credential.Spec.ClusterID == "" || credential.Spec.SecretName == "") {
+		err = errors.New("clusterId and secretName must be defined")
+	}
+	if err != nil {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonInvalidSpec, Message: err.Error()}, now)
+		return nil
+	}
+	owner, err := r.owner(credential)
+	if err != nil {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonAPIError, Message: err.Error()}, now)
+		return err
+	}
+	if owner != nil {
+		// both credentials would rotate the api key of the same sub user, invalidating each other's secret
+		message := fmt.Sprintf("cluster id %s is already used by %s/%s", credential.Spec.ClusterID, owner.Namespace, owner.Name)
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonClusterIDConflict, Message: message}, now)
+		return nil
+	}
+	client, err := r.clientFactory(credential)
+	if err != nil {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonInvalidSpec, Message: err.Error()}, now)
+		return nil
+	}
+	_, err = r.kubeClient.GetSecret(credential.Namespace, credential.Spec.SecretName)
+	secretExists := err == nil
+	if err != nil && !kubernetes.IsNotExistError(err) {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonSecretError, Message: err.Error()}, now)
+		return err
+	}
+	_, err = client.Get(credential.Spec.ClusterID)
+	detailsExist := err == nil
+	if err != nil && !smtpdetails.IsNotExistError(err) {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonProviderError, Message: err.Error()}, now)
+		return err
+	}
+	if secretExists && detailsExist && status.LastRotationTime == nil {
+		// adopted secret, start the rotation clock now
+		status.LastRotationTime = &v1.Time{Time: now}
+	}
+	rotationDue := interval > 0 && status.LastRotationTime != nil && !now.Before(status.LastRotationTime.Add(interval))
+	if secretExists && detailsExist && !rotationDue && status.SecretName == credential.Spec.SecretName {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionTrue, Reason: ReasonReconciled}, now)
+		return nil
+	}
+	var details *smtpdetails.SMTPDetails
+	if detailsExist {
+		// the api key of existing details cannot be retrieved, a new key is generated for the secret
+		logger.Infof("refreshing smtp details, secretExists=%t rotationDue=%t", secretExists, rotationDue)
+		details, err = client.Refresh(credential.Spec.ClusterID)
+	} else {
+		logger.Info("creating smtp details")
+		details, err = client.Create(credential.Spec.ClusterID)
+	}
+	if err != nil {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonProviderError, Message: err.Error()}, now)
+		return errors.Wrapf(err, "failed to create smtp details for cluster %s", credential.Spec.ClusterID)
+	}
+	secret := smtpdetails.ConvertSMTPDetailsToSecret(details, credential.Spec.SecretName)
+	secret.OwnerReferences = []v1.OwnerReference{ownerReference(credential)}
+	if _, err := r.kubeClient.ApplySecret(credential.Namespace, secret, true); err != nil {
+		status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionFalse, Reason: ReasonSecretError, Message: err.Error()}, now)
+		return errors.Wrapf(err, "failed to apply secret %s", credential.Spec.SecretName)
+	}
+	status.SecretName = credential.Spec.SecretName
+	status.LastRotationTime = &v1.Time{Time: now}
+	status.SetCondition(Condition{Type: ConditionReady, Status: v1.ConditionTrue, Reason: ReasonReconciled}, now)
+	return nil
+}
+
+//finalize Delete the SMTP details of a deleted SMTPCredential and release it by removing the finalizer, the secret
+//is garbage collected through its owner reference
+func (r *Reconciler) finalize(credential *SMTPCredential, logger *logrus.Entry) error {
+	if !credential.HasFinalizer() {
+		return nil
+	}
+	owner, err := r.owner(credential)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		logger.Infof("keeping smtp details for cluster %s, they are owned by %s/%s", credential.Spec.ClusterID, owner.Namespace, owner.Name)
+	} else {
+		client, err := r.clientFactory(credential)
+		if err != nil {
+			return errors.Wrap(err, "failed to create smtp details client")
+		}
+		logger.Infof("deleting smtp details for cluster %s", credential.Spec.ClusterID)
+		if err := client.Delete(credential.Spec.ClusterID); err != nil && !smtpdetails.IsNotExistError(err) {
+			return errors.Wrapf(err, "failed to delete smtp details for cluster %s", credential.Spec.ClusterID)
+		}
+	}
+	var finalizers []string
+	for _, f := range credential.Finalizers {
+		if f != Finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	return r.setFinalizers(credential, finalizers)
+}
+
+//owner Find the SMTPCredential in any namespace owning the SMTP details of the cluster id of a credential, nil if the
+//credential owns them itself. The oldest credential of a cluster id owns its SMTP details, ties are broken by
+//namespace and name, so every reconcile picks the same owner.
+func (r *Reconciler) owner(credential *SMTPCredential) (*SMTPCredential, error) {
+	list := &SMTPCredentialList{}
+	if err := r.kubeClient.Get(APIRouteCredentials, list); err != nil {
+		return nil, errors.Wrapf(err, "failed to list smtp credentials to check cluster id %s is unique", credential.Spec.ClusterID)
+	}
+	var owner *SMTPCredential
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.UID == credential.UID || other.Spec.ClusterID != credential.Spec.ClusterID || !olderCredential(other, credential) {
+			continue
+		}
+		if owner == nil || olderCredential(other, owner) {
+			owner = other
+		}
+	}
+	return owner, nil
+}
+
+//olderCredential Whether credential a was created before b, ties are broken by namespace and name
+func olderCredential(a, b *SMTPCredential) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
+
+//setFinalizers Replace the finalizers of an SMTPCredential, failing on conflict if it changed since it was read
+func (r *Reconciler) setFinalizers(credential *SMTPCredential, finalizers []string) error {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": credential.ResourceVersion,
+		},
+	}
+	route := fmt.Sprintf(APIRouteCredential, credential.Namespace, credential.Name)
+	updated := &SMTPCredential{}
+	if err := r.kubeClient.Patch(route, kubernetes.ContentTypeMergePatch, patch, updated); err != nil {
+		return errors.Wrapf(err, "failed to set finalizers of %s/%s", credential.Namespace, credential.Name)
+	}
+	credential.ObjectMeta = updated.ObjectMeta
+	return nil
+}
+
+//patchStatus Update the status of an SMTPCredential if it changed, an unchanged status is not written so the watch
+//event of the update does not cause another update
+func (r *Reconciler) patchStatus(credential *SMTPCredential, status *SMTPCredentialStatus) error {
+	if reflect.DeepEqual(&credential.Status, status) {
+		return nil
+	}
+	route := fmt.Sprintf(APIRouteCredentialStatus, credential.Namespace, credential.Name)
+	if err := r.kubeClient.Patch(route, kubernetes.ContentTypeMergePatch, map[string]interface{}{"status": status}, nil); err != nil {
+		return errors.Wrapf(err, "failed to update status of %s/%s", credential.Namespace, credential.Name)
+	}
+	credential.Status = *status
+	return nil
+}
+
+func ownerReference(credential *SMTPCredential) v1.OwnerReference {
+	controller := true
+	return v1.OwnerReference{
+		APIVersion:         Group + "/" + Version,
+		Kind:               Kind,
+		Name:               credential.Name,
+		UID:                credential.UID,
+		Controller:         &controller,
+		BlockOwnerDeletion: &controller,
+	}
+}
@@ -0,0 +1,470 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/kubernetes"
+	"github.com/integr8ly/smtp-service/pkg/sendgrid"
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	mockNamespace  = "test-ns"
+	mockName       = "test"
+	mockClusterID  = "test-cluster"
+	mockSecretName = "test-smtp"
+)
+
+var mockNow = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newMockLogger() *logrus.Entry {
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+func newMockCredential() *SMTPCredential {
+	return &SMTPCredential{
+		TypeMeta: v1.TypeMeta{Kind: Kind, APIVersion: Group + "/" + Version},
+		ObjectMeta: v1.ObjectMeta{
+			Name:              mockName,
+			Namespace:         mockNamespace,
+			UID:               "test-uid",
+			ResourceVersion:   "1",
+			CreationTimestamp: v1.NewTime(mockNow),
+		},
+		Spec: SMTPCredentialSpec{
+			ClusterID:  mockClusterID,
+			SecretName: mockSecretName,
+		},
+	}
+}
+
+//newMockConflictingCredential Credential in another namespace with the same cluster id, created age after the mock
+//credential
+func newMockConflictingCredential(age time.Duration) *SMTPCredential {
+	credential := newMockCredential()
+	credential.Namespace = "other-ns"
+	credential.UID = "other-uid"
+	credential.CreationTimestamp = v1.NewTime(mockNow.Add(age))
+	return credential
+}
+
+func newMockSMTPDetails() *smtpdetails.SMTPDetails {
+	return &smtpdetails.SMTPDetails{ID: mockClusterID, Host: "smtp.test.com", Port: 587, TLS: true, Username: "apikey", Password: "test"}
+}
+
+//newMockSMTPDetailsClient Mock client where the smtp details of the cluster exist if exists is set or once created
+func newMockSMTPDetailsClient(exists bool) *smtpdetails.ClientMock {
+	var mu sync.Mutex
+	return &smtpdetails.ClientMock{
+		GetFunc: func(id string) (*smtpdetails.SMTPDetails, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !exists {
+				return nil, &smtpdetails.NotExistError{Message: "not found"}
+			}
+			return newMockSMTPDetails(), nil
+		},
+		CreateFunc: func(id string) (*smtpdetails.SMTPDetails, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			exists = true
+			return newMockSMTPDetails(), nil
+		},
+		RefreshFunc: func(id string) (*smtpdetails.SMTPDetails, error) {
+			return newMockSMTPDetails(), nil
+		},
+		DeleteFunc: func(id string) error {
+			return nil
+		},
+	}
+}
+
+//mockAPIServer Fake Kubernetes API server storing a single SMTPCredential and secrets
+type mockAPIServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	credential  *SMTPCredential
+	others      []SMTPCredential
+	failList    bool
+	secrets     map[string]*apiv1.Secret
+	watchEvents []string
+}
+
+func newMockAPIServer(credential *SMTPCredential) *mockAPIServer {
+	s := &mockAPIServer{credential: credential, secrets: map[string]*apiv1.Secret{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *mockAPIServer) writeStatus(w http.ResponseWriter, code int, message string) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(&v1.Status{Status: v1.StatusFailure, Code: int32(code), Message: message})
+}
+
+func (s *mockAPIServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := ioutil.ReadAll(r.Body)
+	credentialRoute := fmt.Sprintf(APIRouteCredential, mockNamespace, mockName)
+	switch {
+	case strings.HasPrefix(r.URL.Path, fmt.Sprintf("/api/v1/namespaces/%s/secrets/", mockNamespace)):
+		name := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/v1/namespaces/%s/secrets/", mockNamespace))
+		if r.Method == http.MethodPatch {
+			secret := &apiv1.Secret{}
+			json.Unmarshal(body, secret)
+			s.secrets[name] = secret
+		}
+		secret, ok := s.secrets[name]
+		if !ok {
+			s.writeStatus(w, http.StatusNotFound, "not found")
+			return
+		}
+		json.NewEncoder(w).Encode(secret)
+	case r.URL.Path == APIRouteCredentials && r.URL.Query().Get("watch") == "true":
+		for _, eventType := range s.watchEvents {
+			json.NewEncoder(w).Encode(map[string]interface{}{"type": eventType, "object": s.credential})
+		}
+	case r.URL.Path == APIRouteCredentials && s.failList:
+		s.writeStatus(w, http.StatusForbidden, "forbidden")
+	case r.URL.Path == APIRouteCredentials:
+		list := &SMTPCredentialList{Items: append([]SMTPCredential{*s.credential}, s.others...)}
+		list.ResourceVersion = s.credential.ResourceVersion
+		json.NewEncoder(w).Encode(list)
+	case r.URL.Path == credentialRoute && r.Method == http.MethodPatch:
+		patch := &SMTPCredential{}
+		json.Unmarshal(body, patch)
+		if patch.ResourceVersion != s.credential.ResourceVersion {
+			s.writeStatus(w, http.StatusConflict, "the object has been modified")
+			return
+		}
+		s.credential.Finalizers = patch.Finalizers
+		s.bumpResourceVersion()
+		json.NewEncoder(w).Encode(s.credential)
+	case r.URL.Path == credentialRoute+"/status" && r.Method == http.MethodPatch:
+		patch := &SMTPCredential{}
+		json.Unmarshal(body, patch)
+		s.credential.Status = patch.Status
+		s.bumpResourceVersion()
+		json.NewEncoder(w).Encode(s.credential)
+	default:
+		s.writeStatus(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *mockAPIServer) bumpResourceVersion() {
+	rv, _ := strconv.Atoi(s.credential.ResourceVersion)
+	s.credential.ResourceVersion = strconv.Itoa(rv + 1)
+}
+
+func newMockReconciler(t *testing.T, server *mockAPIServer, client smtpdetails.Client) *Reconciler {
+	kubeClient, err := kubernetes.NewClient(&kubernetes.Config{Host: server.URL, Namespace: mockNamespace}, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create kubernetes client: %v", err)
+	}
+	r, err := NewReconciler(kubeClient, func(credential *SMTPCredential) (smtpdetails.Client, error) {
+		if credential.Spec.Provider != "" && credential.Spec.Provider != "sendgrid" {
+			return nil, fmt.Errorf("unsupported provider %s", credential.Spec.Provider)
+		}
+		return client, nil
+	}, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create reconciler: %v", err)
+	}
+	r.now = func() time.Time {
+		return mockNow
+	}
+	return r
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	tests := []struct {
+		name         string
+		modifyFn     func(credential *SMTPCredential)
+		secretExists bool
+		others       []SMTPCredential
+		failList     bool
+		client       *smtpdetails.ClientMock
+		expectErr    bool
+		validate     func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock)
+	}{
+		{
+			name:   "should create smtp details and secret for new credential",
+			client: newMockSMTPDetailsClient(false),
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.CreateCalls()) != 1 || len(client.RefreshCalls()) != 0 {
+					t.Fatalf("expected create to be called once, create=%d refresh=%d", len(client.CreateCalls()), len(client.RefreshCalls()))
+				}
+				if !server.credential.HasFinalizer() {
+					t.Fatal("expected finalizer to be added")
+				}
+				secret, ok := server.secrets[mockSecretName]
+				if !ok || string(secret.Data[smtpdetails.SecretKeyPassword]) != "test" {
+					t.Fatalf("expected secret to be applied, got %v", secret)
+				}
+				if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "test-uid" {
+					t.Fatalf("expected owner reference on secret, got %v", secret.OwnerReferences)
+				}
+				assertReady(t, server.credential, v1.ConditionTrue, ReasonReconciled)
+				if server.credential.Status.LastRotationTime == nil || !server.credential.Status.LastRotationTime.Equal(&v1.Time{Time: mockNow}) {
+					t.Fatalf("expected last rotation time to be set, got %v", server.credential.Status.LastRotationTime)
+				}
+			},
+		},
+		{
+			name:         "should not modify credential that is up to date",
+			secretExists: true,
+			client:       newMockSMTPDetailsClient(true),
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Finalizers = []string{Finalizer}
+				credential.Spec.Rotation = &RotationPolicy{Interval: "720h"}
+				credential.Status.SecretName = mockSecretName
+				credential.Status.LastRotationTime = &v1.Time{Time: mockNow.Add(-time.Hour)}
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.CreateCalls()) != 0 || len(client.RefreshCalls()) != 0 {
+					t.Fatal("expected no smtp details to be created")
+				}
+				assertReady(t, server.credential, v1.ConditionTrue, ReasonReconciled)
+			},
+		},
+		{
+			name:         "should rotate smtp details when due",
+			secretExists: true,
+			client:       newMockSMTPDetailsClient(true),
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Finalizers = []string{Finalizer}
+				credential.Spec.Rotation = &RotationPolicy{Interval: "720h"}
+				credential.Status.SecretName = mockSecretName
+				credential.Status.LastRotationTime = &v1.Time{Time: mockNow.Add(-721 * time.Hour)}
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.RefreshCalls()) != 1 {
+					t.Fatal("expected smtp details to be refreshed")
+				}
+				if !server.credential.Status.LastRotationTime.Equal(&v1.Time{Time: mockNow}) {
+					t.Fatalf("expected last rotation time to be updated, got %v", server.credential.Status.LastRotationTime)
+				}
+			},
+		},
+		{
+			name:   "should refresh existing smtp details when secret is missing",
+			client: newMockSMTPDetailsClient(true),
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.RefreshCalls()) != 1 || len(client.CreateCalls()) != 0 {
+					t.Fatal("expected smtp details to be refreshed")
+				}
+				if _, ok := server.secrets[mockSecretName]; !ok {
+					t.Fatal("expected secret to be applied")
+				}
+			},
+		},
+		{
+			name:   "should report invalid spec",
+			client: newMockSMTPDetailsClient(false),
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Spec.ClusterID = ""
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.GetCalls()) != 0 {
+					t.Fatal("expected provider not to be called")
+				}
+				assertReady(t, server.credential, v1.ConditionFalse, ReasonInvalidSpec)
+			},
+		},
+		{
+			name:   "should report unsupported provider",
+			client: newMockSMTPDetailsClient(false),
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Spec.Provider = "mailgun"
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				assertReady(t, server.credential, v1.ConditionFalse, ReasonInvalidSpec)
+			},
+		},
+		{
+			name: "should report provider errors",
+			client: func() *smtpdetails.ClientMock {
+				c := newMockSMTPDetailsClient(false)
+				c.CreateFunc = func(id string) (*smtpdetails.SMTPDetails, error) {
+					return nil, fmt.Errorf("sendgrid unavailable")
+				}
+				return c
+			}(),
+			expectErr: true,
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				assertReady(t, server.credential, v1.ConditionFalse, ReasonProviderError)
+			},
+		},
+		{
+			name:   "should delete smtp details and remove finalizer on deletion",
+			client: newMockSMTPDetailsClient(true),
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Finalizers = []string{"other", Finalizer}
+				credential.DeletionTimestamp = &v1.Time{Time: mockNow}
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.DeleteCalls()) != 1 || client.DeleteCalls()[0].ID != mockClusterID {
+					t.Fatal("expected smtp details to be deleted")
+				}
+				if server.credential.HasFinalizer() || len(server.credential.Finalizers) != 1 {
+					t.Fatalf("expected only the finalizer of the operator to be removed, got %v", server.credential.Finalizers)
+				}
+			},
+		},
+		{
+			name: "should remove finalizer when smtp details do not exist on deletion",
+			client: func() *smtpdetails.ClientMock {
+				c := newMockSMTPDetailsClient(false)
+				c.DeleteFunc = func(id string) error {
+					return &smtpdetails.NotExistError{Message: "not found"}
+				}
+				return c
+			}(),
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Finalizers = []string{Finalizer}
+				credential.DeletionTimestamp = &v1.Time{Time: mockNow}
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if server.credential.HasFinalizer() {
+					t.Fatal("expected finalizer to be removed")
+				}
+			},
+		},
+		{
+			name:   "should report cluster id conflict with an older credential",
+			client: newMockSMTPDetailsClient(true),
+			others: []SMTPCredential{*newMockConflictingCredential(-time.Hour)},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.GetCalls()) != 0 || len(client.RefreshCalls()) != 0 {
+					t.Fatal("expected provider not to be called")
+				}
+				if _, ok := server.secrets[mockSecretName]; ok {
+					t.Fatal("expected no secret to be applied")
+				}
+				assertReady(t, server.credential, v1.ConditionFalse, ReasonClusterIDConflict)
+			},
+		},
+		{
+			name:      "should report api error when credentials cannot be listed",
+			client:    newMockSMTPDetailsClient(true),
+			failList:  true,
+			expectErr: true,
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.GetCalls()) != 0 {
+					t.Fatal("expected provider not to be called")
+				}
+				assertReady(t, server.credential, v1.ConditionFalse, ReasonAPIError)
+			},
+		},
+		{
+			name:   "should reconcile credential owning the cluster id despite a newer conflicting credential",
+			client: newMockSMTPDetailsClient(false),
+			others: []SMTPCredential{*newMockConflictingCredential(time.Hour)},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.CreateCalls()) != 1 {
+					t.Fatal("expected smtp details to be created")
+				}
+				assertReady(t, server.credential, v1.ConditionTrue, ReasonReconciled)
+			},
+		},
+		{
+			name:   "should keep smtp details owned by an older credential on deletion",
+			client: newMockSMTPDetailsClient(true),
+			others: []SMTPCredential{*newMockConflictingCredential(-time.Hour)},
+			modifyFn: func(credential *SMTPCredential) {
+				credential.Finalizers = []string{Finalizer}
+				credential.DeletionTimestamp = &v1.Time{Time: mockNow}
+			},
+			validate: func(t *testing.T, server *mockAPIServer, client *smtpdetails.ClientMock) {
+				if len(client.DeleteCalls()) != 0 {
+					t.Fatal("expected smtp details of the owner not to be deleted")
+				}
+				if server.credential.HasFinalizer() {
+					t.Fatal("expected finalizer to be removed")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential := newMockCredential()
+			if tt.modifyFn != nil {
+				tt.modifyFn(credential)
+			}
+			stored := *credential
+			server := newMockAPIServer(&stored)
+			defer server.Close()
+			server.others = tt.others
+			server.failList = tt.failList
+			if tt.secretExists {
+				server.secrets[mockSecretName] = &apiv1.Secret{ObjectMeta: v1.ObjectMeta{Name: mockSecretName}}
+			}
+			err := newMockReconciler(t, server, tt.client).Reconcile(credential)
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, server, tt.client)
+			}
+		})
+	}
+}
+
+func TestReconciler_Reconcile_DeletedAPIKey(t *testing.T) {
+	// the sub user exists without an api key, as after the key was deleted in sendgrid
+	apiClient := &sendgrid.APIClientMock{
+		GetSubUserByUsernameFunc: func(username string) (*sendgrid.SubUser, error) {
+			return &sendgrid.SubUser{Username: username}, nil
+		},
+		GetAPIKeysForSubUserFunc: func(username string) ([]*sendgrid.APIKey, error) {
+			return []*sendgrid.APIKey{}, nil
+		},
+		CreateAPIKeyForSubUserFunc: func(username string, scopes []string) (*sendgrid.APIKey, error) {
+			return &sendgrid.APIKey{Name: username, Key: "new-key"}, nil
+		},
+	}
+	client, err := sendgrid.NewClient(apiClient, sendgrid.DefaultAPIKeyScopes, &smtpdetails.PasswordGeneratorMock{}, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create sendgrid client: %v", err)
+	}
+	credential := newMockCredential()
+	credential.Finalizers = []string{Finalizer}
+	credential.Status.SecretName = mockSecretName
+	credential.Status.LastRotationTime = &v1.Time{Time: mockNow.Add(-time.Hour)}
+	stored := *credential
+	server := newMockAPIServer(&stored)
+	defer server.Close()
+	server.secrets[mockSecretName] = &apiv1.Secret{ObjectMeta: v1.ObjectMeta{Name: mockSecretName}}
+	if err := newMockReconciler(t, server, client).Reconcile(credential); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(apiClient.CreateAPIKeyForSubUserCalls()) != 1 {
+		t.Fatal("expected a new api key to be created")
+	}
+	if secret := server.secrets[mockSecretName]; string(secret.Data[smtpdetails.SecretKeyPassword]) != "new-key" {
+		t.Fatalf("expected secret with the new api key, got %v", secret)
+	}
+	assertReady(t, server.credential, v1.ConditionTrue, ReasonReconciled)
+}
+
+func assertReady(t *testing.T, credential *SMTPCredential, status v1.ConditionStatus, reason string) {
+	condition := credential.Status.GetCondition(ConditionReady)
+	if condition == nil || condition.Status != status || condition.Reason != reason {
+		t.Fatalf("expected ready condition %s with reason %s, got %v", status, reason, condition)
+	}
+}
@@ -0,0 +1,36 @@
+package operator
+
+const (
+	//LogFieldCredential Logging key for specifying the namespace/name of an SMTPCredential
+	LogFieldCredential = "smtp_service_credential"
+	//Group API group of the SMTPCredential custom resource
+	Group = "smtp.integr8ly.org"
+	//Version API version of the SMTPCredential custom resource
+	Version = "v1alpha1"
+	//Kind Kind of the SMTPCredential custom resource
+	Kind = "SMTPCredential"
+	//Finalizer Finalizer ensuring the SMTP details of a deleted SMTPCredential are deleted from the provider
+	Finalizer = "smtp.integr8ly.org/credentials"
+	//APIRouteCredentials API route listing SMTPCredentials in all namespaces
+	APIRouteCredentials = "/apis/smtp.integr8ly.org/v1alpha1/smtpcredentials"
+	//APIRouteNamespacedCredentials API route listing SMTPCredentials in a namespace
+	APIRouteNamespacedCredentials = "/apis/smtp.integr8ly.org/v1alpha1/namespaces/%s/smtpcredentials"
+	//APIRouteCredential API route of an SMTPCredential
+	APIRouteCredential = "/apis/smtp.integr8ly.org/v1alpha1/namespaces/%s/smtpcredentials/%s"
+	//APIRouteCredentialStatus API route of the status subresource of an SMTPCredential
+	APIRouteCredentialStatus = "/apis/smtp.integr8ly.org/v1alpha1/namespaces/%s/smtpcredentials/%s/status"
+	//ConditionReady Condition type reporting whether the secret of an SMTPCredential is up to date
+	ConditionReady = "Ready"
+	//ReasonReconciled Condition reason for a successfully reconciled SMTPCredential
+	ReasonReconciled = "Reconciled"
+	//ReasonInvalidSpec Condition reason for an SMTPCredential with an invalid spec
+	ReasonInvalidSpec = "InvalidSpec"
+	//ReasonProviderError Condition reason for a failure to create or rotate SMTP details
+	ReasonProviderError = "ProviderError"
+	//ReasonSecretError Condition reason for a failure to write the secret
+	ReasonSecretError = "SecretError"
+	//ReasonAPIError Condition reason for a failure to read SMTPCredentials from the Kubernetes API
+	ReasonAPIError = "APIError"
+	//ReasonClusterIDConflict Condition reason for an SMTPCredential whose cluster id is used by an older SMTPCredential
+	ReasonClusterIDConflict = "ClusterIDConflict"
+)
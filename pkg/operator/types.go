@@ -0,0 +1,117 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//SMTPCredential Custom resource requesting SMTP details for a cluster, written to a secret in its namespace
+type SMTPCredential struct {
+	v1.TypeMeta   `json:",inline"`
+	v1.ObjectMeta `json:"metadata,omitempty"`
+	Spec          SMTPCredentialSpec   `json:"spec"`
+	Status        SMTPCredentialStatus `json:"status,omitempty"`
+}
+
+//SMTPCredentialList List of SMTPCredentials
+type SMTPCredentialList struct {
+	v1.TypeMeta `json:",inline"`
+	v1.ListMeta `json:"metadata,omitempty"`
+	Items       []SMTPCredential `json:"items"`
+}
+
+//SMTPCredentialSpec Desired SMTP details of an SMTPCredential
+type SMTPCredentialSpec struct {
+	ClusterID  string          `json:"clusterId"`
+	Provider   string          `json:"provider,omitempty"`
+	Scopes     []string        `json:"scopes,omitempty"`
+	SecretName string          `json:"secretName"`
+	Rotation   *RotationPolicy `json:"rotation,omitempty"`
+}
+
+//RotationPolicy How often the SMTP details of an SMTPCredential are rotated
+type RotationPolicy struct {
+	//Interval Go duration between rotations e.g. 720h, rotation is disabled if blank
+	Interval string `json:"interval,omitempty"`
+}
+
+//SMTPCredentialStatus Observed state of an SMTPCredential
+type SMTPCredentialStatus struct {
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	SecretName         string      `json:"secretName,omitempty"`
+	LastRotationTime   *v1.Time    `json:"lastRotationTime,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+//Condition State of an aspect of an SMTPCredential
+type Condition struct {
+	Type               string             `json:"type"`
+	Status             v1.ConditionStatus `json:"status"`
+	Reason             string             `json:"reason,omitempty"`
+	Message            string             `json:"message,omitempty"`
+	LastTransitionTime v1.Time            `json:"lastTransitionTime"`
+}
+
+//RotationInterval Parse the rotation interval, zero if rotation is disabled
+func (s *SMTPCredentialSpec) RotationInterval() (time.Duration, error) {
+	if s.Rotation == nil || s.Rotation.Interval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(s.Rotation.Interval)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid rotation interval %s", s.Rotation.Interval)
+	}
+	if interval < 0 {
+		return 0, errors.New("rotation interval must not be negative")
+	}
+	return interval, nil
+}
+
+//HasFinalizer Whether the SMTPCredential has the finalizer of the operator
+func (c *SMTPCredential) HasFinalizer() bool {
+	for _, f := range c.Finalizers {
+		if f == Finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+//DeepCopy Copy the status, so it can be modified without modifying the SMTPCredential
+func (s *SMTPCredentialStatus) DeepCopy() *SMTPCredentialStatus {
+	out := *s
+	if s.LastRotationTime != nil {
+		lastRotationTime := *s.LastRotationTime
+		out.LastRotationTime = &lastRotationTime
+	}
+	out.Conditions = append([]Condition(nil), s.Conditions...)
+	return &out
+}
+
+//SetCondition Set a condition, updating the transition time only if the status changed
+func (s *SMTPCredentialStatus) SetCondition(condition Condition, now time.Time) {
+	condition.LastTransitionTime = v1.NewTime(now)
+	for i, existing := range s.Conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		s.Conditions[i] = condition
+		return
+	}
+	s.Conditions = append(s.Conditions, condition)
+}
+
+//GetCondition Retrieve a condition by type, nil if it is not set
+func (s *SMTPCredentialStatus) GetCondition(conditionType string) *Condition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package prune
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+//Action Outcome of pruning an orphan
+type Action string
+
+//Config Filters and confirmation of a prune
+type Config struct {
+	//Pattern Only prune orphans with a cluster id matching the glob pattern, all orphans if blank
+	Pattern string
+	//MinAge Only prune orphans tracked in the store for at least MinAge, orphans with an unknown age are kept
+	MinAge time.Duration
+	//Confirm Delete the orphans, otherwise they are only reported
+	Confirm bool
+	//Scoped The clusters of the provider are scoped by an id prefix, so they were all created for clusters
+	Scoped bool
+	//AllSubUsers Acknowledge that every unscoped cluster of the provider, including ones not created for clusters, may
+	//be deleted, required to confirm a prune that is not scoped
+	AllSubUsers bool
+}
+
+//Orphan A cluster of the provider missing from the known clusters
+type Orphan struct {
+	ClusterID string     `json:"clusterId"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	Action    Action     `json:"action"`
+	Reason    string     `json:"reason,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+//Report Result of a prune
+type Report struct {
+	Confirmed bool      `json:"confirmed"`
+	Known     int       `json:"known"`
+	Checked   int       `json:"checked"`
+	Deleted   int       `json:"deleted"`
+	Failed    int       `json:"failed"`
+	Orphans   []*Orphan `json:"orphans"`
+}
+
+//Pruner Delete the clusters of a provider that are missing from an authoritative list of known clusters
+type Pruner struct {
+	client    smtpdetails.Client
+	inventory store.Inventory
+	store     store.Store
+	logger    *logrus.Entry
+	now       func() time.Time
+}
+
+//NewPruner Create a Pruner, the store is optional and provides the age of orphans
+func NewPruner(client smtpdetails.Client, inventory store.Inventory, store store.Store, logger *logrus.Entry) (*Pruner, error) {
+	if client == nil {
+		return nil, errors.New("client must be defined")
+	}
+	if inventory == nil {
+		return nil, errors.New("inventory must be defined")
+	}
+	return &Pruner{
+		client:    client,
+		inventory: inventory,
+		store:     store,
+		logger:    logger,
+		now:       time.Now,
+	}, nil
+}
+
+//Prune Report the clusters of the provider missing from known, deleting them if the config is confirmed
+func (p *Pruner) Prune(known []string, config *Config) (*Report, error) {
+	if len(known) == 0 {
+		return nil, errors.New("known clusters must not be empty, refusing to treat every cluster as an orphan")
+	}
+	if config.Confirm && !config.Scoped && !config.AllSubUsers {
+		return nil, &smtpdetails.ValidationError{Message: "clusters are not scoped by an id prefix, so every cluster missing from the known clusters is an orphan, refusing to delete them unless all sub users are acknowledged"}
+	}
+	if config.Pattern != "" {
+		if _, err := path.Match(config.Pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %s", config.Pattern)
+		}
+	}
+	ids, err := p.inventory.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	knownIDs := map[string]bool{}
+	for _, id := range known {
+		knownIDs[id] = true
+	}
+	records := map[string]*store.Record{}
+	if p.store != nil {
+		list, err := p.store.List()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list records")
+		}
+		for _, record := range list {
+			records[record.ClusterID] = record
+		}
+	}
+	report := &Report{Confirmed: config.Confirm, Known: len(knownIDs), Checked: len(ids), Orphans: []*Orphan{}}
+	now := p.now()
+	for _, id := range ids {
+		if knownIDs[id] {
+			continue
+		}
+		orphan := &Orphan{ClusterID: id}
+		report.Orphans = append(report.Orphans, orphan)
+		if record, ok := records[id]; ok {
+			createdAt := record.CreatedAt
+			orphan.CreatedAt = &createdAt
+		}
+		if reason := excludeReason(orphan, config, now); reason != "" {
+			orphan.Action = ActionKept
+			orphan.Reason = reason
+			continue
+		}
+		if !config.Confirm {
+			orphan.Action = ActionWouldDelete
+			continue
+		}
+		p.logger.Infof("deleting orphan cluster %s", id)
+		if err := p.client.Delete(id); err != nil && !smtpdetails.IsNotExistError(err) {
+			orphan.Action = ActionFailed
+			orphan.Error = err.Error()
+			report.Failed++
+			continue
+		}
+		orphan.Action = ActionDeleted
+		report.Deleted++
+		if p.store != nil {
+			if err := p.store.Delete(id); err != nil && !store.IsNotExistError(err) {
+				p.logger.Warnf("failed to delete record of orphan cluster %s: %v", id, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+//excludeReason Reason the filters of config exclude an orphan from being pruned, blank if it is not excluded
+func excludeReason(orphan *Orphan, config *Config, now time.Time) string {
+	if config.Pattern != "" {
+		if matched, _ := path.Match(config.Pattern, orphan.ClusterID); !matched {
+			return fmt.Sprintf("does not match pattern %s", config.Pattern)
+		}
+	}
+	if config.MinAge > 0 {
+		if orphan.CreatedAt == nil {
+			return "age is unknown, cluster is not tracked in the state file"
+		}
+		if now.Sub(*orphan.CreatedAt) < config.MinAge {
+			return fmt.Sprintf("younger than %s", config.MinAge)
+		}
+	}
+	return ""
+}
+
+//ParseKnownClusters Read cluster ids, one per line, ignoring blank lines and lines starting with #
+func ParseKnownClusters(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read known clusters")
+	}
+	return ids, nil
+}
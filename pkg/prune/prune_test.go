@@ -0,0 +1,204 @@
+package prune
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var mockNow = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newMockLogger() *logrus.Entry {
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+type mockInventory []string
+
+func (i mockInventory) List() ([]string, error) {
+	if i == nil {
+		return nil, errors.New("test")
+	}
+	return i, nil
+}
+
+func (i mockInventory) Describe(clusterID string) (*store.Record, error) {
+	return &store.Record{ClusterID: clusterID}, nil
+}
+
+func newMockStore(t *testing.T, records ...*store.Record) (store.Store, func()) {
+	dir, err := ioutil.TempDir("", "prune")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	s := store.NewFileStore(filepath.Join(dir, "state.json"))
+	for _, r := range records {
+		if err := s.Put(r); err != nil {
+			t.Fatalf("failed to put record: %v", err)
+		}
+	}
+	return s, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPruner_Prune(t *testing.T) {
+	records := []*store.Record{
+		{ClusterID: "old-orphan", CreatedAt: mockNow.Add(-48 * time.Hour)},
+		{ClusterID: "new-orphan", CreatedAt: mockNow.Add(-time.Hour)},
+		{ClusterID: "live", CreatedAt: mockNow.Add(-48 * time.Hour)},
+	}
+	inventory := mockInventory{"live", "old-orphan", "new-orphan", "manual"}
+	tests := []struct {
+		name        string
+		inventory   store.Inventory
+		known       []string
+		config      *Config
+		deleteErr   error
+		want        map[string]Action
+		wantDeleted []string
+		wantErr     bool
+	}{
+		{
+			name:      "should report orphans without confirmation",
+			inventory: inventory,
+			known:     []string{"live"},
+			config:    &Config{},
+			want:      map[string]Action{"old-orphan": ActionWouldDelete, "new-orphan": ActionWouldDelete, "manual": ActionWouldDelete},
+		},
+		{
+			name:        "should delete orphans with confirmation",
+			inventory:   inventory,
+			known:       []string{"live", "manual"},
+			config:      &Config{Confirm: true, Scoped: true},
+			want:        map[string]Action{"old-orphan": ActionDeleted, "new-orphan": ActionDeleted},
+			wantDeleted: []string{"old-orphan", "new-orphan"},
+		},
+		{
+			name:        "should keep orphans not matching pattern",
+			inventory:   inventory,
+			known:       []string{"live"},
+			config:      &Config{Pattern: "*-orphan", Confirm: true, Scoped: true},
+			want:        map[string]Action{"old-orphan": ActionDeleted, "new-orphan": ActionDeleted, "manual": ActionKept},
+			wantDeleted: []string{"old-orphan", "new-orphan"},
+		},
+		{
+			name:        "should keep orphans younger than min age or with unknown age",
+			inventory:   inventory,
+			known:       []string{"live"},
+			config:      &Config{MinAge: 24 * time.Hour, Confirm: true, Scoped: true},
+			want:        map[string]Action{"old-orphan": ActionDeleted, "new-orphan": ActionKept, "manual": ActionKept},
+			wantDeleted: []string{"old-orphan"},
+		},
+		{
+			name:      "should report failed deletes",
+			inventory: inventory,
+			known:     []string{"live", "manual", "new-orphan"},
+			config:    &Config{Confirm: true, Scoped: true},
+			deleteErr: errors.New("test"),
+			want:      map[string]Action{"old-orphan": ActionFailed},
+		},
+		{
+			name:      "should refuse to delete unscoped orphans without acknowledgement",
+			inventory: inventory,
+			known:     []string{"live"},
+			config:    &Config{Confirm: true},
+			wantErr:   true,
+		},
+		{
+			name:        "should delete unscoped orphans with acknowledgement",
+			inventory:   inventory,
+			known:       []string{"live", "manual"},
+			config:      &Config{Confirm: true, AllSubUsers: true},
+			want:        map[string]Action{"old-orphan": ActionDeleted, "new-orphan": ActionDeleted},
+			wantDeleted: []string{"old-orphan", "new-orphan"},
+		},
+		{
+			name:      "should refuse empty known clusters",
+			inventory: inventory,
+			config:    &Config{Confirm: true, Scoped: true},
+			wantErr:   true,
+		},
+		{
+			name:      "should fail on invalid pattern",
+			inventory: inventory,
+			known:     []string{"live"},
+			config:    &Config{Pattern: "["},
+			wantErr:   true,
+		},
+		{
+			name:      "should fail when listing clusters fails",
+			inventory: mockInventory(nil),
+			known:     []string{"live"},
+			config:    &Config{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cleanup := newMockStore(t, records...)
+			defer cleanup()
+			var deleted []string
+			client := &smtpdetails.ClientMock{
+				DeleteFunc: func(id string) error {
+					if tt.deleteErr != nil {
+						return tt.deleteErr
+					}
+					deleted = append(deleted, id)
+					return nil
+				},
+			}
+			p, err := NewPruner(client, tt.inventory, s, newMockLogger())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p.now = func() time.Time { return mockNow }
+			report, err := p.Prune(tt.known, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Prune() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(client.DeleteCalls()) != 0 {
+					t.Fatal("expected no deletes on error")
+				}
+				return
+			}
+			if len(report.Orphans) != len(tt.want) {
+				t.Fatalf("Prune() got %d orphans, want %d", len(report.Orphans), len(tt.want))
+			}
+			for _, orphan := range report.Orphans {
+				if orphan.Action != tt.want[orphan.ClusterID] {
+					t.Fatalf("orphan %s got action %s, want %s", orphan.ClusterID, orphan.Action, tt.want[orphan.ClusterID])
+				}
+			}
+			if strings.Join(deleted, ",") != strings.Join(tt.wantDeleted, ",") {
+				t.Fatalf("deleted %v, want %v", deleted, tt.wantDeleted)
+			}
+			if report.Deleted != len(tt.wantDeleted) {
+				t.Fatalf("report counted %d deletes, want %d", report.Deleted, len(tt.wantDeleted))
+			}
+			for _, id := range tt.wantDeleted {
+				if _, err := s.Get(id); !store.IsNotExistError(err) {
+					t.Fatalf("expected record of deleted orphan %s to be removed, got %v", id, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseKnownClusters(t *testing.T) {
+	got, err := ParseKnownClusters(strings.NewReader("# live clusters\ncluster-a\n\n  cluster-b  \n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got, ",") != "cluster-a,cluster-b" {
+		t.Fatalf("ParseKnownClusters() got = %v", got)
+	}
+}
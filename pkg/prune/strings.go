@@ -0,0 +1,12 @@
+package prune
+
+const (
+	//ActionKept The orphan was excluded by the pattern or age filters
+	ActionKept Action = "kept"
+	//ActionWouldDelete The orphan would be deleted, without confirmation
+	ActionWouldDelete Action = "would-delete"
+	//ActionDeleted The orphan was deleted
+	ActionDeleted Action = "deleted"
+	//ActionFailed Deleting the orphan failed
+	ActionFailed Action = "failed"
+)
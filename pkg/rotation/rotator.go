@@ -0,0 +1,222 @@
+package rotation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+//Action Outcome of a rotation run for a cluster
+type Action string
+
+//Config Configuration of a Rotator
+type Config struct {
+	//MaxAge Age after which the API key of a cluster is rotated
+	MaxAge time.Duration
+	//Concurrency Number of clusters rotated in parallel
+	Concurrency int
+	//DryRun Report the clusters that would be rotated without rotating them or updating the store
+	DryRun bool
+	//RotateUnknown Rotate clusters without a record in the store, instead of tracking them from the time they are first seen
+	RotateUnknown bool
+	//Fallback Sink the secret is emitted to when emitting it to the sink fails, the previous API key has already been
+	//deleted at that point so the new one would otherwise be lost
+	Fallback Sink
+}
+
+//Result Outcome of a rotation run for a cluster
+type Result struct {
+	ClusterID   string    `json:"clusterId"`
+	Action      Action    `json:"action"`
+	KeyIssuedAt time.Time `json:"keyIssuedAt,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+//Rotator Rotate the API keys of clusters older than a max age, emitting the new secrets to a sink.
+//
+//Providers such as SendGrid do not expose the creation time of API keys, so the time a key was issued is tracked in a
+//store. Clusters without a record are tracked from the time they are first seen, unless RotateUnknown is set.
+type Rotator struct {
+	client    smtpdetails.Client
+	inventory store.Inventory
+	store     store.Store
+	sink      Sink
+	config    *Config
+	logger    *logrus.Entry
+	now       func() time.Time
+}
+
+//NewRotator Create a Rotator, if inventory is nil only the clusters tracked in the store are rotated and the provider
+//details of their records are not updated
+func NewRotator(client smtpdetails.Client, inventory store.Inventory, store store.Store, sink Sink, config *Config, logger *logrus.Entry) (*Rotator, error) {
+	if client == nil {
+		return nil, errors.New("client must be defined")
+	}
+	if store == nil {
+		return nil, errors.New("store must be defined")
+	}
+	if sink == nil {
+		return nil, errors.New("sink must be defined")
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultMaxAge
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	return &Rotator{
+		client:    client,
+		inventory: inventory,
+		store:     store,
+		sink:      sink,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}, nil
+}
+
+//Run Rotate API keys every interval until stop is closed
+func (r *Rotator) Run(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		results, err := r.RunOnce()
+		if err != nil {
+			r.logger.Errorf("rotation run failed: %v", err)
+		}
+		for _, result := range results {
+			if result.Action == ActionFailed {
+				r.logger.Errorf("failed to rotate api key of cluster %s: %s", result.ClusterID, result.Error)
+				continue
+			}
+			r.logger.Infof("cluster %s: %s", result.ClusterID, result.Action)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//RunOnce Rotate the API keys of all clusters older than the max age, returning the result of each cluster
+func (r *Rotator) RunOnce() ([]*Result, error) {
+	records, err := r.store.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list records")
+	}
+	recordsByID := map[string]*store.Record{}
+	for _, record := range records {
+		recordsByID[record.ClusterID] = record
+	}
+	var ids []string
+	if r.inventory == nil {
+		for _, record := range records {
+			ids = append(ids, record.ClusterID)
+		}
+	} else {
+		if ids, err = r.inventory.List(); err != nil {
+			return nil, errors.Wrap(err, "failed to list clusters")
+		}
+	}
+	results := make([]*Result, len(ids))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < r.config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = r.rotate(ids[i], recordsByID[ids[i]])
+			}
+		}()
+	}
+	for i := range ids {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results, nil
+}
+
+//rotate Rotate the API key of a cluster if it is older than the max age, record is nil for unknown clusters
+func (r *Rotator) rotate(id string, record *store.Record) *Result {
+	now := r.now()
+	result := &Result{ClusterID: id}
+	if record == nil {
+		if !r.config.RotateUnknown {
+			result.Action = ActionTracked
+			result.KeyIssuedAt = now
+			if !r.config.DryRun {
+				if err := r.store.Put(&store.Record{ClusterID: id, CreatedAt: now}); err != nil {
+					return failedResult(result, errors.Wrap(err, "failed to track cluster"))
+				}
+			}
+			return result
+		}
+		record = &store.Record{ClusterID: id, CreatedAt: now}
+	} else {
+		result.KeyIssuedAt = record.KeyIssuedAt()
+		if now.Sub(result.KeyIssuedAt) < r.config.MaxAge {
+			result.Action = ActionSkipped
+			return result
+		}
+	}
+	if r.config.DryRun {
+		result.Action = ActionWouldRotate
+		return result
+	}
+	r.logger.Debugf("rotating api key of cluster %s", id)
+	details, err := r.client.Refresh(id)
+	if err != nil {
+		return failedResult(result, errors.Wrap(err, "failed to refresh api key"))
+	}
+	// the previous api key has been deleted, leave the record untouched on failure so the next run rotates it again
+	if err := r.sink.Emit(id, details); err != nil {
+		return failedResult(result, r.emitFallback(id, details, errors.Wrap(err, "api key was rotated but the secret could not be emitted")))
+	}
+	if r.inventory != nil {
+		described, err := r.inventory.Describe(id)
+		if err != nil {
+			r.logger.Warnf("failed to describe rotated api key of cluster %s: %v", id, err)
+		} else {
+			record.UpdateFrom(described)
+		}
+	}
+	record.RotatedAt = now
+	if err := r.store.Put(record); err != nil {
+		return failedResult(result, errors.Wrap(err, "api key was rotated but the record could not be updated"))
+	}
+	result.Action = ActionRotated
+	result.KeyIssuedAt = now
+	return result
+}
+
+//emitFallback Emit the secret of a cluster to the fallback sink after the sink failed with err, returning err annotated
+//with where the secret can be recovered from
+func (r *Rotator) emitFallback(id string, details *smtpdetails.SMTPDetails, err error) error {
+	if r.config.Fallback == nil {
+		return err
+	}
+	if fallbackErr := r.config.Fallback.Emit(id, details); fallbackErr != nil {
+		return errors.Wrapf(err, "failed to emit the secret to the fallback sink: %v", fallbackErr)
+	}
+	r.logger.Warnf("secret of rotated api key of cluster %s was emitted to the fallback sink", id)
+	return errors.Wrap(err, "the secret was emitted to the fallback sink")
+}
+
+func failedResult(result *Result, err error) *Result {
+	result.Action = ActionFailed
+	result.Error = err.Error()
+	return result
+}
@@ -0,0 +1,306 @@
+package rotation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var mockNow = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newMockLogger() *logrus.Entry {
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+type mockInventory []string
+
+func (i mockInventory) List() ([]string, error) {
+	if i == nil {
+		return nil, errors.New("test")
+	}
+	return i, nil
+}
+
+func (i mockInventory) Describe(clusterID string) (*store.Record, error) {
+	return &store.Record{ClusterID: clusterID, Provider: "test", KeyID: "new"}, nil
+}
+
+type mockSink struct {
+	mu      sync.Mutex
+	emitted []string
+	err     error
+}
+
+func (s *mockSink) Emit(clusterID string, details *smtpdetails.SMTPDetails) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.emitted = append(s.emitted, clusterID)
+	return nil
+}
+
+func newMockSMTPDetailsClient(refreshErr error) *smtpdetails.ClientMock {
+	return &smtpdetails.ClientMock{
+		RefreshFunc: func(id string) (*smtpdetails.SMTPDetails, error) {
+			if refreshErr != nil {
+				return nil, refreshErr
+			}
+			return &smtpdetails.SMTPDetails{ID: id, Host: "smtp.example.com", Port: 587, Username: "apikey", Password: "new"}, nil
+		},
+	}
+}
+
+func newMockStore(t *testing.T, records ...*store.Record) (store.Store, func()) {
+	dir, err := ioutil.TempDir("", "rotation")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	s := store.NewFileStore(filepath.Join(dir, "state.json"))
+	for _, r := range records {
+		if err := s.Put(r); err != nil {
+			t.Fatalf("failed to put record: %v", err)
+		}
+	}
+	return s, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRotator_RunOnce(t *testing.T) {
+	old := &store.Record{ClusterID: "old", CreatedAt: mockNow.Add(-100 * 24 * time.Hour)}
+	rotated := &store.Record{ClusterID: "rotated", CreatedAt: mockNow.Add(-200 * 24 * time.Hour), RotatedAt: mockNow.Add(-time.Hour)}
+	tests := []struct {
+		name        string
+		records     []*store.Record
+		inventory   store.Inventory
+		config      *Config
+		refreshErr  error
+		sinkErr     error
+		fallback    *mockSink
+		want        map[string]Action
+		wantEmitted []string
+		wantRotated []string
+		wantErr     bool
+	}{
+		{
+			name:        "should rotate keys older than max age",
+			records:     []*store.Record{old, rotated},
+			config:      &Config{},
+			want:        map[string]Action{"old": ActionRotated, "rotated": ActionSkipped},
+			wantEmitted: []string{"old"},
+			wantRotated: []string{"old"},
+		},
+		{
+			name:      "should only report keys to rotate in dry run",
+			records:   []*store.Record{old, rotated},
+			inventory: mockInventory{"old", "rotated", "unknown"},
+			config:    &Config{DryRun: true},
+			want:      map[string]Action{"old": ActionWouldRotate, "rotated": ActionSkipped, "unknown": ActionTracked},
+		},
+		{
+			name:        "should track unknown clusters and ignore records of missing clusters",
+			records:     []*store.Record{old},
+			inventory:   mockInventory{"unknown"},
+			config:      &Config{},
+			want:        map[string]Action{"unknown": ActionTracked},
+			wantEmitted: []string{},
+		},
+		{
+			name:        "should rotate unknown clusters when configured",
+			inventory:   mockInventory{"unknown"},
+			config:      &Config{RotateUnknown: true},
+			want:        map[string]Action{"unknown": ActionRotated},
+			wantEmitted: []string{"unknown"},
+			wantRotated: []string{"unknown"},
+		},
+		{
+			name:        "should honour a custom max age",
+			records:     []*store.Record{rotated},
+			config:      &Config{MaxAge: time.Minute},
+			want:        map[string]Action{"rotated": ActionRotated},
+			wantEmitted: []string{"rotated"},
+			wantRotated: []string{"rotated"},
+		},
+		{
+			name:       "should report failed refresh",
+			records:    []*store.Record{old},
+			config:     &Config{},
+			refreshErr: errors.New("test"),
+			want:       map[string]Action{"old": ActionFailed},
+		},
+		{
+			name:    "should not update record when emitting fails",
+			records: []*store.Record{old},
+			config:  &Config{},
+			sinkErr: errors.New("test"),
+			want:    map[string]Action{"old": ActionFailed},
+		},
+		{
+			name:     "should emit the secret to the fallback sink when emitting fails",
+			records:  []*store.Record{old},
+			config:   &Config{},
+			sinkErr:  errors.New("test"),
+			fallback: &mockSink{},
+			want:     map[string]Action{"old": ActionFailed},
+		},
+		{
+			name:     "should report failed fallback sink",
+			records:  []*store.Record{old},
+			config:   &Config{},
+			sinkErr:  errors.New("test"),
+			fallback: &mockSink{err: errors.New("test")},
+			want:     map[string]Action{"old": ActionFailed},
+		},
+		{
+			name:      "should fail when listing clusters fails",
+			inventory: mockInventory(nil),
+			config:    &Config{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cleanup := newMockStore(t, tt.records...)
+			defer cleanup()
+			sink := &mockSink{err: tt.sinkErr}
+			if tt.fallback != nil {
+				tt.config.Fallback = tt.fallback
+			}
+			r, err := NewRotator(newMockSMTPDetailsClient(tt.refreshErr), tt.inventory, s, sink, tt.config, newMockLogger())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			r.now = func() time.Time { return mockNow }
+			results, err := r.RunOnce()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunOnce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("RunOnce() got %d results, want %d", len(results), len(tt.want))
+			}
+			for _, result := range results {
+				if result.Action != tt.want[result.ClusterID] {
+					t.Fatalf("cluster %s got action %s, want %s, error=%s", result.ClusterID, result.Action, tt.want[result.ClusterID], result.Error)
+				}
+			}
+			sort.Strings(sink.emitted)
+			if len(sink.emitted) != len(tt.wantEmitted) {
+				t.Fatalf("emitted secrets of %v, want %v", sink.emitted, tt.wantEmitted)
+			}
+			if tt.fallback != nil && tt.fallback.err == nil && len(tt.fallback.emitted) != 1 {
+				t.Fatalf("expected the secret to be emitted to the fallback sink, got %v", tt.fallback.emitted)
+			}
+			for _, id := range tt.wantRotated {
+				record, err := s.Get(id)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !record.RotatedAt.Equal(mockNow) {
+					t.Fatalf("record of cluster %s has rotation time %v, want %v", id, record.RotatedAt, mockNow)
+				}
+				if tt.inventory != nil && record.KeyID != "new" {
+					t.Fatalf("expected provider details of cluster %s to be updated, got %+v", id, record)
+				}
+			}
+			for id, action := range tt.want {
+				record, err := s.Get(id)
+				switch {
+				case action == ActionTracked && tt.config.DryRun:
+					if !store.IsNotExistError(err) {
+						t.Fatalf("expected cluster %s to not be tracked in dry run, got %v", id, err)
+					}
+				case action == ActionTracked:
+					if err != nil || !record.CreatedAt.Equal(mockNow) {
+						t.Fatalf("expected cluster %s to be tracked from %v, got %v %v", id, mockNow, record, err)
+					}
+				case action == ActionFailed || action == ActionWouldRotate:
+					if err != nil || !record.RotatedAt.IsZero() {
+						t.Fatalf("expected record of cluster %s to be unchanged, got %v %v", id, record, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewRotator(t *testing.T) {
+	s, cleanup := newMockStore(t)
+	defer cleanup()
+	tests := []struct {
+		name    string
+		client  smtpdetails.Client
+		store   store.Store
+		sink    Sink
+		wantErr bool
+	}{
+		{
+			name:   "should create rotator with default config",
+			client: newMockSMTPDetailsClient(nil),
+			store:  s,
+			sink:   &mockSink{},
+		},
+		{
+			name:    "should fail without client",
+			store:   s,
+			sink:    &mockSink{},
+			wantErr: true,
+		},
+		{
+			name:    "should fail without store",
+			client:  newMockSMTPDetailsClient(nil),
+			sink:    &mockSink{},
+			wantErr: true,
+		},
+		{
+			name:    "should fail without sink",
+			client:  newMockSMTPDetailsClient(nil),
+			store:   s,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRotator(tt.client, nil, tt.store, tt.sink, nil, newMockLogger())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRotator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if r.config.MaxAge != DefaultMaxAge || r.config.Concurrency != DefaultConcurrency {
+				t.Fatalf("expected default config, got %+v", r.config)
+			}
+		})
+	}
+}
+
+func TestRotator_Run(t *testing.T) {
+	s, cleanup := newMockStore(t, &store.Record{ClusterID: "old", CreatedAt: mockNow.Add(-100 * 24 * time.Hour)})
+	defer cleanup()
+	sink := &mockSink{}
+	r, err := NewRotator(newMockSMTPDetailsClient(nil), nil, s, sink, &Config{}, newMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.now = func() time.Time { return mockNow }
+	stop := make(chan struct{})
+	close(stop)
+	r.Run(time.Hour, stop)
+	if len(sink.emitted) != 1 {
+		t.Fatalf("expected a single run to emit 1 secret, got %v", sink.emitted)
+	}
+}
@@ -0,0 +1,181 @@
+package rotation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+//Sink Destination of the secrets of rotated API keys
+type Sink interface {
+	Emit(clusterID string, details *smtpdetails.SMTPDetails) error
+}
+
+//SecretApplier Client able to create or update a secret in a Kubernetes cluster
+type SecretApplier interface {
+	ApplySecret(namespace string, secret *apiv1.Secret, force bool) (*apiv1.Secret, error)
+}
+
+type secretTemplateData struct {
+	ClusterID string
+}
+
+//SecretTemplate Template rendering a per cluster value, such as a secret name, the cluster ID is available as {{.ClusterID}}
+type SecretTemplate struct {
+	tmpl *template.Template
+}
+
+//ParseSecretTemplate Parse a per cluster template
+func ParseSecretTemplate(text string) (*SecretTemplate, error) {
+	tmpl, err := template.New("secret").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse template %s", text)
+	}
+	return &SecretTemplate{tmpl: tmpl}, nil
+}
+
+//Render Render the template for a cluster
+func (t *SecretTemplate) Render(clusterID string) (string, error) {
+	var out bytes.Buffer
+	if err := t.tmpl.Execute(&out, &secretTemplateData{ClusterID: clusterID}); err != nil {
+		return "", errors.Wrapf(err, "failed to render template for cluster %s", clusterID)
+	}
+	return out.String(), nil
+}
+
+//secretConverter Convert the SMTP details of a cluster to a secret named by a template
+type secretConverter struct {
+	name *SecretTemplate
+	opts []smtpdetails.SecretOption
+}
+
+func (c *secretConverter) convert(clusterID string, details *smtpdetails.SMTPDetails) (*apiv1.Secret, error) {
+	name, err := c.name.Render(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return smtpdetails.ConvertSMTPDetailsToSecret(details, name, c.opts...), nil
+}
+
+var _ Sink = &WriterSink{}
+
+//WriterSink Sink writing every secret as JSON to a writer, such as stdout
+type WriterSink struct {
+	secretConverter
+	out io.Writer
+	mu  sync.Mutex
+}
+
+//NewWriterSink Create a WriterSink, the secret name of each cluster is rendered from name
+func NewWriterSink(out io.Writer, name *SecretTemplate, opts ...smtpdetails.SecretOption) *WriterSink {
+	return &WriterSink{secretConverter: secretConverter{name: name, opts: opts}, out: out}
+}
+
+//Emit Write the secret of a cluster
+func (s *WriterSink) Emit(clusterID string, details *smtpdetails.SMTPDetails) error {
+	secret, err := s.convert(clusterID, details)
+	if err != nil {
+		return err
+	}
+	secretJSON, err := json.MarshalIndent(secret, "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal secret of cluster %s", clusterID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintln(s.out, string(secretJSON)); err != nil {
+		return errors.Wrapf(err, "failed to write secret of cluster %s", clusterID)
+	}
+	return nil
+}
+
+var _ Sink = &DirSink{}
+
+//DirSink Sink writing the secret of each cluster to its own JSON file in a directory
+type DirSink struct {
+	secretConverter
+	dir string
+}
+
+//NewDirSink Create a DirSink, the secret of each cluster is written to <dir>/<cluster id>.json, cluster ids containing
+//path separators are rejected
+func NewDirSink(dir string, name *SecretTemplate, opts ...smtpdetails.SecretOption) (*DirSink, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check output directory %s", dir)
+	}
+	if !info.IsDir() {
+		return nil, errors.New(fmt.Sprintf("output path %s is not a directory", dir))
+	}
+	return &DirSink{secretConverter: secretConverter{name: name, opts: opts}, dir: dir}, nil
+}
+
+//Emit Write the secret of a cluster to its file, replacing any previous secret
+func (s *DirSink) Emit(clusterID string, details *smtpdetails.SMTPDetails) error {
+	secret, err := s.convert(clusterID, details)
+	if err != nil {
+		return err
+	}
+	secretJSON, err := json.MarshalIndent(secret, "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal secret of cluster %s", clusterID)
+	}
+	if clusterID == "" || clusterID == "." || clusterID == ".." || strings.ContainsAny(clusterID, `/\`) {
+		return errors.New(fmt.Sprintf("cluster id %s cannot be used as a file name", clusterID))
+	}
+	path := filepath.Join(s.dir, clusterID+".json")
+	if err := ioutil.WriteFile(path, secretJSON, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write secret of cluster %s to %s", clusterID, path)
+	}
+	return nil
+}
+
+var _ Sink = &KubernetesSink{}
+
+//KubernetesSink Sink applying the secret of each cluster to a Kubernetes cluster
+type KubernetesSink struct {
+	secretConverter
+	applier   SecretApplier
+	namespace *SecretTemplate
+	force     bool
+}
+
+//NewKubernetesSink Create a KubernetesSink, the namespace of each secret is rendered from namespace, a blank namespace
+//uses the namespace of the applier
+func NewKubernetesSink(applier SecretApplier, namespace, name *SecretTemplate, force bool, opts ...smtpdetails.SecretOption) *KubernetesSink {
+	return &KubernetesSink{
+		secretConverter: secretConverter{name: name, opts: opts},
+		applier:         applier,
+		namespace:       namespace,
+		force:           force,
+	}
+}
+
+//Emit Apply the secret of a cluster
+func (s *KubernetesSink) Emit(clusterID string, details *smtpdetails.SMTPDetails) error {
+	secret, err := s.convert(clusterID, details)
+	if err != nil {
+		return err
+	}
+	namespace := ""
+	if s.namespace != nil {
+		if namespace, err = s.namespace.Render(clusterID); err != nil {
+			return err
+		}
+	}
+	if _, err := s.applier.ApplySecret(namespace, secret, s.force); err != nil {
+		return errors.Wrapf(err, "failed to apply secret of cluster %s", clusterID)
+	}
+	return nil
+}
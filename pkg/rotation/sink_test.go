@@ -0,0 +1,203 @@
+package rotation
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func newMockSMTPDetails() *smtpdetails.SMTPDetails {
+	return &smtpdetails.SMTPDetails{ID: "test", Host: "smtp.example.com", Port: 587, Username: "apikey", Password: "test"}
+}
+
+func mustParseSecretTemplate(t *testing.T, text string) *SecretTemplate {
+	tmpl, err := ParseSecretTemplate(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tmpl
+}
+
+type mockApplier struct {
+	namespace string
+	secret    *apiv1.Secret
+	err       error
+}
+
+func (a *mockApplier) ApplySecret(namespace string, secret *apiv1.Secret, force bool) (*apiv1.Secret, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	a.namespace = namespace
+	a.secret = secret
+	return secret, nil
+}
+
+func TestParseSecretTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		want          string
+		wantParseErr  bool
+		wantRenderErr bool
+	}{
+		{
+			name: "should render cluster id",
+			text: DefaultSecretNameTemplate,
+			want: "test-smtp",
+		},
+		{
+			name: "should render constant",
+			text: "smtp",
+			want: "smtp",
+		},
+		{
+			name:         "should fail on invalid template",
+			text:         "{{.ClusterID",
+			wantParseErr: true,
+		},
+		{
+			name:          "should fail on unknown field",
+			text:          "{{.Unknown}}",
+			wantRenderErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseSecretTemplate(tt.text)
+			if (err != nil) != tt.wantParseErr {
+				t.Fatalf("ParseSecretTemplate() error = %v, wantErr %v", err, tt.wantParseErr)
+			}
+			if tt.wantParseErr {
+				return
+			}
+			got, err := tmpl.Render("test")
+			if (err != nil) != tt.wantRenderErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantRenderErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Render() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterSink_Emit(t *testing.T) {
+	var out bytes.Buffer
+	sink := NewWriterSink(&out, mustParseSecretTemplate(t, DefaultSecretNameTemplate))
+	if err := sink.Emit("test", newMockSMTPDetails()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secret := &apiv1.Secret{}
+	if err := json.Unmarshal(out.Bytes(), secret); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if secret.Name != "test-smtp" || string(secret.Data[smtpdetails.SecretKeyPassword]) != "test" {
+		t.Fatalf("unexpected secret %v", secret)
+	}
+}
+
+func TestDirSink_Emit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sink")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := NewDirSink(filepath.Join(dir, "missing"), mustParseSecretTemplate(t, "smtp")); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+	sink, err := NewDirSink(dir, mustParseSecretTemplate(t, "smtp"), smtpdetails.WithSecretKeys(&smtpdetails.SecretKeys{Password: "SMTP_PASSWORD"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sink.Emit("test", newMockSMTPDetails()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "test.json"))
+	if err != nil {
+		t.Fatalf("failed to read secret: %v", err)
+	}
+	secret := &apiv1.Secret{}
+	if err := json.Unmarshal(raw, secret); err != nil {
+		t.Fatalf("failed to unmarshal secret: %v", err)
+	}
+	if secret.Name != "smtp" || len(secret.Data) != 1 || string(secret.Data["SMTP_PASSWORD"]) != "test" {
+		t.Fatalf("unexpected secret %v", secret)
+	}
+}
+
+func TestDirSink_Emit_InvalidClusterID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sink")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	sink, err := NewDirSink(dir, mustParseSecretTemplate(t, "smtp"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range []string{"a/x", `b\x`, "../x", "..", ""} {
+		if err := sink.Emit(id, newMockSMTPDetails()); err == nil {
+			t.Fatalf("expected error for cluster id %q", id)
+		}
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected no secrets to be written, got %d", len(files))
+	}
+}
+
+func TestKubernetesSink_Emit(t *testing.T) {
+	tests := []struct {
+		name          string
+		namespace     string
+		applier       *mockApplier
+		wantNamespace string
+		wantErr       bool
+	}{
+		{
+			name:          "should apply secret in rendered namespace",
+			namespace:     "{{.ClusterID}}-ns",
+			applier:       &mockApplier{},
+			wantNamespace: "test-ns",
+		},
+		{
+			name:    "should apply secret in default namespace",
+			applier: &mockApplier{},
+		},
+		{
+			name:    "should fail when applying fails",
+			applier: &mockApplier{err: errors.New("test")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var namespace *SecretTemplate
+			if tt.namespace != "" {
+				namespace = mustParseSecretTemplate(t, tt.namespace)
+			}
+			sink := NewKubernetesSink(tt.applier, namespace, mustParseSecretTemplate(t, DefaultSecretNameTemplate), false)
+			err := sink.Emit("test", newMockSMTPDetails())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Emit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.applier.namespace != tt.wantNamespace || tt.applier.secret.Name != "test-smtp" {
+				t.Fatalf("applied secret %s in namespace %s", tt.applier.secret.Name, tt.applier.namespace)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package rotation
+
+import "time"
+
+const (
+	//DefaultMaxAge Age after which the API key of a cluster is rotated, 90 days
+	DefaultMaxAge = 90 * 24 * time.Hour
+	//DefaultInterval Interval between rotation runs of the daemon
+	DefaultInterval = time.Hour
+	//DefaultConcurrency Number of clusters rotated in parallel
+	DefaultConcurrency = 4
+	//DefaultSecretNameTemplate Template of the secret name of a cluster used by sinks
+	DefaultSecretNameTemplate = "{{.ClusterID}}-smtp"
+
+	//ActionSkipped The API key of the cluster is younger than the max age
+	ActionSkipped Action = "skipped"
+	//ActionTracked The cluster was seen for the first time and is now tracked
+	ActionTracked Action = "tracked"
+	//ActionRotated The API key of the cluster was rotated and the secret emitted
+	ActionRotated Action = "rotated"
+	//ActionWouldRotate The API key of the cluster would be rotated, in a dry run
+	ActionWouldRotate Action = "would-rotate"
+	//ActionFailed Rotating the API key of the cluster failed
+	ActionFailed Action = "failed"
+)
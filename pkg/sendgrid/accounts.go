@@ -0,0 +1,399 @@
+package sendgrid
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var _ smtpdetails.Client = &ShardedClient{}
+var _ store.Inventory = &ShardedClient{}
+
+//Placement Policy choosing the master account the sub user of a new cluster is placed in
+type Placement string
+
+const (
+	//PlacementFillFirst Place sub users in the first master account with room for another sub user
+	PlacementFillFirst Placement = "fill-first"
+	//PlacementHash Place sub users in a master account chosen by a hash of the cluster id
+	PlacementHash Placement = "hash"
+	//PlacementLeastUsed Place sub users in the master account with the fewest sub users
+	PlacementLeastUsed Placement = "least-used"
+)
+
+//Placements All supported placement policies
+var Placements = []Placement{PlacementFillFirst, PlacementHash, PlacementLeastUsed}
+
+//ParsePlacement Find a placement policy by it's name, a blank name returns fill-first
+func ParsePlacement(name string) (Placement, error) {
+	if name == "" {
+		return PlacementFillFirst, nil
+	}
+	for _, p := range Placements {
+		if strings.EqualFold(string(p), name) {
+			return p, nil
+		}
+	}
+	return "", &smtpdetails.ValidationError{Message: fmt.Sprintf("placement %s is invalid, supported placements are %v", name, Placements)}
+}
+
+//AccountKey Name and API key of a SendGrid master account
+type AccountKey struct {
+	Name   string
+	APIKey string
+}
+
+//ParseAccountKeys Parse a list of name=api_key pairs of master accounts, names must be unique
+func ParseAccountKeys(pairs []string) ([]*AccountKey, error) {
+	var keys []*AccountKey
+	seen := map[string]bool{}
+	for _, pair := range pairs {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New("invalid account, expected format name=api_key")
+		}
+		if seen[parts[0]] {
+			return nil, errors.New(fmt.Sprintf("account %s is declared more than once", parts[0]))
+		}
+		seen[parts[0]] = true
+		keys = append(keys, &AccountKey{Name: parts[0], APIKey: parts[1]})
+	}
+	return keys, nil
+}
+
+//Account A SendGrid master account holding the sub users of clusters
+type Account struct {
+	Name   string
+	Client *Client
+}
+
+//ShardedClient Client spreading the sub users of clusters over several SendGrid master accounts. New sub users are
+//placed in an account by the placement policy, every other lookup searches all accounts.
+type ShardedClient struct {
+	accounts    []*Account
+	placement   Placement
+	maxSubUsers int
+	logger      *logrus.Entry
+}
+
+//NewDefaultShardedClient Create a ShardedClient of the master accounts in the SENDGRID_ACCOUNTS env var, or of the single
+//master account of the SENDGRID_API_KEY env var if it is not set. The options are applied to the client of every
+//account.
+func NewDefaultShardedClient(logger *logrus.Entry, placement Placement, maxSubUsers int, opts ...ClientOption) (*ShardedClient, error) {
+	accountsEnv := os.Getenv(EnvAccounts)
+	if accountsEnv == "" {
+		client, err := NewDefaultClient(logger, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return NewShardedClient([]*Account{{Name: DefaultAccountName, Client: client}}, placement, maxSubUsers, logger)
+	}
+	keys, err := ParseAccountKeys(strings.Split(accountsEnv, ","))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s env var", EnvAccounts)
+	}
+	var accounts []*Account
+	for _, key := range keys {
+		client, err := NewClientWithAPIKey(key.APIKey, logger.WithField(LogFieldAccount, key.Name), opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create client of account %s", key.Name)
+		}
+		accounts = append(accounts, &Account{Name: key.Name, Client: client})
+	}
+	return NewShardedClient(accounts, placement, maxSubUsers, logger)
+}
+
+//NewShardedClient Create a new ShardedClient, maxSubUsers limits the sub users of each account, zero is unlimited
+func NewShardedClient(accounts []*Account, placement Placement, maxSubUsers int, logger *logrus.Entry) (*ShardedClient, error) {
+	if len(accounts) == 0 {
+		return nil, errors.New("at least one account must be defined")
+	}
+	if _, err := ParsePlacement(string(placement)); err != nil {
+		return nil, err
+	}
+	if maxSubUsers < 0 {
+		return nil, errors.New("max sub users must not be negative")
+	}
+	return &ShardedClient{
+		accounts:    accounts,
+		placement:   placement,
+		maxSubUsers: maxSubUsers,
+		logger:      logger,
+	}, nil
+}
+
+//Accounts The master accounts of the client, in the order they were configured
+func (c *ShardedClient) Accounts() []*Account {
+	return c.accounts
+}
+
+//MaxSubUsers Maximum number of sub users of each account, zero is unlimited
+func (c *ShardedClient) MaxSubUsers() int {
+	return c.maxSubUsers
+}
+
+//Account Find the client of a master account by it's name, a blank name returns the first account
+func (c *ShardedClient) Account(name string) (*Client, error) {
+	if name == "" {
+		return c.accounts[0].Client, nil
+	}
+	var names []string
+	for _, account := range c.accounts {
+		if account.Name == name {
+			return account.Client, nil
+		}
+		names = append(names, account.Name)
+	}
+	return nil, &smtpdetails.NotExistError{Message: fmt.Sprintf("account %s does not exist, configured accounts are %s", name, strings.Join(names, ", "))}
+}
+
+//Create Generate the sub user and API key of a cluster, in the account already holding its sub user if there is one,
+//otherwise in the account chosen by the placement policy. The limits of the account are checked once the cluster is
+//known to have no API key, failing with a QuotaExceededError if they are reached, so an existing API key still fails
+//with an AlreadyExistsError.
+func (c *ShardedClient) Create(id string) (details *smtpdetails.SMTPDetails, err error) {
+	defer smtpdetails.ObserveOperation(smtpdetails.OperationCreate, time.Now(), &err)
+	account, err := c.locate(id)
+	if err != nil && !smtpdetails.IsNotExistError(err) {
+		return nil, err
+	}
+	if account == nil {
+		if account, err = c.place(id); err != nil {
+			return nil, err
+		}
+		c.logger.Infof("placing sub user of cluster %s in account %s", id, account.Name)
+	}
+	details, err = account.Client.create(id, func(subuser *SubUser, apiKeys []*APIKey) error {
+		return account.Client.preflight(subuser, apiKeys, c.maxSubUsers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return annotateAccount(details, account), nil
+}
+
+//Get Retrieve the SMTP details of a cluster from the account holding its sub user
+func (c *ShardedClient) Get(id string) (details *smtpdetails.SMTPDetails, err error) {
+	defer smtpdetails.ObserveOperation(smtpdetails.OperationGet, time.Now(), &err)
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	details, err = account.Client.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return annotateAccount(details, account), nil
+}
+
+//Refresh Replace the API key of a cluster in the account holding its sub user
+func (c *ShardedClient) Refresh(id string) (details *smtpdetails.SMTPDetails, err error) {
+	defer smtpdetails.ObserveOperation(smtpdetails.OperationRefresh, time.Now(), &err)
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	details, err = account.Client.Refresh(id)
+	if err != nil {
+		return nil, err
+	}
+	return annotateAccount(details, account), nil
+}
+
+//Delete Delete the sub user of a cluster from the account holding it
+func (c *ShardedClient) Delete(id string) (err error) {
+	defer smtpdetails.ObserveOperation(smtpdetails.OperationDelete, time.Now(), &err)
+	account, err := c.locate(id)
+	if err != nil {
+		return err
+	}
+	return account.Client.Delete(id)
+}
+
+//List Retrieve the IDs of the clusters of all accounts, sorted by ID
+func (c *ShardedClient) List() ([]string, error) {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, account := range c.accounts {
+		accountIDs, err := account.Client.List()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list clusters of account %s", account.Name)
+		}
+		for _, id := range accountIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+//Describe Retrieve the sub user and API key of a cluster, including the account holding them
+func (c *ShardedClient) Describe(id string) (*store.Record, error) {
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	record, err := account.Client.Describe(id)
+	if err != nil {
+		return nil, err
+	}
+	record.Account = account.Name
+	return record, nil
+}
+
+//GetMonitor Retrieve the monitor of a cluster from the account holding its sub user
+func (c *ShardedClient) GetMonitor(id string) (*Monitor, error) {
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	return account.Client.GetMonitor(id)
+}
+
+//SetMonitor Create or update the monitor of a cluster in the account holding its sub user
+func (c *ShardedClient) SetMonitor(id, email string, frequency int) (*Monitor, error) {
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	return account.Client.SetMonitor(id, email, frequency)
+}
+
+//DeleteMonitor Delete the monitor of a cluster from the account holding its sub user
+func (c *ShardedClient) DeleteMonitor(id string) error {
+	account, err := c.locate(id)
+	if err != nil {
+		return err
+	}
+	return account.Client.DeleteMonitor(id)
+}
+
+//GetEventWebhook Retrieve the event webhook settings of a cluster from the account holding its sub user
+func (c *ShardedClient) GetEventWebhook(id string) (*EventWebhookSettings, error) {
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	return account.Client.GetEventWebhook(id)
+}
+
+//SetEventWebhook Enable the event webhook of a cluster in the account holding its sub user
+func (c *ShardedClient) SetEventWebhook(id, urlTemplate string, events []string) (*EventWebhookSettings, error) {
+	account, err := c.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	return account.Client.SetEventWebhook(id, urlTemplate, events)
+}
+
+//TestEventWebhook Send a test event to the event webhook of a cluster in the account holding its sub user
+func (c *ShardedClient) TestEventWebhook(id, urlTemplate string) (string, error) {
+	account, err := c.locate(id)
+	if err != nil {
+		return "", err
+	}
+	return account.Client.TestEventWebhook(id, urlTemplate)
+}
+
+//locate Find the account holding the sub user of a cluster, with a single account no lookup is needed
+func (c *ShardedClient) locate(id string) (*Account, error) {
+	if len(c.accounts) == 1 {
+		return c.accounts[0], nil
+	}
+	for _, account := range c.accounts {
+		if _, err := account.Client.getExistingSubUser(id); err != nil {
+			if smtpdetails.IsNotExistError(err) {
+				continue
+			}
+			if smtpdetails.IsValidationError(err) {
+				return nil, err
+			}
+			return nil, errors.Wrapf(err, "failed to look up sub user of cluster %s in account %s", id, account.Name)
+		}
+		return account, nil
+	}
+	return nil, &smtpdetails.NotExistError{Message: fmt.Sprintf("sub user of cluster %s not found in any account", id)}
+}
+
+//place Choose the account the sub user of a new cluster is created in
+func (c *ShardedClient) place(id string) (*Account, error) {
+	switch c.placement {
+	case PlacementHash:
+		canonical, _, err := c.accounts[0].Client.username(id)
+		if err != nil {
+			return nil, err
+		}
+		h := fnv.New32a()
+		h.Write([]byte(canonical))
+		account := c.accounts[h.Sum32()%uint32(len(c.accounts))]
+		if c.maxSubUsers == 0 {
+			return account, nil
+		}
+		count, err := c.subUserCount(account)
+		if err != nil {
+			return nil, err
+		}
+		if count >= c.maxSubUsers {
+			return nil, &smtpdetails.QuotaExceededError{Message: fmt.Sprintf("account %s chosen for cluster %s has reached the limit of %d sub users", account.Name, id, c.maxSubUsers)}
+		}
+		return account, nil
+	case PlacementLeastUsed:
+		var least *Account
+		leastCount := 0
+		for _, account := range c.accounts {
+			count, err := c.subUserCount(account)
+			if err != nil {
+				return nil, err
+			}
+			if least == nil || count < leastCount {
+				least, leastCount = account, count
+			}
+		}
+		if c.maxSubUsers > 0 && leastCount >= c.maxSubUsers {
+			return nil, &smtpdetails.QuotaExceededError{Message: fmt.Sprintf("all accounts have reached the limit of %d sub users", c.maxSubUsers)}
+		}
+		return least, nil
+	default:
+		if c.maxSubUsers == 0 {
+			return c.accounts[0], nil
+		}
+		for _, account := range c.accounts {
+			count, err := c.subUserCount(account)
+			if err != nil {
+				return nil, err
+			}
+			if count < c.maxSubUsers {
+				return account, nil
+			}
+		}
+		return nil, &smtpdetails.QuotaExceededError{Message: fmt.Sprintf("all accounts have reached the limit of %d sub users", c.maxSubUsers)}
+	}
+}
+
+func (c *ShardedClient) subUserCount(account *Account) (int, error) {
+	count, err := account.Client.SubUserCount()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count sub users of account %s", account.Name)
+	}
+	return count, nil
+}
+
+//annotateAccount Record the account holding the sub user of a cluster in the annotations of its SMTP details
+func annotateAccount(details *smtpdetails.SMTPDetails, account *Account) *smtpdetails.SMTPDetails {
+	if details.Annotations == nil {
+		details.Annotations = map[string]string{}
+	}
+	details.Annotations[AnnotationAccount] = account.Name
+	return details
+}
@@ -0,0 +1,404 @@
+package sendgrid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
+func newMockAccount(t *testing.T, name string, usernames ...string) *Account {
+	apiClient := newMockAPIClient(func(c *APIClientMock) {
+		c.GetSubUserByUsernameFunc = func(username string) (*SubUser, error) {
+			for _, u := range usernames {
+				if u == username {
+					return &SubUser{Username: username}, nil
+				}
+			}
+			return nil, &NotExistError{Message: "test"}
+		}
+		c.ListSubUsersFunc = func(query map[string]string) ([]*SubUser, error) {
+			subusers := []*SubUser{}
+			for _, u := range usernames {
+				subusers = append(subusers, &SubUser{Username: u})
+			}
+			return subusers, nil
+		}
+		c.GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+			return []*APIKey{}, nil
+		}
+	})
+	client, err := NewClient(apiClient, mockAPIScopes, newMockPasswordGenerator(), newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return &Account{Name: name, Client: client}
+}
+
+func newMockAccountWithAPIKey(t *testing.T, name string, usernames ...string) *Account {
+	account := newMockAccount(t, name, usernames...)
+	account.Client.sendgridClient.(*APIClientMock).GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+		return []*APIKey{newMockAPIKey()}, nil
+	}
+	return account
+}
+
+func newMockAccountWithoutCredits(account *Account) *Account {
+	account.Client.sendgridClient.(*APIClientMock).GetCreditsFunc = func() (*Credits, error) {
+		return &Credits{Remain: 0, Total: 1000, Used: 1000}, nil
+	}
+	return account
+}
+
+func newMockValidatingAccount(t *testing.T, name string) *Account {
+	account := newMockAccount(t, name)
+	account.Client.idPolicy = NewIDPolicy()
+	return account
+}
+
+func newMockAccountDetails(account string) *smtpdetails.SMTPDetails {
+	details := newMockSMTPDetails()
+	details.Annotations = map[string]string{AnnotationAccount: account}
+	return details
+}
+
+func TestParsePlacement(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    Placement
+		wantErr bool
+	}{
+		{
+			name: "blank name should return fill-first",
+			arg:  "",
+			want: PlacementFillFirst,
+		},
+		{
+			name: "name should be case insensitive",
+			arg:  "Least-Used",
+			want: PlacementLeastUsed,
+		},
+		{
+			name:    "unknown name should cause error",
+			arg:     "random",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePlacement(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePlacement() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && !smtpdetails.IsValidationError(err) {
+				t.Errorf("ParsePlacement() error = %v, want ValidationError", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePlacement() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAccountKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     []string
+		want    []*AccountKey
+		wantErr bool
+	}{
+		{
+			name: "valid pairs should be parsed",
+			arg:  []string{"eu=key1", " us=key=2 "},
+			want: []*AccountKey{{Name: "eu", APIKey: "key1"}, {Name: "us", APIKey: "key=2"}},
+		},
+		{
+			name:    "missing api key should cause error",
+			arg:     []string{"eu="},
+			wantErr: true,
+		},
+		{
+			name:    "missing separator should cause error",
+			arg:     []string{"eu"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name should cause error",
+			arg:     []string{"eu=key1", "eu=key2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAccountKeys(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAccountKeys() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAccountKeys() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewShardedClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		accounts    []*Account
+		placement   Placement
+		maxSubUsers int
+		wantErr     bool
+	}{
+		{
+			name:      "valid client should be created",
+			accounts:  []*Account{newMockAccount(t, "a")},
+			placement: PlacementHash,
+		},
+		{
+			name:      "no accounts should cause error",
+			placement: PlacementFillFirst,
+			wantErr:   true,
+		},
+		{
+			name:      "unknown placement should cause error",
+			accounts:  []*Account{newMockAccount(t, "a")},
+			placement: "random",
+			wantErr:   true,
+		},
+		{
+			name:        "negative max sub users should cause error",
+			accounts:    []*Account{newMockAccount(t, "a")},
+			placement:   PlacementFillFirst,
+			maxSubUsers: -1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewShardedClient(tt.accounts, tt.placement, tt.maxSubUsers, newMockLogger())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewShardedClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShardedClient_Create(t *testing.T) {
+	tests := []struct {
+		name        string
+		accounts    []*Account
+		placement   Placement
+		maxSubUsers int
+		id          string
+		want        *smtpdetails.SMTPDetails
+		wantErrFn   func(err error) bool
+	}{
+		{
+			name:      "fill-first should use the first account without a limit",
+			accounts:  []*Account{newMockAccount(t, "a", "x1", "x2"), newMockAccount(t, "b")},
+			placement: PlacementFillFirst,
+			id:        "test",
+			want:      newMockAccountDetails("a"),
+		},
+		{
+			name:        "fill-first should skip full accounts",
+			accounts:    []*Account{newMockAccount(t, "a", "x1", "x2"), newMockAccount(t, "b")},
+			placement:   PlacementFillFirst,
+			maxSubUsers: 2,
+			id:          "test",
+			want:        newMockAccountDetails("b"),
+		},
+		{
+			name:        "least-used should use the account with the fewest sub users",
+			accounts:    []*Account{newMockAccount(t, "a", "x1", "x2"), newMockAccount(t, "b", "x3"), newMockAccount(t, "c", "x4", "x5")},
+			placement:   PlacementLeastUsed,
+			maxSubUsers: 5,
+			id:          "test",
+			want:        newMockAccountDetails("b"),
+		},
+		{
+			name:      "existing sub user should stay in its account",
+			accounts:  []*Account{newMockAccount(t, "a"), newMockAccount(t, "b", "test")},
+			placement: PlacementFillFirst,
+			id:        "test",
+			want:      newMockAccountDetails("b"),
+		},
+		{
+			name:        "all accounts full should cause quota exceeded error",
+			accounts:    []*Account{newMockAccount(t, "a", "x1"), newMockAccount(t, "b", "x2")},
+			placement:   PlacementLeastUsed,
+			maxSubUsers: 1,
+			id:          "test",
+			wantErrFn:   smtpdetails.IsQuotaExceededError,
+		},
+		{
+			name:        "full hashed account should cause quota exceeded error",
+			accounts:    []*Account{newMockAccount(t, "a", "x1"), newMockAccount(t, "b", "x2")},
+			placement:   PlacementHash,
+			maxSubUsers: 1,
+			id:          "test",
+			wantErrFn:   smtpdetails.IsQuotaExceededError,
+		},
+		{
+			name:        "existing api key in a full account should cause already exists error",
+			accounts:    []*Account{newMockAccountWithAPIKey(t, "a", "test")},
+			placement:   PlacementFillFirst,
+			maxSubUsers: 1,
+			id:          "test",
+			wantErrFn:   smtpdetails.IsAlreadyExistsError,
+		},
+		{
+			name:      "existing api key without credits should cause already exists error",
+			accounts:  []*Account{newMockAccountWithoutCredits(newMockAccountWithAPIKey(t, "a", "test"))},
+			placement: PlacementFillFirst,
+			id:        "test",
+			wantErrFn: smtpdetails.IsAlreadyExistsError,
+		},
+		{
+			name:      "new api key without credits should cause quota exceeded error",
+			accounts:  []*Account{newMockAccountWithoutCredits(newMockAccount(t, "a", "test"))},
+			placement: PlacementFillFirst,
+			id:        "test",
+			wantErrFn: smtpdetails.IsQuotaExceededError,
+		},
+		{
+			name:      "invalid id should cause validation error",
+			accounts:  []*Account{newMockValidatingAccount(t, "a"), newMockValidatingAccount(t, "b")},
+			placement: PlacementHash,
+			id:        "",
+			wantErrFn: smtpdetails.IsValidationError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewShardedClient(tt.accounts, tt.placement, tt.maxSubUsers, newMockLogger())
+			if err != nil {
+				t.Fatalf("failed to create sharded client: %v", err)
+			}
+			got, err := c.Create(tt.id)
+			if tt.wantErrFn != nil {
+				if err == nil || !tt.wantErrFn(err) {
+					t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErrFn != nil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Create() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShardedClient_Create_Hash(t *testing.T) {
+	accounts := []*Account{newMockAccount(t, "a"), newMockAccount(t, "b"), newMockAccount(t, "c")}
+	c, err := NewShardedClient(accounts, PlacementHash, 0, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create sharded client: %v", err)
+	}
+	first, err := c.Create("test")
+	if err != nil {
+		t.Fatalf("Create() unexpected error = %v", err)
+	}
+	second, err := c.Create("test")
+	if err != nil {
+		t.Fatalf("Create() unexpected error = %v", err)
+	}
+	if first.Annotations[AnnotationAccount] != second.Annotations[AnnotationAccount] {
+		t.Errorf("Create() placed the same id in accounts %s and %s", first.Annotations[AnnotationAccount], second.Annotations[AnnotationAccount])
+	}
+}
+
+func TestShardedClient_Get(t *testing.T) {
+	tests := []struct {
+		name      string
+		accounts  []*Account
+		want      *smtpdetails.SMTPDetails
+		wantErrFn func(err error) bool
+	}{
+		{
+			name:     "sub user should be found in any account",
+			accounts: []*Account{newMockAccount(t, "a", "x1"), newMockAccountWithAPIKey(t, "b", "test")},
+			want:     newMockAccountDetails("b"),
+		},
+		{
+			name:      "sub user in no account should cause not exist error",
+			accounts:  []*Account{newMockAccount(t, "a", "x1"), newMockAccount(t, "b")},
+			wantErrFn: smtpdetails.IsNotExistError,
+		},
+		{
+			name: "failing lookup should cause error",
+			accounts: []*Account{newMockAccount(t, "a"), {Name: "b", Client: &Client{
+				sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+					c.GetSubUserByUsernameFunc = func(username string) (*SubUser, error) {
+						return nil, errors.New("test")
+					}
+				}),
+				logger: newMockLogger(),
+			}}},
+			wantErrFn: func(err error) bool { return !smtpdetails.IsNotExistError(err) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewShardedClient(tt.accounts, PlacementFillFirst, 0, newMockLogger())
+			if err != nil {
+				t.Fatalf("failed to create sharded client: %v", err)
+			}
+			got, err := c.Get("test")
+			if tt.wantErrFn != nil {
+				if err == nil || !tt.wantErrFn(err) {
+					t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErrFn != nil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShardedClient_List(t *testing.T) {
+	accounts := []*Account{newMockAccount(t, "a", "rhmi-b", "rhmi-a"), newMockAccount(t, "b", "rhmi-c", "rhmi-a", "other")}
+	c, err := NewShardedClient(accounts, PlacementFillFirst, 0, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create sharded client: %v", err)
+	}
+	c.accounts[0].Client.idPolicy = &smtpdetails.IDPolicy{Prefix: "rhmi-"}
+	c.accounts[1].Client.idPolicy = &smtpdetails.IDPolicy{Prefix: "rhmi-"}
+	got, err := c.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error = %v", err)
+	}
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() got = %v, want %v", got, want)
+	}
+}
+
+func TestShardedClient_Account(t *testing.T) {
+	accounts := []*Account{newMockAccount(t, "a"), newMockAccount(t, "b")}
+	c, err := NewShardedClient(accounts, PlacementFillFirst, 0, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create sharded client: %v", err)
+	}
+	if got, err := c.Account(""); err != nil || got != accounts[0].Client {
+		t.Errorf("Account() blank name should return the first account, got = %v, err = %v", got, err)
+	}
+	if got, err := c.Account("b"); err != nil || got != accounts[1].Client {
+		t.Errorf("Account() got = %v, err = %v", got, err)
+	}
+	if _, err := c.Account("c"); !smtpdetails.IsNotExistError(err) {
+		t.Errorf("Account() unknown name should cause not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package sendgrid
+
+import (
+	"fmt"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+)
+
+//Capacity Usage of the limited resources of a SendGrid master account
+type Capacity struct {
+	Account string `json:"account"`
+	//SubUsers Number of sub users of the account, MaxSubUsers is zero if the number is unlimited
+	SubUsers    int `json:"subUsers"`
+	MaxSubUsers int `json:"maxSubUsers"`
+	//APIKeys Highest number of API keys of any sub user, held by APIKeysSubUser
+	APIKeys        int    `json:"apiKeys"`
+	APIKeysSubUser string `json:"apiKeysSubUser,omitempty"`
+	MaxAPIKeys     int    `json:"maxApiKeys"`
+	//IPs Number of IP addresses of the account, of which WarmupIPs are still warming up
+	IPs       int `json:"ips"`
+	WarmupIPs int `json:"warmupIps"`
+	//Credits Email credits of the account, nil if they could not be retrieved
+	Credits *Credits `json:"credits,omitempty"`
+	//Warnings Limits the usage of the account has crossed the threshold of
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+//Capacity Retrieve the usage of the limited resources of the master account, with a warning for every limit of which
+//at least the threshold fraction is in use. maxSubUsers is the sub user limit of the account, zero is unlimited.
+func (c *Client) Capacity(maxSubUsers int, threshold float64) (*Capacity, error) {
+	capacity := &Capacity{MaxSubUsers: maxSubUsers, MaxAPIKeys: MaxAPIKeysPerSubUser}
+	subusers, err := c.sendgridClient.ListSubUsers(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list sub users")
+	}
+	capacity.SubUsers = len(subusers)
+	for _, subuser := range subusers {
+		keys, err := c.sendgridClient.GetAPIKeysForSubUser(subuser.Username)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list api keys of sub user %s", subuser.Username)
+		}
+		if len(keys) > capacity.APIKeys {
+			capacity.APIKeys = len(keys)
+			capacity.APIKeysSubUser = subuser.Username
+		}
+	}
+	ips, err := c.sendgridClient.ListIPAddresses()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ip addresses")
+	}
+	capacity.IPs = len(ips)
+	for _, ip := range ips {
+		if ip.Warmup {
+			capacity.WarmupIPs++
+		}
+	}
+	credits, err := c.getCredits()
+	if err != nil {
+		return nil, err
+	}
+	capacity.Credits = credits
+	capacity.Warnings = capacity.check(threshold)
+	return capacity, nil
+}
+
+//check Describe every limit of which at least the threshold fraction is in use
+func (c *Capacity) check(threshold float64) []string {
+	var warnings []string
+	crossed := func(used, limit int) bool {
+		return limit > 0 && float64(used) >= threshold*float64(limit)
+	}
+	if crossed(c.SubUsers, c.MaxSubUsers) {
+		warnings = append(warnings, fmt.Sprintf("%d of %d sub users in use", c.SubUsers, c.MaxSubUsers))
+	}
+	if crossed(c.APIKeys, c.MaxAPIKeys) {
+		warnings = append(warnings, fmt.Sprintf("%d of %d api keys in use by sub user %s", c.APIKeys, c.MaxAPIKeys, c.APIKeysSubUser))
+	}
+	if c.IPs == 0 {
+		warnings = append(warnings, "no ip addresses available")
+	}
+	if c.Credits != nil && crossed(c.Credits.Used, c.Credits.Total) {
+		warnings = append(warnings, fmt.Sprintf("%d of %d credits used", c.Credits.Used, c.Credits.Total))
+	}
+	return warnings
+}
+
+//preflight Check the limits of the master account allow a sub user to get a new API key, failing with a
+//QuotaExceededError if they do not. subuser is nil if it is yet to be created, otherwise apiKeys are its API keys.
+//maxSubUsers is the sub user limit of the account, zero is unlimited.
+func (c *Client) preflight(subuser *SubUser, apiKeys []*APIKey, maxSubUsers int) error {
+	credits, err := c.getCredits()
+	if err != nil {
+		return err
+	}
+	if credits != nil && credits.Total > 0 && credits.Remain <= 0 {
+		return &smtpdetails.QuotaExceededError{Message: fmt.Sprintf("no credits remain of %d, next reset %s", credits.Total, credits.NextReset)}
+	}
+	if subuser == nil {
+		if maxSubUsers == 0 {
+			return nil
+		}
+		count, err := c.SubUserCount()
+		if err != nil {
+			return err
+		}
+		if count >= maxSubUsers {
+			return &smtpdetails.QuotaExceededError{Message: fmt.Sprintf("account has reached the limit of %d sub users", maxSubUsers)}
+		}
+		return nil
+	}
+	if len(apiKeys) >= MaxAPIKeysPerSubUser {
+		return &smtpdetails.QuotaExceededError{Message: fmt.Sprintf("sub user %s has reached the limit of %d api keys", subuser.Username, MaxAPIKeysPerSubUser)}
+	}
+	return nil
+}
+
+//getCredits Retrieve the credits of the master account, nil if the API key is not allowed to read them
+func (c *Client) getCredits() (*Credits, error) {
+	credits, err := c.sendgridClient.GetCredits()
+	if err != nil {
+		if apiErr, ok := errors.Cause(err).(*APIError); ok && (apiErr.StatusCode == 403 || apiErr.StatusCode == 404) {
+			c.logger.Warnf("skipping credits, they could not be retrieved: %v", err)
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get credits")
+	}
+	return credits, nil
+}
+
+//Capacity Retrieve the usage of the limited resources of every master account, in the order they were configured
+func (c *ShardedClient) Capacity(threshold float64) ([]*Capacity, error) {
+	capacities := make([]*Capacity, 0, len(c.accounts))
+	for _, account := range c.accounts {
+		capacity, err := account.Client.Capacity(c.maxSubUsers, threshold)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get capacity of account %s", account.Name)
+		}
+		capacity.Account = account.Name
+		capacities = append(capacities, capacity)
+	}
+	return capacities, nil
+}
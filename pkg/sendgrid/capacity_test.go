@@ -0,0 +1,220 @@
+package sendgrid
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
+func newMockAPIKeys(count int) []*APIKey {
+	keys := make([]*APIKey, 0, count)
+	for i := 0; i < count; i++ {
+		keys = append(keys, &APIKey{ID: fmt.Sprintf("test%d", i), Name: fmt.Sprintf("test%d", i)})
+	}
+	return keys
+}
+
+func TestClient_Capacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiClient   APIClient
+		maxSubUsers int
+		want        *Capacity
+		wantErr     bool
+	}{
+		{
+			name:        "usage below the threshold should not warn",
+			apiClient:   newMockAPIClient(func(c *APIClientMock) {}),
+			maxSubUsers: 10,
+			want: &Capacity{
+				SubUsers:       1,
+				MaxSubUsers:    10,
+				APIKeys:        1,
+				APIKeysSubUser: "test",
+				MaxAPIKeys:     MaxAPIKeysPerSubUser,
+				IPs:            1,
+				Credits:        newMockCredits(),
+			},
+		},
+		{
+			name: "usage above the threshold should warn",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {
+				c.ListSubUsersFunc = func(query map[string]string) ([]*SubUser, error) {
+					return []*SubUser{{Username: "a"}, {Username: "b"}}, nil
+				}
+				c.GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+					if username == "b" {
+						return newMockAPIKeys(90), nil
+					}
+					return newMockAPIKeys(1), nil
+				}
+				c.ListIPAddressesFunc = func() ([]*IPAddress, error) {
+					return []*IPAddress{}, nil
+				}
+				c.GetCreditsFunc = func() (*Credits, error) {
+					return &Credits{Remain: 100, Total: 1000, Used: 900}, nil
+				}
+			}),
+			maxSubUsers: 2,
+			want: &Capacity{
+				SubUsers:       2,
+				MaxSubUsers:    2,
+				APIKeys:        90,
+				APIKeysSubUser: "b",
+				MaxAPIKeys:     MaxAPIKeysPerSubUser,
+				Credits:        &Credits{Remain: 100, Total: 1000, Used: 900},
+				Warnings: []string{
+					"2 of 2 sub users in use",
+					"90 of 100 api keys in use by sub user b",
+					"no ip addresses available",
+					"900 of 1000 credits used",
+				},
+			},
+		},
+		{
+			name: "forbidden credits should be skipped",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetCreditsFunc = func() (*Credits, error) {
+					return nil, &APIError{StatusCode: 403, Message: "test"}
+				}
+			}),
+			want: &Capacity{
+				SubUsers:       1,
+				APIKeys:        1,
+				APIKeysSubUser: "test",
+				MaxAPIKeys:     MaxAPIKeysPerSubUser,
+				IPs:            1,
+			},
+		},
+		{
+			name: "failing credits should cause error",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetCreditsFunc = func() (*Credits, error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+		{
+			name: "failing sub user list should cause error",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {
+				c.ListSubUsersFunc = func(query map[string]string) ([]*SubUser, error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient: tt.apiClient,
+				logger:         newMockLogger(),
+			}
+			got, err := c.Capacity(tt.maxSubUsers, DefaultCapacityThreshold)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Capacity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Capacity() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_preflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiClient   APIClient
+		subuser     *SubUser
+		apiKeys     []*APIKey
+		maxSubUsers int
+		wantErrFn   func(err error) bool
+	}{
+		{
+			name:      "existing sub user with room for a key should pass",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {}),
+			subuser:   newMockSubUser(),
+			apiKeys:   newMockAPIKeys(1),
+		},
+		{
+			name:      "new sub user without a limit should pass",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {}),
+		},
+		{
+			name: "exhausted credits should cause quota exceeded error",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetCreditsFunc = func() (*Credits, error) {
+					return &Credits{Remain: 0, Total: 1000, Used: 1000}, nil
+				}
+			}),
+			subuser:   newMockSubUser(),
+			wantErrFn: smtpdetails.IsQuotaExceededError,
+		},
+		{
+			name:        "new sub user in a full account should cause quota exceeded error",
+			apiClient:   newMockAPIClient(func(c *APIClientMock) {}),
+			maxSubUsers: 1,
+			wantErrFn:   smtpdetails.IsQuotaExceededError,
+		},
+		{
+			name:      "sub user with the maximum number of keys should cause quota exceeded error",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {}),
+			subuser:   newMockSubUser(),
+			apiKeys:   newMockAPIKeys(MaxAPIKeysPerSubUser),
+			wantErrFn: smtpdetails.IsQuotaExceededError,
+		},
+		{
+			name: "failing credits lookup should cause error",
+			apiClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetCreditsFunc = func() (*Credits, error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErrFn: func(err error) bool { return !smtpdetails.IsQuotaExceededError(err) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient: tt.apiClient,
+				logger:         newMockLogger(),
+			}
+			err := c.preflight(tt.subuser, tt.apiKeys, tt.maxSubUsers)
+			if tt.wantErrFn == nil {
+				if err != nil {
+					t.Errorf("preflight() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !tt.wantErrFn(err) {
+				t.Errorf("preflight() error = %v, wantErr true", err)
+			}
+		})
+	}
+}
+
+func TestShardedClient_Capacity(t *testing.T) {
+	accounts := []*Account{newMockAccount(t, "a", "x1"), newMockAccount(t, "b", "x2", "x3")}
+	c, err := NewShardedClient(accounts, PlacementFillFirst, 2, newMockLogger())
+	if err != nil {
+		t.Fatalf("failed to create sharded client: %v", err)
+	}
+	got, err := c.Capacity(DefaultCapacityThreshold)
+	if err != nil {
+		t.Fatalf("Capacity() unexpected error = %v", err)
+	}
+	if len(got) != 2 || got[0].Account != "a" || got[1].Account != "b" {
+		t.Fatalf("Capacity() got = %v, want capacities of accounts a and b", got)
+	}
+	if len(got[0].Warnings) != 0 {
+		t.Errorf("Capacity() account a got warnings %v, want none", got[0].Warnings)
+	}
+	if want := []string{"2 of 2 sub users in use"}; !reflect.DeepEqual(got[1].Warnings, want) {
+		t.Errorf("Capacity() account b got warnings %v, want %v", got[1].Warnings, want)
+	}
+}
@@ -0,0 +1,196 @@
+package sendgrid
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//DryRunAPIKey Placeholder ID and key of API keys created by a DryRunAPIClient
+const DryRunAPIKey = "dry-run"
+
+var _ APIClient = &DryRunAPIClient{}
+
+//Mutation A mutating SendGrid API call planned by a DryRunAPIClient instead of being executed
+type Mutation struct {
+	Action string            `json:"action"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+//String Format the mutation as the action followed by its sorted params
+func (m *Mutation) String() string {
+	params := make([]string, 0, len(m.Params))
+	for k, v := range m.Params {
+		params = append(params, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(params)
+	return strings.TrimSpace(fmt.Sprintf("%s %s", m.Action, strings.Join(params, " ")))
+}
+
+//Plan Mutations planned by DryRunAPIClients, in the order they were planned
+type Plan struct {
+	mu        sync.Mutex
+	mutations []*Mutation
+}
+
+//Mutations Retrieve the planned mutations
+func (p *Plan) Mutations() []*Mutation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Mutation{}, p.mutations...)
+}
+
+func (p *Plan) record(action string, params map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mutations = append(p.mutations, &Mutation{Action: action, Params: params})
+}
+
+//DryRunAPIClient APIClient decorator performing read calls and recording mutating calls in a Plan instead of executing
+//them. Reads of sub users planned to be created are answered by the decorator, as they do not exist in SendGrid.
+type DryRunAPIClient struct {
+	APIClient
+	plan     *Plan
+	mu       sync.Mutex
+	subUsers map[string]*SubUser
+}
+
+//NewDryRunAPIClient Create a DryRunAPIClient recording the mutations of client in plan
+func NewDryRunAPIClient(client APIClient, plan *Plan) *DryRunAPIClient {
+	return &DryRunAPIClient{APIClient: client, plan: plan, subUsers: map[string]*SubUser{}}
+}
+
+//WithDryRun Record the mutations of the Client in plan instead of executing them
+func WithDryRun(plan *Plan) ClientOption {
+	return func(c *Client) {
+		c.sendgridClient = NewDryRunAPIClient(c.sendgridClient, plan)
+	}
+}
+
+func (c *DryRunAPIClient) plannedSubUser(username string) *SubUser {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subUsers[username]
+}
+
+//CreateIPPool Plan the creation of an IP pool
+func (c *DryRunAPIClient) CreateIPPool(name string) (*IPPool, error) {
+	c.plan.record("create ip pool", map[string]string{"name": name})
+	return &IPPool{Name: name}, nil
+}
+
+//AddIPToPool Plan the addition of an IP address to an IP pool
+func (c *DryRunAPIClient) AddIPToPool(name, ip string) error {
+	c.plan.record("add ip to pool", map[string]string{"pool": name, "ip": ip})
+	return nil
+}
+
+//RemoveIPFromPool Plan the removal of an IP address from an IP pool
+func (c *DryRunAPIClient) RemoveIPFromPool(name, ip string) error {
+	c.plan.record("remove ip from pool", map[string]string{"pool": name, "ip": ip})
+	return nil
+}
+
+//StartIPWarmup Plan the start of the warmup of an IP address
+func (c *DryRunAPIClient) StartIPWarmup(ip string) (*WarmupIP, error) {
+	c.plan.record("start ip warmup", map[string]string{"ip": ip})
+	return &WarmupIP{IP: ip}, nil
+}
+
+//StopIPWarmup Plan the end of the warmup of an IP address
+func (c *DryRunAPIClient) StopIPWarmup(ip string) error {
+	c.plan.record("stop ip warmup", map[string]string{"ip": ip})
+	return nil
+}
+
+//GetAPIKeysForSubUser Get the API keys of a sub user, a planned sub user has no API keys
+func (c *DryRunAPIClient) GetAPIKeysForSubUser(username string) ([]*APIKey, error) {
+	if c.plannedSubUser(username) != nil {
+		return []*APIKey{}, nil
+	}
+	return c.APIClient.GetAPIKeysForSubUser(username)
+}
+
+//CreateAPIKeyForSubUser Plan the creation of an API key, returning a placeholder key
+func (c *DryRunAPIClient) CreateAPIKeyForSubUser(username string, scopes []string) (*APIKey, error) {
+	c.plan.record("create api key", map[string]string{"sub user": username, "name": username, "scopes": strings.Join(scopes, ",")})
+	return &APIKey{ID: DryRunAPIKey, Key: DryRunAPIKey, Name: username, Scopes: scopes}, nil
+}
+
+//DeleteAPIKeyForSubUser Plan the deletion of an API key
+func (c *DryRunAPIClient) DeleteAPIKeyForSubUser(id, keyName string) error {
+	c.plan.record("delete api key", map[string]string{"id": id, "name": keyName})
+	return nil
+}
+
+//CreateSubUser Plan the creation of a sub user, the password is not recorded
+func (c *DryRunAPIClient) CreateSubUser(id, email, password string, ips []string) (*SubUser, error) {
+	c.plan.record("create sub user", map[string]string{"username": id, "email": email, "ips": strings.Join(ips, ",")})
+	subuser := &SubUser{Username: id, Email: email}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subUsers[id] = subuser
+	return subuser, nil
+}
+
+//DeleteSubUser Plan the deletion of a sub user
+func (c *DryRunAPIClient) DeleteSubUser(username string) error {
+	c.plan.record("delete sub user", map[string]string{"username": username})
+	return nil
+}
+
+//GetSubUserByUsername Get a sub user, including planned sub users
+func (c *DryRunAPIClient) GetSubUserByUsername(username string) (*SubUser, error) {
+	if subuser := c.plannedSubUser(username); subuser != nil {
+		return subuser, nil
+	}
+	return c.APIClient.GetSubUserByUsername(username)
+}
+
+//GetSubUserMonitor Get the monitor of a sub user, a planned sub user has no monitor
+func (c *DryRunAPIClient) GetSubUserMonitor(username string) (*Monitor, error) {
+	if c.plannedSubUser(username) != nil {
+		return nil, &NotExistError{Message: fmt.Sprintf("monitor for sub user %s does not exist", username)}
+	}
+	return c.APIClient.GetSubUserMonitor(username)
+}
+
+//CreateSubUserMonitor Plan the creation of the monitor of a sub user
+func (c *DryRunAPIClient) CreateSubUserMonitor(username, email string, frequency int) (*Monitor, error) {
+	c.plan.record("create monitor", map[string]string{"sub user": username, "email": email, "frequency": strconv.Itoa(frequency)})
+	return &Monitor{Email: email, Frequency: frequency}, nil
+}
+
+//UpdateSubUserMonitor Plan the update of the monitor of a sub user
+func (c *DryRunAPIClient) UpdateSubUserMonitor(username, email string, frequency int) (*Monitor, error) {
+	c.plan.record("update monitor", map[string]string{"sub user": username, "email": email, "frequency": strconv.Itoa(frequency)})
+	return &Monitor{Email: email, Frequency: frequency}, nil
+}
+
+//DeleteSubUserMonitor Plan the deletion of the monitor of a sub user
+func (c *DryRunAPIClient) DeleteSubUserMonitor(username string) error {
+	c.plan.record("delete monitor", map[string]string{"sub user": username})
+	return nil
+}
+
+//GetEventWebhookSettings Get the event webhook settings of a sub user, a planned sub user has disabled settings
+func (c *DryRunAPIClient) GetEventWebhookSettings(username string) (*EventWebhookSettings, error) {
+	if c.plannedSubUser(username) != nil {
+		return &EventWebhookSettings{}, nil
+	}
+	return c.APIClient.GetEventWebhookSettings(username)
+}
+
+//UpdateEventWebhookSettings Plan the update of the event webhook settings of a sub user
+func (c *DryRunAPIClient) UpdateEventWebhookSettings(username string, settings *EventWebhookSettings) (*EventWebhookSettings, error) {
+	c.plan.record("update event webhook", map[string]string{"sub user": username, "url": settings.URL, "events": strings.Join(settings.EnabledEvents(), ",")})
+	return settings, nil
+}
+
+//TestEventWebhook Plan sending a test event to an event webhook
+func (c *DryRunAPIClient) TestEventWebhook(username, url string) error {
+	c.plan.record("test event webhook", map[string]string{"sub user": username, "url": url})
+	return nil
+}
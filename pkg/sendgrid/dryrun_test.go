@@ -0,0 +1,102 @@
+package sendgrid
+
+import (
+	"reflect"
+	"testing"
+)
+
+//newMockReadOnlyAPIClient API client implementing only read calls, any mutation panics
+func newMockReadOnlyAPIClient(subUserExists bool) *APIClientMock {
+	return &APIClientMock{
+		GetSubUserByUsernameFunc: func(username string) (user *SubUser, e error) {
+			if !subUserExists {
+				return nil, &NotExistError{Message: "test"}
+			}
+			return newMockSubUser(), nil
+		},
+		GetAPIKeysForSubUserFunc: func(username string) (keys []*APIKey, e error) {
+			return []*APIKey{newMockAPIKey()}, nil
+		},
+		ListIPAddressesFunc: func() (addresses []*IPAddress, e error) {
+			return []*IPAddress{newMockIPAddress()}, nil
+		},
+	}
+}
+
+func TestDryRunAPIClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		subUserExists bool
+		opts          []ClientOption
+		run           func(c *Client) error
+		want          []string
+	}{
+		{
+			name: "should plan sub user and api key creation",
+			opts: []ClientOption{WithSubUserMonitor("monitor@example.com", 10), WithEventWebhook("https://example.com/{{.ClusterID}}", []string{EventDelivered, EventBounce})},
+			run: func(c *Client) error {
+				details, err := c.Create("test")
+				if err == nil && details.Password != DryRunAPIKey {
+					t.Fatalf("expected placeholder api key, got %s", details.Password)
+				}
+				return err
+			},
+			want: []string{
+				"create sub user email=test@email.com ips=127.0.0.1 username=test",
+				"create monitor email=monitor@example.com frequency=10 sub user=test",
+				"update event webhook events=delivered,bounce sub user=test url=https://example.com/test",
+				"create api key name=test scopes=test sub user=test",
+			},
+		},
+		{
+			name:          "should plan api key rotation",
+			subUserExists: true,
+			run: func(c *Client) error {
+				_, err := c.Refresh("test")
+				return err
+			},
+			want: []string{
+				"delete api key id=test name=test",
+				"create api key name=test scopes=test sub user=test",
+			},
+		},
+		{
+			name:          "should plan sub user deletion",
+			subUserExists: true,
+			run: func(c *Client) error {
+				return c.Delete("test")
+			},
+			want: []string{"delete sub user username=test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &Plan{}
+			c, err := NewClient(newMockReadOnlyAPIClient(tt.subUserExists), mockAPIScopes, mockPasswordGen, newMockLogger(), append(tt.opts, WithDryRun(plan))...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := tt.run(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, m := range plan.Mutations() {
+				got = append(got, m.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("planned mutations got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventWebhookSettings_EnabledEvents(t *testing.T) {
+	events := []string{EventProcessed, EventBounce, EventGroupResubscribe}
+	settings, err := NewEventWebhookSettings("https://example.com", events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := settings.EnabledEvents(); !reflect.DeepEqual(got, events) {
+		t.Fatalf("EnabledEvents() got = %v, want %v", got, events)
+	}
+}
@@ -1,5 +1,11 @@
 package sendgrid
 
+import (
+	"fmt"
+
+	"github.com/sendgrid/rest"
+)
+
 //AlreadyExistsError Error to indicate an API key already exists
 type AlreadyExistsError struct {
 	Message string
@@ -31,3 +37,25 @@ func IsNotExistError(err error) bool {
 	_, ok := err.(*NotExistError)
 	return ok
 }
+
+//APIError Error to indicate the SendGrid API responded with an unexpected status code
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+//Error String representation of error
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+//IsAPIError Compare check for APIError
+func IsAPIError(err error) bool {
+	_, ok := err.(*APIError)
+	return ok
+}
+
+//newAPIError Create an APIError for a response without the expected status code
+func newAPIError(resp *rest.Response, expected int) *APIError {
+	return &APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("non-%d status code returned, code=%d body=%s", expected, resp.StatusCode, resp.Body)}
+}
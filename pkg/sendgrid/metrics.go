@@ -0,0 +1,65 @@
+package sendgrid
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/integr8ly/smtp-service/pkg/metrics"
+)
+
+var (
+	apiRequestsTotal   = metrics.Default.NewCounter(MetricAPIRequestsTotal, "Number of SendGrid API requests by route, method and status code.", "route", "method", "status")
+	apiRequestDuration = metrics.Default.NewHistogram(MetricAPIRequestDurationSeconds, "Duration of SendGrid API requests by route and method.", metrics.DefaultBuckets, "route", "method")
+	apiRetriesTotal    = metrics.Default.NewCounter(MetricAPIRetriesTotal, "Number of rate limited SendGrid API requests retried by route and method.", "route", "method")
+)
+
+//metricRoutes Templates of the API routes used by the client, a {param} segment matches any value. Routes are used as
+//the route label instead of the request path to keep usernames, pools and ips out of the label values.
+var metricRoutes = []string{
+	APIRouteSubUsers,
+	APIRouteSubUsers + "/{username}",
+	fmt.Sprintf(APIRouteSubUserMonitor, "{username}"),
+	APIRouteAPIKeys,
+	APIRouteAPIKeys + "/{id}",
+	APIRouteIPAddresses,
+	APIRouteIPPools,
+	APIRouteIPPools + "/{name}/ips",
+	APIRouteIPPools + "/{name}/ips/{ip}",
+	APIRouteIPWarmup,
+	APIRouteIPWarmup + "/{ip}",
+	APIRouteEventWebhookSettings,
+	APIRouteEventWebhookTest,
+	APIRouteCredits,
+}
+
+//metricRoute Find the route template of a request URL, literal segments take precedence over {param} segments
+func metricRoute(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return MetricRouteOther
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	best, bestLiterals := MetricRouteOther, -1
+	for _, route := range metricRoutes {
+		routeSegments := strings.Split(strings.Trim(route, "/"), "/")
+		if len(routeSegments) != len(segments) {
+			continue
+		}
+		literals := 0
+		for i, segment := range routeSegments {
+			if strings.HasPrefix(segment, "{") {
+				continue
+			}
+			if segment != segments[i] {
+				literals = -1
+				break
+			}
+			literals++
+		}
+		if literals > bestLiterals {
+			best, bestLiterals = route, literals
+		}
+	}
+	return best
+}
@@ -0,0 +1,96 @@
+package sendgrid
+
+import (
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+)
+
+//WithSubUserMonitor Attach a monitor sending a sample of outgoing mail to email to every sub user handled by Create
+func WithSubUserMonitor(email string, frequency int) ClientOption {
+	return func(c *Client) {
+		c.subUserMonitor = &Monitor{
+			Email:     email,
+			Frequency: frequency,
+		}
+	}
+}
+
+//GetMonitor Retrieve the monitor settings of the SendGrid sub user associated with a cluster by it's ID
+func (c *Client) GetMonitor(id string) (*Monitor, error) {
+	subuser, err := c.getExistingSubUser(id)
+	if err != nil {
+		return nil, err
+	}
+	monitor, err := c.sendgridClient.GetSubUserMonitor(subuser.Username)
+	if err != nil {
+		if IsNotExistError(err) {
+			return nil, &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return nil, errors.Wrapf(err, "failed to get monitor for sub user %s", id)
+	}
+	return monitor, nil
+}
+
+//SetMonitor Create or update the monitor settings of the SendGrid sub user associated with a cluster by it's ID
+func (c *Client) SetMonitor(id, email string, frequency int) (*Monitor, error) {
+	subuser, err := c.getExistingSubUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.setSubUserMonitor(subuser.Username, email, frequency)
+}
+
+//DeleteMonitor Remove the monitor settings of the SendGrid sub user associated with a cluster by it's ID
+func (c *Client) DeleteMonitor(id string) error {
+	subuser, err := c.getExistingSubUser(id)
+	if err != nil {
+		return err
+	}
+	if err := c.sendgridClient.DeleteSubUserMonitor(subuser.Username); err != nil {
+		if IsNotExistError(err) {
+			return &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return errors.Wrapf(err, "failed to delete monitor for sub user %s", id)
+	}
+	return nil
+}
+
+func (c *Client) setSubUserMonitor(username, email string, frequency int) (*Monitor, error) {
+	existing, err := c.sendgridClient.GetSubUserMonitor(username)
+	if err != nil && !IsNotExistError(err) {
+		return nil, errors.Wrapf(err, "failed to check if monitor exists for sub user %s", username)
+	}
+	if existing == nil {
+		c.logger.Debugf("no monitor found for sub user %s, creating it", username)
+		monitor, err := c.sendgridClient.CreateSubUserMonitor(username, email, frequency)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create monitor for sub user %s", username)
+		}
+		return monitor, nil
+	}
+	if existing.Email == email && existing.Frequency == frequency {
+		c.logger.Debugf("monitor for sub user %s is already up to date", username)
+		return existing, nil
+	}
+	c.logger.Debugf("updating existing monitor for sub user %s", username)
+	monitor, err := c.sendgridClient.UpdateSubUserMonitor(username, email, frequency)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update monitor for sub user %s", username)
+	}
+	return monitor, nil
+}
+
+func (c *Client) getExistingSubUser(id string) (*SubUser, error) {
+	_, username, err := c.username(id)
+	if err != nil {
+		return nil, err
+	}
+	subuser, err := c.sendgridClient.GetSubUserByUsername(username)
+	if err != nil {
+		if IsNotExistError(err) {
+			return nil, &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return nil, errors.Wrapf(err, "failed to get sub user %s", id)
+	}
+	return subuser, nil
+}
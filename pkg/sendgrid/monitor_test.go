@@ -0,0 +1,236 @@
+package sendgrid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
+func TestClient_SetMonitor(t *testing.T) {
+	type args struct {
+		id        string
+		email     string
+		frequency int
+	}
+	tests := []struct {
+		name           string
+		sendgridClient *APIClientMock
+		args           args
+		want           *Monitor
+		wantErr        bool
+		wantNotExist   bool
+		wantCreates    int
+		wantUpdates    int
+	}{
+		{
+			name: "monitor created when none exists",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetSubUserMonitorFunc = func(username string) (*Monitor, error) {
+					return nil, &NotExistError{Message: "test"}
+				}
+			}).(*APIClientMock),
+			args:        args{id: "test", email: "new@email.com", frequency: 10},
+			want:        &Monitor{Email: "new@email.com", Frequency: 10},
+			wantCreates: 1,
+		},
+		{
+			name:           "existing monitor updated",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}).(*APIClientMock),
+			args:           args{id: "test", email: "new@email.com", frequency: 10},
+			want:           &Monitor{Email: "new@email.com", Frequency: 10},
+			wantUpdates:    1,
+		},
+		{
+			name:           "existing monitor already up to date",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}).(*APIClientMock),
+			args:           args{id: "test", email: newMockMonitor().Email, frequency: newMockMonitor().Frequency},
+			want:           newMockMonitor(),
+		},
+		{
+			name: "sub user does not exist",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetSubUserByUsernameFunc = func(username string) (*SubUser, error) {
+					return nil, &NotExistError{Message: "test"}
+				}
+			}).(*APIClientMock),
+			args:         args{id: "test", email: "new@email.com", frequency: 10},
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "getting monitor fails",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetSubUserMonitorFunc = func(username string) (*Monitor, error) {
+					return nil, errors.New("test")
+				}
+			}).(*APIClientMock),
+			args:    args{id: "test", email: "new@email.com", frequency: 10},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient:              tt.sendgridClient,
+				sendgridSubUserAPIKeyScopes: mockAPIScopes,
+				passwordGenerator:           mockPasswordGen,
+				logger:                      newMockLogger(),
+			}
+			got, err := c.SetMonitor(tt.args.id, tt.args.email, tt.args.frequency)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetMonitor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if smtpdetails.IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("SetMonitor() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetMonitor() got = %v, want %v", got, tt.want)
+			}
+			if n := len(tt.sendgridClient.CreateSubUserMonitorCalls()); n != tt.wantCreates {
+				t.Errorf("SetMonitor() creates = %d, want %d", n, tt.wantCreates)
+			}
+			if n := len(tt.sendgridClient.UpdateSubUserMonitorCalls()); n != tt.wantUpdates {
+				t.Errorf("SetMonitor() updates = %d, want %d", n, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestClient_GetMonitor(t *testing.T) {
+	tests := []struct {
+		name           string
+		sendgridClient APIClient
+		want           *Monitor
+		wantErr        bool
+		wantNotExist   bool
+	}{
+		{
+			name:           "successful get",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}),
+			want:           newMockMonitor(),
+		},
+		{
+			name: "monitor does not exist",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetSubUserMonitorFunc = func(username string) (*Monitor, error) {
+					return nil, &NotExistError{Message: "test"}
+				}
+			}),
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "getting monitor fails",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetSubUserMonitorFunc = func(username string) (*Monitor, error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient:              tt.sendgridClient,
+				sendgridSubUserAPIKeyScopes: mockAPIScopes,
+				passwordGenerator:           mockPasswordGen,
+				logger:                      newMockLogger(),
+			}
+			got, err := c.GetMonitor("test")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetMonitor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if smtpdetails.IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("GetMonitor() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMonitor() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_DeleteMonitor(t *testing.T) {
+	tests := []struct {
+		name           string
+		sendgridClient APIClient
+		wantErr        bool
+		wantNotExist   bool
+	}{
+		{
+			name:           "successful delete",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}),
+		},
+		{
+			name: "monitor does not exist",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.DeleteSubUserMonitorFunc = func(username string) error {
+					return &NotExistError{Message: "test"}
+				}
+			}),
+			wantErr:      true,
+			wantNotExist: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient:              tt.sendgridClient,
+				sendgridSubUserAPIKeyScopes: mockAPIScopes,
+				passwordGenerator:           mockPasswordGen,
+				logger:                      newMockLogger(),
+			}
+			err := c.DeleteMonitor("test")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteMonitor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if smtpdetails.IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("DeleteMonitor() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+		})
+	}
+}
+
+func TestClient_Create_WithSubUserMonitor(t *testing.T) {
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {
+		c.GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+			return []*APIKey{}, nil
+		}
+		c.GetSubUserMonitorFunc = func(username string) (*Monitor, error) {
+			return nil, &NotExistError{Message: "test"}
+		}
+	}).(*APIClientMock)
+	c, err := NewClient(sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithSubUserMonitor("abuse@email.com", 50))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := c.Create("test"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	calls := sendgridClient.CreateSubUserMonitorCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Create() monitor creates = %d, want 1", len(calls))
+	}
+	if calls[0].Username != "test" || calls[0].Email != "abuse@email.com" || calls[0].Frequency != 50 {
+		t.Errorf("Create() monitor = %+v, want username=test email=abuse@email.com frequency=50", calls[0])
+	}
+}
+
+func TestClient_Create_WithSubUserMonitor_ExistingAPIKey(t *testing.T) {
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {}).(*APIClientMock)
+	c, err := NewClient(sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithSubUserMonitor("abuse@email.com", 50))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := c.Create("test"); !smtpdetails.IsAlreadyExistsError(err) {
+		t.Fatalf("Create() error = %v, want already exists error", err)
+	}
+	if calls := sendgridClient.CreateSubUserMonitorCalls(); len(calls) != 0 {
+		t.Errorf("Create() monitor creates = %d, want 0", len(calls))
+	}
+}
@@ -0,0 +1,135 @@
+package sendgrid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+)
+
+//TierIPPools IP pool names keyed by cluster tier, e.g. prod or trial
+type TierIPPools map[string]string
+
+//ParseTierIPPools Parse a list of tier=pool pairs into TierIPPools
+func ParseTierIPPools(pairs []string) (TierIPPools, error) {
+	tierPools := TierIPPools{}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New(fmt.Sprintf("invalid tier ip pool %s, expected format tier=pool", pair))
+		}
+		tierPools[parts[0]] = parts[1]
+	}
+	return tierPools, nil
+}
+
+//Pool Get the IP pool clusters of a tier draw their IP address from
+func (t TierIPPools) Pool(tier string) (string, error) {
+	pool, ok := t[tier]
+	if !ok {
+		return "", &smtpdetails.NotExistError{Message: fmt.Sprintf("no ip pool declared for tier %s", tier)}
+	}
+	return pool, nil
+}
+
+//WithIPPool Assign sub users created by Create an IP address from the named IP pool
+func WithIPPool(pool string) ClientOption {
+	return func(c *Client) {
+		c.ipPool = pool
+	}
+}
+
+//ListIPAddresses List the IP addresses of the SendGrid account ordered by IP
+func (c *Client) ListIPAddresses() ([]*IPAddress, error) {
+	ips, err := c.sendgridClient.ListIPAddresses()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ip addresses")
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		return ips[i].IP < ips[j].IP
+	})
+	return ips, nil
+}
+
+//ListIPPools List the IP pools of the SendGrid account
+func (c *Client) ListIPPools() ([]*IPPool, error) {
+	pools, err := c.sendgridClient.ListIPPools()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ip pools")
+	}
+	return pools, nil
+}
+
+//CreateIPPool Create an IP pool in the SendGrid account
+func (c *Client) CreateIPPool(name string) (*IPPool, error) {
+	pool, err := c.sendgridClient.CreateIPPool(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create ip pool %s", name)
+	}
+	return pool, nil
+}
+
+//AddIPToPool Add an IP address of the SendGrid account to an IP pool
+func (c *Client) AddIPToPool(name, ip string) error {
+	if err := c.sendgridClient.AddIPToPool(name, ip); err != nil {
+		return errors.Wrapf(err, "failed to add ip %s to pool %s", ip, name)
+	}
+	return nil
+}
+
+//RemoveIPFromPool Remove an IP address of the SendGrid account from an IP pool
+func (c *Client) RemoveIPFromPool(name, ip string) error {
+	if err := c.sendgridClient.RemoveIPFromPool(name, ip); err != nil {
+		if IsNotExistError(err) {
+			return &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return errors.Wrapf(err, "failed to remove ip %s from pool %s", ip, name)
+	}
+	return nil
+}
+
+//selectIPAddress Choose the IP address to assign to a new sub user, limited to the configured IP pool if one is set.
+//IP addresses that are warming up are only used if no other IP address is available, starting with the one that has
+//been warming up the longest.
+func (c *Client) selectIPAddress(ips []*IPAddress) (*IPAddress, error) {
+	candidates := ips
+	if c.ipPool != "" {
+		candidates = filterIPAddressesByPool(ips, c.ipPool)
+		if len(candidates) < 1 {
+			return nil, errors.New(fmt.Sprintf("no ip addresses found in pool %s to assign to sub user", c.ipPool))
+		}
+	}
+	if len(candidates) < 1 {
+		return nil, errors.New("no ip addresses found to assign to sub user")
+	}
+	var warming []*IPAddress
+	for _, ip := range candidates {
+		if !ip.Warmup {
+			return ip, nil
+		}
+		warming = append(warming, ip)
+	}
+	if c.excludeWarmupIPs {
+		return nil, errors.New("all candidate ip addresses are warming up and warming ip addresses are excluded")
+	}
+	sort.SliceStable(warming, func(i, j int) bool {
+		return warming[i].StartDate < warming[j].StartDate
+	})
+	c.logger.Warnf("all candidate ip addresses are warming up, using %s which started warming up the longest ago", warming[0].IP)
+	return warming[0], nil
+}
+
+func filterIPAddressesByPool(ips []*IPAddress, pool string) []*IPAddress {
+	var filtered []*IPAddress
+	for _, ip := range ips {
+		for _, p := range ip.Pools {
+			if p == pool {
+				filtered = append(filtered, ip)
+				break
+			}
+		}
+	}
+	return filtered
+}
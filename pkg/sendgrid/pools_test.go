@@ -0,0 +1,164 @@
+package sendgrid
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
+func TestParseTierIPPools(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    TierIPPools
+		wantErr bool
+	}{
+		{
+			name:  "valid pairs",
+			pairs: []string{"prod=pool-prod", "trial=pool-trial"},
+			want:  TierIPPools{"prod": "pool-prod", "trial": "pool-trial"},
+		},
+		{
+			name:  "no pairs",
+			pairs: nil,
+			want:  TierIPPools{},
+		},
+		{
+			name:    "missing separator",
+			pairs:   []string{"prod"},
+			wantErr: true,
+		},
+		{
+			name:    "missing pool",
+			pairs:   []string{"prod="},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTierIPPools(tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTierIPPools() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTierIPPools() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTierIPPools_Pool(t *testing.T) {
+	tierPools := TierIPPools{"prod": "pool-prod"}
+	if got, err := tierPools.Pool("prod"); err != nil || got != "pool-prod" {
+		t.Errorf("Pool() got = %v, %v, want pool-prod", got, err)
+	}
+	if _, err := tierPools.Pool("trial"); !smtpdetails.IsNotExistError(err) {
+		t.Errorf("Pool() error = %v, want NotExistError", err)
+	}
+}
+
+func TestClient_selectIPAddress(t *testing.T) {
+	ips := []*IPAddress{
+		{IP: "127.0.0.1", Pools: []string{"trial"}},
+		{IP: "127.0.0.2", Pools: []string{"other", "prod"}},
+	}
+	warmingIPs := []*IPAddress{
+		{IP: "127.0.0.3", Warmup: true, StartDate: 200},
+		{IP: "127.0.0.4", Warmup: true, StartDate: 100},
+		{IP: "127.0.0.5", Warmup: false},
+	}
+	tests := []struct {
+		name             string
+		ipPool           string
+		excludeWarmupIPs bool
+		ips              []*IPAddress
+		want             *IPAddress
+		wantErr          bool
+	}{
+		{
+			name: "ip not warming up preferred",
+			ips:  warmingIPs,
+			want: warmingIPs[2],
+		},
+		{
+			name: "longest warming ip used when all are warming",
+			ips:  warmingIPs[:2],
+			want: warmingIPs[1],
+		},
+		{
+			name:             "warming ips excluded",
+			excludeWarmupIPs: true,
+			ips:              warmingIPs[:2],
+			wantErr:          true,
+		},
+		{
+			name: "first ip used without pool",
+			ips:  ips,
+			want: ips[0],
+		},
+		{
+			name:   "ip from pool used",
+			ipPool: "prod",
+			ips:    ips,
+			want:   ips[1],
+		},
+		{
+			name:    "no ip in pool",
+			ipPool:  "unknown",
+			ips:     ips,
+			wantErr: true,
+		},
+		{
+			name:    "no ips",
+			ips:     nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:           newMockLogger(),
+				ipPool:           tt.ipPool,
+				excludeWarmupIPs: tt.excludeWarmupIPs,
+			}
+			got, err := c.selectIPAddress(tt.ips)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("selectIPAddress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectIPAddress() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Create_WithIPPool(t *testing.T) {
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {
+		c.GetSubUserByUsernameFunc = func(username string) (*SubUser, error) {
+			return nil, &NotExistError{Message: "test"}
+		}
+		c.GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+			return []*APIKey{}, nil
+		}
+		c.ListIPAddressesFunc = func() ([]*IPAddress, error) {
+			return []*IPAddress{
+				{IP: "127.0.0.1", Pools: []string{"trial"}},
+				{IP: "127.0.0.2", Pools: []string{"prod"}},
+			}, nil
+		}
+	}).(*APIClientMock)
+	c, err := NewClient(sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithIPPool("prod"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := c.Create("test"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	calls := sendgridClient.CreateSubUserCalls()
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0].Ips, []string{"127.0.0.2"}) {
+		t.Errorf("Create() sub user calls = %+v, want ip 127.0.0.2", calls)
+	}
+}
@@ -0,0 +1,66 @@
+package sendgrid
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//Region A SendGrid data residency region, selecting both the API host and the SMTP host of the generated details
+type Region struct {
+	Name     string
+	APIHost  string
+	SMTPHost string
+}
+
+var (
+	//RegionGlobal The default SendGrid region
+	RegionGlobal = &Region{Name: "global", APIHost: APIHost, SMTPHost: ConnectionDetailsHost}
+	//RegionEU The SendGrid EU data residency region
+	RegionEU = &Region{Name: "eu", APIHost: APIHostEU, SMTPHost: ConnectionDetailsHostEU}
+	//Regions All known SendGrid regions
+	Regions = []*Region{RegionGlobal, RegionEU}
+)
+
+//GetRegion Find a region by it's name, a blank name returns the global region
+func GetRegion(name string) (*Region, error) {
+	if name == "" {
+		return RegionGlobal, nil
+	}
+	var names []string
+	for _, r := range Regions {
+		if strings.EqualFold(r.Name, name) {
+			return r, nil
+		}
+		names = append(names, r.Name)
+	}
+	return nil, errors.New(fmt.Sprintf("unknown region %s, expected one of %s", name, strings.Join(names, ", ")))
+}
+
+//WithRegion Use the API host of a region and output SMTP details pointing at the SMTP host of the region
+func WithRegion(region *Region) ClientOption {
+	return func(c *Client) {
+		c.apiHost = region.APIHost
+		c.smtpHost = region.SMTPHost
+	}
+}
+
+//WithAPIHost Send API requests to a custom host, e.g. a proxy or a fake SendGrid API, instead of the region API host
+func WithAPIHost(apiHost string) ClientOption {
+	return func(c *Client) {
+		c.apiHost = apiHost
+	}
+}
+
+func validateAPIHost(apiHost string) error {
+	u, err := url.Parse(apiHost)
+	if err != nil {
+		return errors.Wrapf(err, "invalid api host %s", apiHost)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New(fmt.Sprintf("invalid api host %s, expected an http or https url", apiHost))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package sendgrid
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetRegion(t *testing.T) {
+	tests := []struct {
+		name       string
+		regionName string
+		want       *Region
+		wantErr    bool
+	}{
+		{name: "blank name is global", regionName: "", want: RegionGlobal},
+		{name: "global", regionName: "global", want: RegionGlobal},
+		{name: "eu is case insensitive", regionName: "EU", want: RegionEU},
+		{name: "unknown region", regionName: "us-west", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetRegion(tt.regionName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRegion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetRegion() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDefaultClient_APIHost(t *testing.T) {
+	if err := os.Setenv(EnvAPIKey, testAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(EnvAPIKey)
+	tests := []struct {
+		name         string
+		opts         []ClientOption
+		wantAPIHost  string
+		wantSMTPHost string
+		wantErr      bool
+	}{
+		{
+			name:         "default region",
+			wantAPIHost:  APIHost,
+			wantSMTPHost: ConnectionDetailsHost,
+		},
+		{
+			name:         "eu region",
+			opts:         []ClientOption{WithRegion(RegionEU)},
+			wantAPIHost:  APIHostEU,
+			wantSMTPHost: ConnectionDetailsHostEU,
+		},
+		{
+			name:         "api host overrides region api host",
+			opts:         []ClientOption{WithRegion(RegionEU), WithAPIHost("http://127.0.0.1:8080")},
+			wantAPIHost:  "http://127.0.0.1:8080",
+			wantSMTPHost: ConnectionDetailsHostEU,
+		},
+		{
+			name:    "invalid api host",
+			opts:    []ClientOption{WithAPIHost("127.0.0.1:8080")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewDefaultClient(newMockLogger(), tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewDefaultClient() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			restClient := c.sendgridClient.(*BackendAPIClient).restClient.(*BackendRESTClient)
+			if restClient.apiHost != tt.wantAPIHost {
+				t.Errorf("NewDefaultClient() api host = %v, want %v", restClient.apiHost, tt.wantAPIHost)
+			}
+			if got := c.connectionDetails("test", "test").Host; got != tt.wantSMTPHost {
+				t.Errorf("NewDefaultClient() smtp host = %v, want %v", got, tt.wantSMTPHost)
+			}
+		})
+	}
+}
+
+func TestNewClientWithAPIKey_Options(t *testing.T) {
+	applied := 0
+	plan := &Plan{}
+	c, err := NewClientWithAPIKey(testAPIKey, newMockLogger(), func(c *Client) { applied++ }, WithRegion(RegionEU), WithRateLimitRetries(3), WithDryRun(plan))
+	if err != nil {
+		t.Fatalf("NewClientWithAPIKey() error = %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("NewClientWithAPIKey() applied option %d times, want 1", applied)
+	}
+	dryRunClient, ok := c.sendgridClient.(*DryRunAPIClient)
+	if !ok {
+		t.Fatalf("NewClientWithAPIKey() api client = %T, want *DryRunAPIClient", c.sendgridClient)
+	}
+	restClient := dryRunClient.APIClient.(*BackendAPIClient).restClient.(*BackendRESTClient)
+	if restClient.apiHost != APIHostEU {
+		t.Errorf("NewClientWithAPIKey() api host = %v, want %v", restClient.apiHost, APIHostEU)
+	}
+	if restClient.rateLimitRetries != 3 {
+		t.Errorf("NewClientWithAPIKey() rate limit retries = %d, want 3", restClient.rateLimitRetries)
+	}
+}
@@ -32,6 +32,49 @@ func buildCreateAPIKeyBody(id string, scopes []string) ([]byte, error) {
 	return marshalRequestBody(&body, "api key create")
 }
 
+func buildSubUserMonitorBody(email string, frequency int) ([]byte, error) {
+	body := Monitor{
+		Email:     email,
+		Frequency: frequency,
+	}
+	return marshalRequestBody(&body, "sub user monitor")
+}
+
+func buildEventWebhookTestBody(url string) ([]byte, error) {
+	body := struct {
+		URL string `json:"url"`
+	}{
+		URL: url,
+	}
+	return marshalRequestBody(&body, "event webhook test")
+}
+
+func buildCreateIPPoolBody(name string) ([]byte, error) {
+	body := struct {
+		Name string `json:"name"`
+	}{
+		Name: name,
+	}
+	return marshalRequestBody(&body, "ip pool create")
+}
+
+func buildAddIPToPoolBody(ip string) ([]byte, error) {
+	return buildIPBody(ip, "ip pool add ip")
+}
+
+func buildStartIPWarmupBody(ip string) ([]byte, error) {
+	return buildIPBody(ip, "ip warmup start")
+}
+
+func buildIPBody(ip, bodyDesc string) ([]byte, error) {
+	body := struct {
+		IP string `json:"ip"`
+	}{
+		IP: ip,
+	}
+	return marshalRequestBody(&body, bodyDesc)
+}
+
 func marshalRequestBody(body interface{}, bodyDesc string) ([]byte, error) {
 	bodyJSON, err := json.Marshal(body)
 	if err != nil {
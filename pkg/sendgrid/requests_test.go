@@ -76,3 +76,48 @@ func Test_buildCreateApiKeyBody(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildSubUserMonitorBody(t *testing.T) {
+	type args struct {
+		email     string
+		frequency int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "correct format",
+			args: args{
+				email:     "test@email.com",
+				frequency: 500,
+			},
+			want: []byte(`{"email":"test@email.com","frequency":500}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildSubUserMonitorBody(tt.args.email, tt.args.frequency)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildSubUserMonitorBody() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildSubUserMonitorBody() got = %v, want %v", string(got), string(tt.want))
+			}
+		})
+	}
+}
+
+func Test_buildEventWebhookTestBody(t *testing.T) {
+	got, err := buildEventWebhookTestBody("https://events.test.com/test")
+	if err != nil {
+		t.Fatalf("buildEventWebhookTestBody() error = %v", err)
+	}
+	want := []byte(`{"url":"https://events.test.com/test"}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildEventWebhookTestBody() got = %v, want %v", string(got), string(want))
+	}
+}
@@ -3,9 +3,12 @@ package sendgrid
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
 	"github.com/pkg/errors"
 	"github.com/sethvargo/go-password/password"
 	"github.com/sirupsen/logrus"
@@ -14,9 +17,12 @@ import (
 var (
 	//DefaultAPIKeyScopes The default API scopes given to the generated SendGrid API key
 	DefaultAPIKeyScopes = []string{"mail.send"}
+	//subUserUsernamePattern Characters allowed in a sub user username, cluster ids may already be email addresses
+	subUserUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]*$`)
 )
 
 var _ smtpdetails.Client = &Client{}
+var _ store.Inventory = &Client{}
 
 //Client Client used to generate new API keys for OpenShift clusters, abstracting sub user creation
 type Client struct {
@@ -24,25 +30,54 @@ type Client struct {
 	sendgridSubUserAPIKeyScopes []string
 	passwordGenerator           smtpdetails.PasswordGenerator
 	logger                      *logrus.Entry
+	subUserMonitor              *Monitor
+	eventWebhookURLTemplate     string
+	eventWebhookEvents          []string
+	ipPool                      string
+	excludeWarmupIPs            bool
+	apiHost                     string
+	smtpHost                    string
+	idPolicy                    *smtpdetails.IDPolicy
+	rateLimitRetries            int
 }
 
-//NewDefaultClient Create new client using API key from SENDGRID_API_KEY env var and the default SendGrid API host.
-func NewDefaultClient(logger *logrus.Entry) (*Client, error) {
-	passGen, err := password.NewGenerator(&password.GeneratorInput{})
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create default password generator")
-	}
+//ClientOption Optional configuration applied to a Client when it is created
+type ClientOption func(c *Client)
+
+//NewDefaultClient Create new client using API key from SENDGRID_API_KEY env var and the default SendGrid API host,
+//unless a region or API host is provided as an option.
+func NewDefaultClient(logger *logrus.Entry, opts ...ClientOption) (*Client, error) {
 	sendgridAPIKeyEnv := os.Getenv(EnvAPIKey)
 	if sendgridAPIKeyEnv == "" {
 		return nil, errors.New("SENDGRID_API_KEY env var must be defined")
 	}
-	sendgridRESTClient := NewBackendRESTClient(APIHost, sendgridAPIKeyEnv, logger)
+	return NewClientWithAPIKey(sendgridAPIKeyEnv, logger, opts...)
+}
+
+//NewClientWithAPIKey Create new client of the master account of an API key, using the default SendGrid API host
+//unless a region or API host is provided as an option.
+func NewClientWithAPIKey(apiKey string, logger *logrus.Entry, opts ...ClientOption) (*Client, error) {
+	passGen, err := password.NewGenerator(&password.GeneratorInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create default password generator")
+	}
+	sendgridRESTClient := NewBackendRESTClient(APIHost, apiKey, logger)
 	sendgridClient := NewBackendAPIClient(sendgridRESTClient, logger)
-	return NewClient(sendgridClient, DefaultAPIKeyScopes, passGen, logger.WithField(smtpdetails.LogFieldDetailProvider, ProviderName))
+	c, err := NewClient(sendgridClient, DefaultAPIKeyScopes, passGen, logger.WithField(smtpdetails.LogFieldDetailProvider, ProviderName), append([]ClientOption{WithAPIHost(APIHost)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	// the rest client is configured from the options once they are applied, before any request is sent
+	if err := validateAPIHost(c.apiHost); err != nil {
+		return nil, err
+	}
+	sendgridRESTClient.apiHost = c.apiHost
+	sendgridRESTClient.rateLimitRetries = c.rateLimitRetries
+	return c, nil
 }
 
 //NewClient Create new Client
-func NewClient(sendgridClient APIClient, apiKeyScopes []string, passGen smtpdetails.PasswordGenerator, logger *logrus.Entry) (*Client, error) {
+func NewClient(sendgridClient APIClient, apiKeyScopes []string, passGen smtpdetails.PasswordGenerator, logger *logrus.Entry, opts ...ClientOption) (*Client, error) {
 	if sendgridClient == nil {
 		return nil, errors.New("sendgridClient must be defined")
 	}
@@ -52,80 +87,153 @@ func NewClient(sendgridClient APIClient, apiKeyScopes []string, passGen smtpdeta
 	if passGen == nil {
 		return nil, errors.New("passGen must be defined")
 	}
-	return &Client{
+	c := &Client{
 		sendgridClient:              sendgridClient,
 		sendgridSubUserAPIKeyScopes: apiKeyScopes,
 		passwordGenerator:           passGen,
 		logger:                      logger,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.eventWebhookURLTemplate != "" {
+		// fail before any sub user is created rather than after
+		url, err := RenderEventWebhookURL(c.eventWebhookURLTemplate, "test")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := NewEventWebhookSettings(url, c.eventWebhookEvents); err != nil {
+			return nil, errors.Wrap(err, "invalid event webhook settings")
+		}
+	}
+	return c, nil
+}
+
+//WithAPIKeyScopes Create sub user API keys with custom scopes instead of the scopes the Client was created with,
+//ignored if blank
+func WithAPIKeyScopes(scopes []string) ClientOption {
+	return func(c *Client) {
+		if len(scopes) > 0 {
+			c.sendgridSubUserAPIKeyScopes = scopes
+		}
+	}
+}
+
+//NewIDPolicy Create an id policy validating cluster ids against the rules of sub user usernames, without normalising
+//them. Clients without a policy use cluster ids verbatim.
+func NewIDPolicy() *smtpdetails.IDPolicy {
+	return &smtpdetails.IDPolicy{MaxLength: SubUserUsernameMaxLength, Allowed: subUserUsernamePattern}
+}
+
+//WithIDPolicy Map cluster ids to sub user usernames with a custom id policy, ignored if nil
+func WithIDPolicy(policy *smtpdetails.IDPolicy) ClientOption {
+	return func(c *Client) {
+		if policy != nil {
+			c.idPolicy = policy
+		}
+	}
 }
 
 //Create Generate new SendGrid sub user and API key for a cluster with it's ID
 func (c *Client) Create(id string) (*smtpdetails.SMTPDetails, error) {
+	return c.create(id, nil)
+}
+
+//create Generate the sub user and API key of a cluster. check is called with the existing sub user, nil if there is
+//none, and its API keys once the cluster is known to have no API key, before anything is created or changed.
+func (c *Client) create(id string, check func(subuser *SubUser, apiKeys []*APIKey) error) (*smtpdetails.SMTPDetails, error) {
+	id, username, err := c.username(id)
+	if err != nil {
+		return nil, err
+	}
 	// check if sub user exists
-	c.logger.Infof("checking if sub user %s exists", id)
-	subuser, err := c.sendgridClient.GetSubUserByUsername(id)
+	c.logger.Infof("checking if sub user %s exists", username)
+	subuser, err := c.sendgridClient.GetSubUserByUsername(username)
 	if err != nil && !IsNotExistError(err) {
 		return nil, errors.Wrapf(err, "failed to check if sub user already exists")
 	}
-	// sub user doesn't exist, create it
-	if subuser == nil {
-		c.logger.Debugf("could not find existing user with username %s, creating it", id)
+	var apiKeys []*APIKey
+	if subuser != nil {
+		// check if api key for sub user exists, before the settings of the sub user are changed
+		c.logger.Infof("checking if api key for sub user %s already exists", username)
+		apiKeys, err = c.sendgridClient.GetAPIKeysForSubUser(username)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get list of api keys")
+		}
+		if apiKey := FindAPIKeyByName(apiKeys, username); apiKey != nil {
+			return nil, &smtpdetails.AlreadyExistsError{Message: fmt.Sprintf("api key %s for sub user %s already exists", apiKey.Name, subuser.Username)}
+		}
+	}
+	if check != nil {
+		if err := check(subuser, apiKeys); err != nil {
+			return nil, err
+		}
+	}
+	if subuser != nil {
+		c.logger.Infof("sub user %s already exists, skipping creation", username)
+	} else {
+		// sub user doesn't exist, create it
+		c.logger.Debugf("could not find existing user with username %s, creating it", username)
 		// get an ip address from the sendgrid account to assign to the sub user
 		ips, err := c.sendgridClient.ListIPAddresses()
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list ip addresses")
 		}
-		if len(ips) < 1 {
-			return nil, errors.New("no ip addresses found to assign to sub user")
+		ipAddr, err := c.selectIPAddress(ips)
+		if err != nil {
+			return nil, err
 		}
-		ipAddr := ips[0]
-		// if id isn't already an email, lazily convert it to one
-		idEmail := id
-		if !strings.Contains(id, "@") {
-			idEmail = fmt.Sprintf("%s@email.com", id)
+		c.logger.Debugf("assigning ip address %s to new sub user %s", ipAddr.IP, username)
+		// if username isn't already an email, lazily convert it to one
+		idEmail := username
+		if !strings.Contains(username, "@") {
+			idEmail = fmt.Sprintf("%s@email.com", username)
 		}
 		// handle password generation
-		c.logger.Debugf("generating password for new sub user %s", id)
+		c.logger.Debugf("generating password for new sub user %s", username)
 		password, err := c.passwordGenerator.Generate(10, 1, 1, false, true)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to generate password for sub user")
 		}
-		subuser, err = c.sendgridClient.CreateSubUser(id, idEmail, password, []string{ipAddr.IP})
+		subuser, err = c.sendgridClient.CreateSubUser(username, idEmail, password, []string{ipAddr.IP})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create sub user")
 		}
-		c.logger.Infof("sub user created with details, username=%s email=%s password=%s", id, idEmail, password)
-	} else {
-		c.logger.Infof("sub user %s already exists, skipping creation", id)
+		c.logger.Infof("sub user created with details, username=%s email=%s password=%s", username, idEmail, password)
 	}
-	// check if api key for sub user exists
-	c.logger.Infof("checking if api key for sub user %s already exists", id)
-	apiKeys, err := c.sendgridClient.GetAPIKeysForSubUser(id)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get list of api keys")
+	if c.subUserMonitor != nil {
+		c.logger.Infof("attaching monitor %s to sub user %s", c.subUserMonitor.Email, id)
+		if _, err := c.setSubUserMonitor(subuser.Username, c.subUserMonitor.Email, c.subUserMonitor.Frequency); err != nil {
+			return nil, errors.Wrapf(err, "failed to attach monitor to sub user %s", id)
+		}
 	}
-	apiKey := FindAPIKeyByName(apiKeys, id)
-	if apiKey != nil {
-		return nil, &smtpdetails.AlreadyExistsError{Message: fmt.Sprintf("api key %s for sub user %s already exists", apiKey.Name, subuser.Username)}
+	if c.eventWebhookURLTemplate != "" {
+		c.logger.Infof("configuring event webhook for sub user %s", id)
+		if _, err := c.setSubUserEventWebhook(id, subuser.Username, c.eventWebhookURLTemplate, c.eventWebhookEvents); err != nil {
+			return nil, errors.Wrapf(err, "failed to configure event webhook for sub user %s", id)
+		}
 	}
 	// api key doesn't exist, create it
-	c.logger.Infof("no api key found, creating api key for sub user %s", id)
-	apiKey, err = c.sendgridClient.CreateAPIKeyForSubUser(subuser.Username, DefaultAPIKeyScopes)
+	c.logger.Infof("no api key found, creating api key for sub user %s", username)
+	apiKey, err := c.sendgridClient.CreateAPIKeyForSubUser(subuser.Username, c.sendgridSubUserAPIKeyScopes)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create api key for sub user")
 	}
-	return defaultConnectionDetails(apiKey.Name, apiKey.Key), nil
+	return c.connectionDetails(apiKey.Name, apiKey.Key), nil
 }
 
 //Get Retrieve the name of the SendGrid API key associated with an OpenShift cluster by it's ID
 func (c *Client) Get(id string) (*smtpdetails.SMTPDetails, error) {
-	subuser, err := c.sendgridClient.GetSubUserByUsername(id)
+	id, username, err := c.username(id)
+	if err != nil {
+		return nil, err
+	}
+	subuser, err := c.sendgridClient.GetSubUserByUsername(username)
 	if err != nil {
 		if IsNotExistError(err) {
 			return nil, &smtpdetails.NotExistError{Message: err.Error()}
 		}
-		return nil, errors.Wrapf(err, "failed to get user by username, %s", id)
+		return nil, errors.Wrapf(err, "failed to get user by username, %s", username)
 	}
 	c.logger.Debugf("found user with username %s, id=%d email=%s disabled=%t", subuser.Username, subuser.ID, subuser.Email, subuser.Disabled)
 	apiKeys, err := c.sendgridClient.GetAPIKeysForSubUser(subuser.Username)
@@ -133,7 +241,7 @@ func (c *Client) Get(id string) (*smtpdetails.SMTPDetails, error) {
 		return nil, errors.Wrapf(err, "failed to get api keys for sub user with username %s", subuser.Username)
 	}
 	if len(apiKeys) < 1 {
-		return nil, errors.New(fmt.Sprintf("no api keys found for sub user %s", id))
+		return nil, &smtpdetails.NotExistError{Message: fmt.Sprintf("no api keys found for sub user %s", id)}
 	}
 	var clusterAPIKey *APIKey
 	for _, k := range apiKeys {
@@ -145,21 +253,25 @@ func (c *Client) Get(id string) (*smtpdetails.SMTPDetails, error) {
 	if clusterAPIKey == nil {
 		return nil, &smtpdetails.NotExistError{Message: fmt.Sprintf("api key with id %s does not exist for sub user %s", subuser.Username, subuser.Username)}
 	}
-	return defaultConnectionDetails(clusterAPIKey.Name, clusterAPIKey.Key), nil
+	return c.connectionDetails(clusterAPIKey.Name, clusterAPIKey.Key), nil
 }
 
 //Delete Delete the SendGrid sub user associated with a cluster by the cluster ID
 func (c *Client) Delete(id string) error {
-	c.logger.Debugf("checking if sub user %s exists", id)
-	subuser, err := c.sendgridClient.GetSubUserByUsername(id)
+	id, username, err := c.username(id)
+	if err != nil {
+		return err
+	}
+	c.logger.Debugf("checking if sub user %s exists", username)
+	subuser, err := c.sendgridClient.GetSubUserByUsername(username)
 	if err != nil {
 		if IsNotExistError(err) {
 			return &smtpdetails.NotExistError{Message: err.Error()}
 		}
 		return errors.Wrapf(err, "failed to check if sub user exists")
 	}
-	if subuser.Username != id {
-		return errors.New(fmt.Sprintf("found user does not have expected username, expected=%s found=%s", id, subuser.Username))
+	if subuser.Username != username {
+		return errors.New(fmt.Sprintf("found user does not have expected username, expected=%s found=%s", username, subuser.Username))
 	}
 	c.logger.Debugf("sub user %s exists, deleting it", subuser.Username)
 	if err := c.sendgridClient.DeleteSubUser(subuser.Username); err != nil {
@@ -170,16 +282,20 @@ func (c *Client) Delete(id string) error {
 
 //Refresh deletes the API key associated with a subuser and generates a new key
 func (c *Client) Refresh(id string) (*smtpdetails.SMTPDetails, error) {
-	c.logger.Debugf("checking if sub user %s exists", id)
-	subuser, err := c.sendgridClient.GetSubUserByUsername(id)
+	id, username, err := c.username(id)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debugf("checking if sub user %s exists", username)
+	subuser, err := c.sendgridClient.GetSubUserByUsername(username)
 	if err != nil {
 		if IsNotExistError(err) {
 			return nil, &smtpdetails.NotExistError{Message: err.Error()}
 		}
 		return nil, errors.Wrapf(err, "check to see if sub user exists failed")
 	}
-	if subuser.Username != id {
-		return nil, errors.New(fmt.Sprintf("found user does not have expected username, expected=%s found=%s", id, subuser.Username))
+	if subuser.Username != username {
+		return nil, errors.New(fmt.Sprintf("found user does not have expected username, expected=%s found=%s", username, subuser.Username))
 	}
 	c.logger.Debugf("sub user %s exists, finding user keys to check for key to delete", subuser.Username)
 	apiKeys, err := c.sendgridClient.GetAPIKeysForSubUser(subuser.Username)
@@ -196,11 +312,89 @@ func (c *Client) Refresh(id string) (*smtpdetails.SMTPDetails, error) {
 	}
 	c.logger.Infof("creating api key for sub user %s", id)
 	var apiKey *APIKey
-	apiKey, err = c.sendgridClient.CreateAPIKeyForSubUser(subuser.Username, DefaultAPIKeyScopes)
+	apiKey, err = c.sendgridClient.CreateAPIKeyForSubUser(subuser.Username, c.sendgridSubUserAPIKeyScopes)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create api key for sub user")
 	}
-	return defaultConnectionDetails(apiKey.Name, apiKey.Key), nil
+	return c.connectionDetails(apiKey.Name, apiKey.Key), nil
+}
+
+//List Retrieve the IDs of all clusters with a SendGrid sub user, sorted by ID
+func (c *Client) List() ([]string, error) {
+	subusers, err := c.sendgridClient.ListSubUsers(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list sub users")
+	}
+	ids := make([]string, 0, len(subusers))
+	for _, subuser := range subusers {
+		// sub users not following the id policy, e.g. without its prefix, do not belong to a cluster
+		if id, ok := c.policy().ClusterID(subuser.Username); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+//SubUserCount Count the sub users of the master account, including those not belonging to a cluster
+func (c *Client) SubUserCount() (int, error) {
+	subusers, err := c.sendgridClient.ListSubUsers(nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list sub users")
+	}
+	return len(subusers), nil
+}
+
+//Describe Retrieve the SendGrid sub user and API key of a cluster by it's ID
+func (c *Client) Describe(id string) (*store.Record, error) {
+	id, username, err := c.username(id)
+	if err != nil {
+		return nil, err
+	}
+	subuser, err := c.sendgridClient.GetSubUserByUsername(username)
+	if err != nil {
+		if IsNotExistError(err) {
+			return nil, &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return nil, errors.Wrapf(err, "failed to get user by username, %s", username)
+	}
+	apiKeys, err := c.sendgridClient.GetAPIKeysForSubUser(subuser.Username)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get api keys for sub user with username %s", subuser.Username)
+	}
+	record := &store.Record{ClusterID: id, Provider: ProviderName, SubUser: subuser.Username}
+	if apiKey := FindAPIKeyByName(apiKeys, subuser.Username); apiKey != nil {
+		record.KeyID = apiKey.ID
+		record.Scopes = apiKey.Scopes
+	}
+	return record, nil
+}
+
+//username Map a cluster id to its canonical form and the username of its sub user
+func (c *Client) username(id string) (string, string, error) {
+	policy := c.policy()
+	canonical, err := policy.Normalize(id)
+	if err != nil {
+		return "", "", err
+	}
+	return canonical, policy.Prefix + canonical, nil
+}
+
+//policy Id policy of the client, using ids verbatim if none is configured
+func (c *Client) policy() *smtpdetails.IDPolicy {
+	if c.idPolicy == nil {
+		return &smtpdetails.IDPolicy{}
+	}
+	return c.idPolicy
+}
+
+//connectionDetails SMTP details of an API key, pointing at the SMTP host of the configured region
+func (c *Client) connectionDetails(apiKeyID, apiKey string) *smtpdetails.SMTPDetails {
+	details := defaultConnectionDetails(apiKeyID, apiKey)
+	if c.smtpHost != "" {
+		details.Host = c.smtpHost
+	}
+	return details
 }
 
 func defaultConnectionDetails(apiKeyID, apiKey string) *smtpdetails.SMTPDetails {
@@ -209,6 +403,7 @@ func defaultConnectionDetails(apiKeyID, apiKey string) *smtpdetails.SMTPDetails
 		Host:     ConnectionDetailsHost,
 		Port:     ConnectionDetailsPort,
 		TLS:      ConnectionDetailsTLS,
+		TLSMode:  ConnectionDetailsTLSMode,
 		Username: ConnectionDetailsUsername,
 		Password: apiKey,
 	}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/integr8ly/smtp-service/pkg/store"
 
 	"github.com/sirupsen/logrus"
 )
@@ -46,6 +47,37 @@ func newMockIPAddress() *IPAddress {
 	}
 }
 
+func newMockMonitor() *Monitor {
+	return &Monitor{
+		Email:     "monitor@email.com",
+		Frequency: 1000,
+	}
+}
+
+func newMockEventWebhookSettings() *EventWebhookSettings {
+	return &EventWebhookSettings{
+		Enabled:   true,
+		URL:       "https://events.test.com/test",
+		Delivered: true,
+		Bounce:    true,
+	}
+}
+
+func newMockWarmupIP() *WarmupIP {
+	return &WarmupIP{
+		IP:        "127.0.0.1",
+		StartDate: 1600000000,
+	}
+}
+
+func newMockCredits() *Credits {
+	return &Credits{
+		Remain: 900,
+		Total:  1000,
+		Used:   100,
+	}
+}
+
 func newMockSMTPDetails() *smtpdetails.SMTPDetails {
 	return defaultConnectionDetails("test", "test")
 }
@@ -73,6 +105,54 @@ func newMockAPIClient(modifyFn func(c *APIClientMock)) APIClient {
 		ListSubUsersFunc: func(query map[string]string) (users []*SubUser, e error) {
 			return []*SubUser{newMockSubUser()}, nil
 		},
+		GetSubUserMonitorFunc: func(username string) (monitor *Monitor, e error) {
+			return newMockMonitor(), nil
+		},
+		CreateSubUserMonitorFunc: func(username string, email string, frequency int) (monitor *Monitor, e error) {
+			return &Monitor{Email: email, Frequency: frequency}, nil
+		},
+		UpdateSubUserMonitorFunc: func(username string, email string, frequency int) (monitor *Monitor, e error) {
+			return &Monitor{Email: email, Frequency: frequency}, nil
+		},
+		DeleteSubUserMonitorFunc: func(username string) error {
+			return nil
+		},
+		GetEventWebhookSettingsFunc: func(username string) (settings *EventWebhookSettings, e error) {
+			return newMockEventWebhookSettings(), nil
+		},
+		UpdateEventWebhookSettingsFunc: func(username string, settings *EventWebhookSettings) (updated *EventWebhookSettings, e error) {
+			return settings, nil
+		},
+		TestEventWebhookFunc: func(username string, url string) error {
+			return nil
+		},
+		ListIPPoolsFunc: func() (pools []*IPPool, e error) {
+			return []*IPPool{{Name: "test"}}, nil
+		},
+		CreateIPPoolFunc: func(name string) (pool *IPPool, e error) {
+			return &IPPool{Name: name}, nil
+		},
+		AddIPToPoolFunc: func(name string, ip string) error {
+			return nil
+		},
+		RemoveIPFromPoolFunc: func(name string, ip string) error {
+			return nil
+		},
+		ListWarmupIPsFunc: func() (warmupIPs []*WarmupIP, e error) {
+			return []*WarmupIP{newMockWarmupIP()}, nil
+		},
+		GetCreditsFunc: func() (credits *Credits, e error) {
+			return newMockCredits(), nil
+		},
+		GetIPWarmupStatusFunc: func(ip string) (warmupIP *WarmupIP, e error) {
+			return newMockWarmupIP(), nil
+		},
+		StartIPWarmupFunc: func(ip string) (warmupIP *WarmupIP, e error) {
+			return newMockWarmupIP(), nil
+		},
+		StopIPWarmupFunc: func(ip string) error {
+			return nil
+		},
 	}
 	modifyFn(apiClient)
 	return apiClient
@@ -274,11 +354,12 @@ func TestClient_Get(t *testing.T) {
 		id string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *smtpdetails.SMTPDetails
-		wantErr bool
+		name         string
+		fields       fields
+		args         args
+		want         *smtpdetails.SMTPDetails
+		wantErr      bool
+		wantNotExist bool
 	}{
 		{
 			name: "successful get",
@@ -333,8 +414,9 @@ func TestClient_Get(t *testing.T) {
 				passwordGenerator:           mockPasswordGen,
 				logger:                      newMockLogger(),
 			},
-			args:    args{id: "test"},
-			wantErr: true,
+			args:         args{id: "test"},
+			wantErr:      true,
+			wantNotExist: true,
 		},
 		{
 			name: "retrieved api keys does not contain expected keys",
@@ -370,6 +452,9 @@ func TestClient_Get(t *testing.T) {
 				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantNotExist && !smtpdetails.IsNotExistError(err) {
+				t.Errorf("Get() error = %v, want smtpdetails.NotExistError", err)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Get() got = %v, want %v", got, tt.want)
 			}
@@ -546,6 +631,7 @@ func TestClient_Refresh(t *testing.T) {
 		sendgridSubUserAPIKeyScopes []string
 		passwordGenerator           smtpdetails.PasswordGenerator
 		logger                      *logrus.Entry
+		idPolicy                    *smtpdetails.IDPolicy
 	}
 	type args struct {
 		id string
@@ -717,6 +803,7 @@ func TestClient_Refresh(t *testing.T) {
 				Host:     "smtp.sendgrid.net",
 				Port:     587,
 				TLS:      true,
+				TLSMode:  smtpdetails.TLSModeStartTLS,
 				Username: "apikey",
 				Password: "",
 			},
@@ -766,10 +853,62 @@ func TestClient_Refresh(t *testing.T) {
 				Host:     "smtp.sendgrid.net",
 				Port:     587,
 				TLS:      true,
+				TLSMode:  smtpdetails.TLSModeStartTLS,
 				Username: "apikey",
 				Password: "",
 			},
 		},
+		{
+			name: "blank id should cause validation error with a validating id policy",
+			fields: fields{
+				sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+					c.GetSubUserByUsernameFunc = func(username string) (user *SubUser, err error) {
+						return nil, errors.New("sub user should not be requested")
+					}
+				}),
+				sendgridSubUserAPIKeyScopes: mockAPIScopes,
+				passwordGenerator:           mockPasswordGen,
+				logger:                      newMockLogger(),
+				idPolicy:                    NewIDPolicy(),
+			},
+			args:    args{id: ""},
+			wantErr: true,
+		},
+		{
+			name: "id policy should refresh the prefixed sub user",
+			fields: fields{
+				sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+					c.GetSubUserByUsernameFunc = func(username string) (user *SubUser, err error) {
+						if username != "rhmi-test" {
+							return nil, &NotExistError{Message: "test"}
+						}
+						return &SubUser{Username: username}, nil
+					}
+					c.GetAPIKeysForSubUserFunc = func(username string) (keys []*APIKey, err error) {
+						return nil, nil
+					}
+					c.CreateAPIKeyForSubUserFunc = func(username string, scopes []string) (key *APIKey, err error) {
+						return &APIKey{Name: username, Key: "key"}, nil
+					}
+				}),
+				sendgridSubUserAPIKeyScopes: mockAPIScopes,
+				passwordGenerator:           mockPasswordGen,
+				logger:                      newMockLogger(),
+				idPolicy:                    &smtpdetails.IDPolicy{Prefix: "rhmi-", Lowercase: true},
+			},
+			args: args{
+				id: "Test",
+			},
+			want: &smtpdetails.SMTPDetails{
+				ID:       "rhmi-test",
+				Host:     "smtp.sendgrid.net",
+				Port:     587,
+				TLS:      true,
+				TLSMode:  smtpdetails.TLSModeStartTLS,
+				Username: "apikey",
+				Password: "key",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -778,6 +917,7 @@ func TestClient_Refresh(t *testing.T) {
 				sendgridSubUserAPIKeyScopes: tt.fields.sendgridSubUserAPIKeyScopes,
 				passwordGenerator:           tt.fields.passwordGenerator,
 				logger:                      tt.fields.logger,
+				idPolicy:                    tt.fields.idPolicy,
 			}
 			got, err := c.Refresh(tt.args.id)
 			if (err != nil) != tt.wantErr {
@@ -790,3 +930,210 @@ func TestClient_Refresh(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		sendgridClient APIClient
+		want           []string
+		wantErr        bool
+	}{
+		{
+			name: "should return sorted sub user usernames",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.ListSubUsersFunc = func(query map[string]string) (users []*SubUser, e error) {
+					return []*SubUser{{Username: "cluster-b"}, {Username: "cluster-a"}}, nil
+				}
+			}),
+			want: []string{"cluster-a", "cluster-b"},
+		},
+		{
+			name: "should return empty list when there are no sub users",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.ListSubUsersFunc = func(query map[string]string) (users []*SubUser, e error) {
+					return nil, nil
+				}
+			}),
+			want: []string{},
+		},
+		{
+			name: "should fail when listing sub users fails",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.ListSubUsersFunc = func(query map[string]string) (users []*SubUser, e error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(tt.sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := c.List()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("List() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Describe(t *testing.T) {
+	tests := []struct {
+		name           string
+		sendgridClient APIClient
+		want           *store.Record
+		wantErr        bool
+		wantNotExist   bool
+	}{
+		{
+			name: "should describe sub user and api key",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetAPIKeysForSubUserFunc = func(username string) (keys []*APIKey, e error) {
+					return []*APIKey{{ID: "other", Name: "other"}, {ID: "key", Name: username, Scopes: mockAPIScopes}}, nil
+				}
+			}),
+			want: &store.Record{ClusterID: "test", Provider: ProviderName, SubUser: "test", KeyID: "key", Scopes: mockAPIScopes},
+		},
+		{
+			name: "should describe sub user without api key",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetAPIKeysForSubUserFunc = func(username string) (keys []*APIKey, e error) {
+					return nil, nil
+				}
+			}),
+			want: &store.Record{ClusterID: "test", Provider: ProviderName, SubUser: "test"},
+		},
+		{
+			name: "should return not exist error when sub user does not exist",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetSubUserByUsernameFunc = func(username string) (user *SubUser, e error) {
+					return nil, &NotExistError{Message: "test"}
+				}
+			}),
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "should fail when getting api keys fails",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetAPIKeysForSubUserFunc = func(username string) (keys []*APIKey, e error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(tt.sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := c.Describe("test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Describe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if smtpdetails.IsNotExistError(err) != tt.wantNotExist {
+				t.Fatalf("Describe() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Describe() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   []string
+	}{
+		{
+			name:   "should override scopes",
+			scopes: []string{"mail.send", "stats.read"},
+			want:   []string{"mail.send", "stats.read"},
+		},
+		{
+			name: "should keep scopes when blank",
+			want: mockAPIScopes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotScopes []string
+			apiClient := newMockAPIClient(func(c *APIClientMock) {
+				c.CreateAPIKeyForSubUserFunc = func(username string, scopes []string) (key *APIKey, e error) {
+					gotScopes = scopes
+					return newMockAPIKey(), nil
+				}
+				c.DeleteAPIKeyForSubUserFunc = func(id string, username string) error {
+					return nil
+				}
+			})
+			c, err := NewClient(apiClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithAPIKeyScopes(tt.scopes))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := c.Refresh(newMockSubUser().Username); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(gotScopes, tt.want) {
+				t.Fatalf("api key created with scopes %v, want %v", gotScopes, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithIDPolicy(t *testing.T) {
+	policy := NewIDPolicy()
+	policy.Lowercase = true
+	policy.Prefix = "rhmi-"
+	var looked []string
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {
+		c.GetSubUserByUsernameFunc = func(username string) (*SubUser, error) {
+			looked = append(looked, username)
+			return &SubUser{Username: username}, nil
+		}
+		c.GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+			return []*APIKey{{ID: "key", Name: username}}, nil
+		}
+		c.ListSubUsersFunc = func(query map[string]string) ([]*SubUser, error) {
+			return []*SubUser{{Username: "rhmi-b"}, {Username: "other"}, {Username: "rhmi-a"}}, nil
+		}
+	})
+	c, err := NewClient(sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithIDPolicy(policy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	details, err := c.Get("MyCluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if details.ID != "rhmi-mycluster" || !reflect.DeepEqual(looked, []string{"rhmi-mycluster"}) {
+		t.Fatalf("expected sub user rhmi-mycluster to be used, got %v and key %s", looked, details.ID)
+	}
+	record, err := c.Describe("MyCluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record.ClusterID != "mycluster" || record.SubUser != "rhmi-mycluster" {
+		t.Fatalf("unexpected record %v", record)
+	}
+	ids, err := c.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b"}) {
+		t.Fatalf("expected sub users without the prefix to be skipped, got %v", ids)
+	}
+	if _, err := c.Get("my cluster"); !smtpdetails.IsValidationError(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
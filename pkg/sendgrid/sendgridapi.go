@@ -3,6 +3,7 @@ package sendgrid
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/pkg/errors"
 	"github.com/sendgrid/rest"
@@ -16,6 +17,18 @@ var _ APIClient = &BackendAPIClient{}
 type APIClient interface {
 	// ip addresses
 	ListIPAddresses() ([]*IPAddress, error)
+	// credits
+	GetCredits() (*Credits, error)
+	// ip pools
+	ListIPPools() ([]*IPPool, error)
+	CreateIPPool(name string) (*IPPool, error)
+	AddIPToPool(name, ip string) error
+	RemoveIPFromPool(name, ip string) error
+	// ip warmup
+	ListWarmupIPs() ([]*WarmupIP, error)
+	GetIPWarmupStatus(ip string) (*WarmupIP, error)
+	StartIPWarmup(ip string) (*WarmupIP, error)
+	StopIPWarmup(ip string) error
 	// api keys
 	GetAPIKeysForSubUser(username string) ([]*APIKey, error)
 	CreateAPIKeyForSubUser(username string, scopes []string) (*APIKey, error)
@@ -25,6 +38,15 @@ type APIClient interface {
 	DeleteSubUser(username string) error
 	ListSubUsers(query map[string]string) ([]*SubUser, error)
 	GetSubUserByUsername(username string) (*SubUser, error)
+	// sub user monitors
+	GetSubUserMonitor(username string) (*Monitor, error)
+	CreateSubUserMonitor(username, email string, frequency int) (*Monitor, error)
+	UpdateSubUserMonitor(username, email string, frequency int) (*Monitor, error)
+	DeleteSubUserMonitor(username string) error
+	// event webhooks
+	GetEventWebhookSettings(username string) (*EventWebhookSettings, error)
+	UpdateEventWebhookSettings(username string, settings *EventWebhookSettings) (*EventWebhookSettings, error)
+	TestEventWebhook(username, url string) error
 }
 
 //apiKeysListResponse A fix for the irregular api keys list response, with format { "results": [] }
@@ -50,6 +72,9 @@ func (c *BackendAPIClient) ListIPAddresses() ([]*IPAddress, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list ip addresses")
 	}
+	if listResp.StatusCode != 200 {
+		return nil, newAPIError(listResp, 200)
+	}
 	var ips []*IPAddress
 	if err = json.Unmarshal([]byte(listResp.Body), &ips); err != nil {
 		return nil, errors.Wrapf(err, "failed to unmarshal ip address response, content=%s", listResp.Body)
@@ -68,6 +93,9 @@ func (c *BackendAPIClient) GetAPIKeysForSubUser(username string) ([]*APIKey, err
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list api keys for user %s", username)
 	}
+	if listResp.StatusCode != 200 {
+		return nil, newAPIError(listResp, 200)
+	}
 	var apiKeysResp *apiKeysListResponse
 	if err := json.Unmarshal([]byte(listResp.Body), &apiKeysResp); err != nil {
 		return nil, errors.Wrapf(err, "failed to unmarshal api keys response, content=%s", listResp.Body)
@@ -124,7 +152,7 @@ func (c *BackendAPIClient) CreateSubUser(id, email, password string, ips []strin
 		return nil, errors.Wrapf(err, "failed to create sub user %s", id)
 	}
 	if createResp.StatusCode != 201 {
-		return nil, errors.New(fmt.Sprintf("non-201 status code returned, code=%d body=%s", createResp.StatusCode, createResp.Body))
+		return nil, newAPIError(createResp, 201)
 	}
 	var subuser *SubUser
 	if err = json.Unmarshal([]byte(createResp.Body), &subuser); err != nil {
@@ -145,7 +173,7 @@ func (c *BackendAPIClient) DeleteSubUser(username string) error {
 		return errors.Wrapf(err, "failed to delete sub user %s", username)
 	}
 	if deleteResp.StatusCode != 204 {
-		return errors.New(fmt.Sprintf("non-204 status code returned, code=%d body=%s", deleteResp.StatusCode, deleteResp.Body))
+		return newAPIError(deleteResp, 204)
 	}
 	return nil
 }
@@ -162,26 +190,45 @@ func (c *BackendAPIClient) DeleteAPIKeyForSubUser(keyID, keyName string) error {
 		return errors.Wrapf(err, "failed to delete key %s", keyID)
 	}
 	if deleteResp.StatusCode != 204 {
-		return errors.New(fmt.Sprintf("non-204 status code returned, code=%d body=%s", deleteResp.StatusCode, deleteResp.Body))
+		return newAPIError(deleteResp, 204)
 	}
 	return nil
 }
 
-//ListSubUsers List all sub users for current authenticated user
+//ListSubUsers List all sub users for current authenticated user matching the query, paging through the results until
+//a page is not full. A limit in the query sets the page size.
 func (c *BackendAPIClient) ListSubUsers(query map[string]string) ([]*SubUser, error) {
-	if query == nil {
-		query = map[string]string{}
+	params := map[string]string{"limit": strconv.Itoa(SubUserListPageSize)}
+	for k, v := range query {
+		params[k] = v
 	}
-	listReq := c.restClient.BuildRequest(APIRouteSubUsers, rest.Get)
-	listResp, err := c.restClient.InvokeRequest(listReq)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list sub users")
+	limit, err := strconv.Atoi(params["limit"])
+	if err != nil || limit <= 0 {
+		return nil, errors.New(fmt.Sprintf("invalid sub user list limit %s", params["limit"]))
 	}
 	var subusers []*SubUser
-	if err = json.Unmarshal([]byte(listResp.Body), &subusers); err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal sub users, content=%s", listResp.Body)
+	for offset := 0; ; offset += limit {
+		listReq := c.restClient.BuildRequest(APIRouteSubUsers, rest.Get)
+		listReq.QueryParams = map[string]string{"offset": strconv.Itoa(offset)}
+		for k, v := range params {
+			listReq.QueryParams[k] = v
+		}
+		listResp, err := c.restClient.InvokeRequest(listReq)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list sub users")
+		}
+		if listResp.StatusCode != 200 {
+			return nil, newAPIError(listResp, 200)
+		}
+		var page []*SubUser
+		if err = json.Unmarshal([]byte(listResp.Body), &page); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal sub users, content=%s", listResp.Body)
+		}
+		subusers = append(subusers, page...)
+		if len(page) < limit {
+			return subusers, nil
+		}
 	}
-	return subusers, nil
 }
 
 //GetSubUserByUsername Get sub user of current authenticated user by username
@@ -205,3 +252,349 @@ func (c *BackendAPIClient) GetSubUserByUsername(username string) (*SubUser, erro
 	}
 	return foundUser, nil
 }
+
+//GetSubUserMonitor Get the monitor settings of a sub user by username
+func (c *BackendAPIClient) GetSubUserMonitor(username string) (*Monitor, error) {
+	if username == "" {
+		return nil, errors.New("username must be a non-empty string")
+	}
+	getReq := c.restClient.BuildRequest(fmt.Sprintf(APIRouteSubUserMonitor, username), rest.Get)
+	getResp, err := c.restClient.InvokeRequest(getReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get monitor for sub user %s", username)
+	}
+	if getResp.StatusCode == 404 {
+		return nil, &NotExistError{Message: fmt.Sprintf("monitor for sub user %s not found", username)}
+	}
+	if getResp.StatusCode != 200 {
+		return nil, newAPIError(getResp, 200)
+	}
+	var monitor *Monitor
+	if err = json.Unmarshal([]byte(getResp.Body), &monitor); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal monitor response, content=%s", getResp.Body)
+	}
+	return monitor, nil
+}
+
+//CreateSubUserMonitor Create monitor settings for a sub user by username
+func (c *BackendAPIClient) CreateSubUserMonitor(username, email string, frequency int) (*Monitor, error) {
+	return c.putSubUserMonitor(username, email, frequency, rest.Post)
+}
+
+//UpdateSubUserMonitor Update the existing monitor settings of a sub user by username
+func (c *BackendAPIClient) UpdateSubUserMonitor(username, email string, frequency int) (*Monitor, error) {
+	return c.putSubUserMonitor(username, email, frequency, rest.Put)
+}
+
+func (c *BackendAPIClient) putSubUserMonitor(username, email string, frequency int, method rest.Method) (*Monitor, error) {
+	if username == "" {
+		return nil, errors.New("username must be a non-empty string")
+	}
+	if email == "" {
+		return nil, errors.New("email must be a non-empty string")
+	}
+	if frequency < 1 {
+		return nil, errors.New("frequency must be greater than zero")
+	}
+	putReq := c.restClient.BuildRequest(fmt.Sprintf(APIRouteSubUserMonitor, username), method)
+	putBody, err := buildSubUserMonitorBody(email, frequency)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sub user monitor request body")
+	}
+	putReq.Body = putBody
+	putResp, err := c.restClient.InvokeRequest(putReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to set monitor for sub user %s", username)
+	}
+	if putResp.StatusCode != 200 {
+		return nil, newAPIError(putResp, 200)
+	}
+	var monitor *Monitor
+	if err = json.Unmarshal([]byte(putResp.Body), &monitor); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal monitor response, content=%s", putResp.Body)
+	}
+	return monitor, nil
+}
+
+//DeleteSubUserMonitor Delete the monitor settings of a sub user by username
+func (c *BackendAPIClient) DeleteSubUserMonitor(username string) error {
+	if username == "" {
+		return errors.New("username must be a non-empty string")
+	}
+	deleteReq := c.restClient.BuildRequest(fmt.Sprintf(APIRouteSubUserMonitor, username), rest.Delete)
+	deleteResp, err := c.restClient.InvokeRequest(deleteReq)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete monitor for sub user %s", username)
+	}
+	if deleteResp.StatusCode == 404 {
+		return &NotExistError{Message: fmt.Sprintf("monitor for sub user %s not found", username)}
+	}
+	if deleteResp.StatusCode != 204 {
+		return newAPIError(deleteResp, 204)
+	}
+	return nil
+}
+
+//GetEventWebhookSettings Get the event webhook settings on behalf of a sub user
+func (c *BackendAPIClient) GetEventWebhookSettings(username string) (*EventWebhookSettings, error) {
+	if username == "" {
+		return nil, errors.New("username must be a non-empty string")
+	}
+	getReq := c.restClient.BuildRequest(APIRouteEventWebhookSettings, rest.Get)
+	getReq.Headers[HeaderOnBehalfOf] = username
+	getResp, err := c.restClient.InvokeRequest(getReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get event webhook settings for user %s", username)
+	}
+	if getResp.StatusCode != 200 {
+		return nil, newAPIError(getResp, 200)
+	}
+	var settings *EventWebhookSettings
+	if err = json.Unmarshal([]byte(getResp.Body), &settings); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal event webhook settings response, content=%s", getResp.Body)
+	}
+	return settings, nil
+}
+
+//UpdateEventWebhookSettings Update the event webhook settings on behalf of a sub user
+func (c *BackendAPIClient) UpdateEventWebhookSettings(username string, settings *EventWebhookSettings) (*EventWebhookSettings, error) {
+	if username == "" {
+		return nil, errors.New("username must be a non-empty string")
+	}
+	if settings == nil {
+		return nil, errors.New("settings must be defined")
+	}
+	updateReq := c.restClient.BuildRequest(APIRouteEventWebhookSettings, rest.Patch)
+	updateReq.Headers[HeaderOnBehalfOf] = username
+	updateBody, err := marshalRequestBody(settings, "event webhook settings")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create event webhook settings request body")
+	}
+	updateReq.Body = updateBody
+	updateResp, err := c.restClient.InvokeRequest(updateReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update event webhook settings for user %s", username)
+	}
+	if updateResp.StatusCode != 200 {
+		return nil, newAPIError(updateResp, 200)
+	}
+	var updated *EventWebhookSettings
+	if err = json.Unmarshal([]byte(updateResp.Body), &updated); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal event webhook settings response, content=%s", updateResp.Body)
+	}
+	return updated, nil
+}
+
+//TestEventWebhook Send a fake event notification to the provided url on behalf of a sub user
+func (c *BackendAPIClient) TestEventWebhook(username, url string) error {
+	if username == "" {
+		return errors.New("username must be a non-empty string")
+	}
+	if url == "" {
+		return errors.New("url must be a non-empty string")
+	}
+	testReq := c.restClient.BuildRequest(APIRouteEventWebhookTest, rest.Post)
+	testReq.Headers[HeaderOnBehalfOf] = username
+	testBody, err := buildEventWebhookTestBody(url)
+	if err != nil {
+		return errors.Wrap(err, "failed to create event webhook test request body")
+	}
+	testReq.Body = testBody
+	testResp, err := c.restClient.InvokeRequest(testReq)
+	if err != nil {
+		return errors.Wrapf(err, "failed to test event webhook for user %s", username)
+	}
+	if testResp.StatusCode != 204 {
+		return newAPIError(testResp, 204)
+	}
+	return nil
+}
+
+//ListIPPools List the IP pools of the authenticated user
+func (c *BackendAPIClient) ListIPPools() ([]*IPPool, error) {
+	listReq := c.restClient.BuildRequest(APIRouteIPPools, rest.Get)
+	listResp, err := c.restClient.InvokeRequest(listReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ip pools")
+	}
+	if listResp.StatusCode != 200 {
+		return nil, newAPIError(listResp, 200)
+	}
+	var pools []*IPPool
+	if err = json.Unmarshal([]byte(listResp.Body), &pools); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal ip pools response, content=%s", listResp.Body)
+	}
+	return pools, nil
+}
+
+//CreateIPPool Create an IP pool with a name
+func (c *BackendAPIClient) CreateIPPool(name string) (*IPPool, error) {
+	if name == "" {
+		return nil, errors.New("name must be a non-empty string")
+	}
+	createReq := c.restClient.BuildRequest(APIRouteIPPools, rest.Post)
+	createBody, err := buildCreateIPPoolBody(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ip pool request body")
+	}
+	createReq.Body = createBody
+	createResp, err := c.restClient.InvokeRequest(createReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create ip pool %s", name)
+	}
+	if createResp.StatusCode != 200 && createResp.StatusCode != 201 {
+		return nil, newAPIError(createResp, 201)
+	}
+	var pool *IPPool
+	if err = json.Unmarshal([]byte(createResp.Body), &pool); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal ip pool response, content=%s", createResp.Body)
+	}
+	return pool, nil
+}
+
+//AddIPToPool Add an IP address to an IP pool
+func (c *BackendAPIClient) AddIPToPool(name, ip string) error {
+	if name == "" || ip == "" {
+		return errors.New("name and ip must be non-empty strings")
+	}
+	addReq := c.restClient.BuildRequest(fmt.Sprintf("%s/%s/ips", APIRouteIPPools, name), rest.Post)
+	addBody, err := buildAddIPToPoolBody(ip)
+	if err != nil {
+		return errors.Wrap(err, "failed to create ip pool add ip request body")
+	}
+	addReq.Body = addBody
+	addResp, err := c.restClient.InvokeRequest(addReq)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add ip %s to pool %s", ip, name)
+	}
+	if addResp.StatusCode != 200 && addResp.StatusCode != 201 {
+		return newAPIError(addResp, 201)
+	}
+	return nil
+}
+
+//RemoveIPFromPool Remove an IP address from an IP pool
+func (c *BackendAPIClient) RemoveIPFromPool(name, ip string) error {
+	if name == "" || ip == "" {
+		return errors.New("name and ip must be non-empty strings")
+	}
+	removeReq := c.restClient.BuildRequest(fmt.Sprintf("%s/%s/ips/%s", APIRouteIPPools, name, ip), rest.Delete)
+	removeResp, err := c.restClient.InvokeRequest(removeReq)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove ip %s from pool %s", ip, name)
+	}
+	if removeResp.StatusCode == 404 {
+		return &NotExistError{Message: fmt.Sprintf("ip %s not found in pool %s", ip, name)}
+	}
+	if removeResp.StatusCode != 204 {
+		return newAPIError(removeResp, 204)
+	}
+	return nil
+}
+
+//GetCredits Get the email credits of the authenticated user
+func (c *BackendAPIClient) GetCredits() (*Credits, error) {
+	getReq := c.restClient.BuildRequest(APIRouteCredits, rest.Get)
+	getResp, err := c.restClient.InvokeRequest(getReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get credits")
+	}
+	if getResp.StatusCode != 200 {
+		return nil, newAPIError(getResp, 200)
+	}
+	credits := &Credits{}
+	if err = json.Unmarshal([]byte(getResp.Body), credits); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal credits response, content=%s", getResp.Body)
+	}
+	return credits, nil
+}
+
+//ListWarmupIPs List the IP addresses of the authenticated user that are currently warming up
+func (c *BackendAPIClient) ListWarmupIPs() ([]*WarmupIP, error) {
+	listReq := c.restClient.BuildRequest(APIRouteIPWarmup, rest.Get)
+	listResp, err := c.restClient.InvokeRequest(listReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list warmup ip addresses")
+	}
+	if listResp.StatusCode != 200 {
+		return nil, newAPIError(listResp, 200)
+	}
+	var warmupIPs []*WarmupIP
+	if err = json.Unmarshal([]byte(listResp.Body), &warmupIPs); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal warmup ip addresses response, content=%s", listResp.Body)
+	}
+	return warmupIPs, nil
+}
+
+//GetIPWarmupStatus Get the warmup status of an IP address, NotExistError is returned if it is not warming up
+func (c *BackendAPIClient) GetIPWarmupStatus(ip string) (*WarmupIP, error) {
+	if ip == "" {
+		return nil, errors.New("ip must be a non-empty string")
+	}
+	getReq := c.restClient.BuildRequest(fmt.Sprintf("%s/%s", APIRouteIPWarmup, ip), rest.Get)
+	getResp, err := c.restClient.InvokeRequest(getReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get warmup status of ip %s", ip)
+	}
+	if getResp.StatusCode == 404 {
+		return nil, &NotExistError{Message: fmt.Sprintf("ip %s is not warming up", ip)}
+	}
+	if getResp.StatusCode != 200 {
+		return nil, newAPIError(getResp, 200)
+	}
+	return findWarmupIP(getResp.Body, ip)
+}
+
+//StartIPWarmup Start warming up an IP address
+func (c *BackendAPIClient) StartIPWarmup(ip string) (*WarmupIP, error) {
+	if ip == "" {
+		return nil, errors.New("ip must be a non-empty string")
+	}
+	startReq := c.restClient.BuildRequest(APIRouteIPWarmup, rest.Post)
+	startBody, err := buildStartIPWarmupBody(ip)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ip warmup request body")
+	}
+	startReq.Body = startBody
+	startResp, err := c.restClient.InvokeRequest(startReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to start warmup of ip %s", ip)
+	}
+	if startResp.StatusCode != 200 {
+		return nil, newAPIError(startResp, 200)
+	}
+	return findWarmupIP(startResp.Body, ip)
+}
+
+//StopIPWarmup Stop warming up an IP address
+func (c *BackendAPIClient) StopIPWarmup(ip string) error {
+	if ip == "" {
+		return errors.New("ip must be a non-empty string")
+	}
+	stopReq := c.restClient.BuildRequest(fmt.Sprintf("%s/%s", APIRouteIPWarmup, ip), rest.Delete)
+	stopResp, err := c.restClient.InvokeRequest(stopReq)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stop warmup of ip %s", ip)
+	}
+	if stopResp.StatusCode == 404 {
+		return &NotExistError{Message: fmt.Sprintf("ip %s is not warming up", ip)}
+	}
+	if stopResp.StatusCode != 204 {
+		return newAPIError(stopResp, 204)
+	}
+	return nil
+}
+
+//findWarmupIP The warmup endpoints respond with a list, find the entry of the expected ip in it
+func findWarmupIP(body, ip string) (*WarmupIP, error) {
+	var warmupIPs []*WarmupIP
+	if err := json.Unmarshal([]byte(body), &warmupIPs); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal warmup ip response, content=%s", body)
+	}
+	for _, w := range warmupIPs {
+		if w.IP == ip {
+			return w, nil
+		}
+	}
+	return nil, &NotExistError{Message: fmt.Sprintf("ip %s not found in warmup response", ip)}
+}
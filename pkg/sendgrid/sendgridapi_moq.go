@@ -8,14 +8,30 @@ import (
 )
 
 var (
-	lockAPIClientMockCreateAPIKeyForSubUser sync.RWMutex
-	lockAPIClientMockCreateSubUser          sync.RWMutex
-	lockAPIClientMockDeleteAPIKeyForSubUser sync.RWMutex
-	lockAPIClientMockDeleteSubUser          sync.RWMutex
-	lockAPIClientMockGetAPIKeysForSubUser   sync.RWMutex
-	lockAPIClientMockGetSubUserByUsername   sync.RWMutex
-	lockAPIClientMockListIPAddresses        sync.RWMutex
-	lockAPIClientMockListSubUsers           sync.RWMutex
+	lockAPIClientMockAddIPToPool                sync.RWMutex
+	lockAPIClientMockCreateAPIKeyForSubUser     sync.RWMutex
+	lockAPIClientMockCreateIPPool               sync.RWMutex
+	lockAPIClientMockCreateSubUser              sync.RWMutex
+	lockAPIClientMockCreateSubUserMonitor       sync.RWMutex
+	lockAPIClientMockDeleteAPIKeyForSubUser     sync.RWMutex
+	lockAPIClientMockDeleteSubUser              sync.RWMutex
+	lockAPIClientMockDeleteSubUserMonitor       sync.RWMutex
+	lockAPIClientMockGetAPIKeysForSubUser       sync.RWMutex
+	lockAPIClientMockGetCredits                 sync.RWMutex
+	lockAPIClientMockGetEventWebhookSettings    sync.RWMutex
+	lockAPIClientMockGetIPWarmupStatus          sync.RWMutex
+	lockAPIClientMockGetSubUserByUsername       sync.RWMutex
+	lockAPIClientMockGetSubUserMonitor          sync.RWMutex
+	lockAPIClientMockListIPAddresses            sync.RWMutex
+	lockAPIClientMockListIPPools                sync.RWMutex
+	lockAPIClientMockListSubUsers               sync.RWMutex
+	lockAPIClientMockListWarmupIPs              sync.RWMutex
+	lockAPIClientMockRemoveIPFromPool           sync.RWMutex
+	lockAPIClientMockStartIPWarmup              sync.RWMutex
+	lockAPIClientMockStopIPWarmup               sync.RWMutex
+	lockAPIClientMockTestEventWebhook           sync.RWMutex
+	lockAPIClientMockUpdateEventWebhookSettings sync.RWMutex
+	lockAPIClientMockUpdateSubUserMonitor       sync.RWMutex
 )
 
 // Ensure, that APIClientMock does implement APIClient.
@@ -28,30 +44,78 @@ var _ APIClient = &APIClientMock{}
 //
 //         // make and configure a mocked APIClient
 //         mockedAPIClient := &APIClientMock{
+//             AddIPToPoolFunc: func(name string, ip string) error {
+// 	               panic("mock out the AddIPToPool method")
+//             },
 //             CreateAPIKeyForSubUserFunc: func(username string, scopes []string) (*APIKey, error) {
 // 	               panic("mock out the CreateAPIKeyForSubUser method")
 //             },
+//             CreateIPPoolFunc: func(name string) (*IPPool, error) {
+// 	               panic("mock out the CreateIPPool method")
+//             },
 //             CreateSubUserFunc: func(id string, email string, password string, ips []string) (*SubUser, error) {
 // 	               panic("mock out the CreateSubUser method")
 //             },
+//             CreateSubUserMonitorFunc: func(username string, email string, frequency int) (*Monitor, error) {
+// 	               panic("mock out the CreateSubUserMonitor method")
+//             },
 //             DeleteAPIKeyForSubUserFunc: func(id string, keyName string) error {
 // 	               panic("mock out the DeleteAPIKeyForSubUser method")
 //             },
 //             DeleteSubUserFunc: func(username string) error {
 // 	               panic("mock out the DeleteSubUser method")
 //             },
+//             DeleteSubUserMonitorFunc: func(username string) error {
+// 	               panic("mock out the DeleteSubUserMonitor method")
+//             },
 //             GetAPIKeysForSubUserFunc: func(username string) ([]*APIKey, error) {
 // 	               panic("mock out the GetAPIKeysForSubUser method")
 //             },
+//             GetCreditsFunc: func() (*Credits, error) {
+// 	               panic("mock out the GetCredits method")
+//             },
+//             GetEventWebhookSettingsFunc: func(username string) (*EventWebhookSettings, error) {
+// 	               panic("mock out the GetEventWebhookSettings method")
+//             },
+//             GetIPWarmupStatusFunc: func(ip string) (*WarmupIP, error) {
+// 	               panic("mock out the GetIPWarmupStatus method")
+//             },
 //             GetSubUserByUsernameFunc: func(username string) (*SubUser, error) {
 // 	               panic("mock out the GetSubUserByUsername method")
 //             },
+//             GetSubUserMonitorFunc: func(username string) (*Monitor, error) {
+// 	               panic("mock out the GetSubUserMonitor method")
+//             },
 //             ListIPAddressesFunc: func() ([]*IPAddress, error) {
 // 	               panic("mock out the ListIPAddresses method")
 //             },
+//             ListIPPoolsFunc: func() ([]*IPPool, error) {
+// 	               panic("mock out the ListIPPools method")
+//             },
 //             ListSubUsersFunc: func(query map[string]string) ([]*SubUser, error) {
 // 	               panic("mock out the ListSubUsers method")
 //             },
+//             ListWarmupIPsFunc: func() ([]*WarmupIP, error) {
+// 	               panic("mock out the ListWarmupIPs method")
+//             },
+//             RemoveIPFromPoolFunc: func(name string, ip string) error {
+// 	               panic("mock out the RemoveIPFromPool method")
+//             },
+//             StartIPWarmupFunc: func(ip string) (*WarmupIP, error) {
+// 	               panic("mock out the StartIPWarmup method")
+//             },
+//             StopIPWarmupFunc: func(ip string) error {
+// 	               panic("mock out the StopIPWarmup method")
+//             },
+//             TestEventWebhookFunc: func(username string, url string) error {
+// 	               panic("mock out the TestEventWebhook method")
+//             },
+//             UpdateEventWebhookSettingsFunc: func(username string, settings *EventWebhookSettings) (*EventWebhookSettings, error) {
+// 	               panic("mock out the UpdateEventWebhookSettings method")
+//             },
+//             UpdateSubUserMonitorFunc: func(username string, email string, frequency int) (*Monitor, error) {
+// 	               panic("mock out the UpdateSubUserMonitor method")
+//             },
 //         }
 //
 //         // use mockedAPIClient in code that requires APIClient
@@ -59,32 +123,87 @@ var _ APIClient = &APIClientMock{}
 //
 //     }
 type APIClientMock struct {
+	// AddIPToPoolFunc mocks the AddIPToPool method.
+	AddIPToPoolFunc func(name string, ip string) error
+
 	// CreateAPIKeyForSubUserFunc mocks the CreateAPIKeyForSubUser method.
 	CreateAPIKeyForSubUserFunc func(username string, scopes []string) (*APIKey, error)
 
+	// CreateIPPoolFunc mocks the CreateIPPool method.
+	CreateIPPoolFunc func(name string) (*IPPool, error)
+
 	// CreateSubUserFunc mocks the CreateSubUser method.
 	CreateSubUserFunc func(id string, email string, password string, ips []string) (*SubUser, error)
 
+	// CreateSubUserMonitorFunc mocks the CreateSubUserMonitor method.
+	CreateSubUserMonitorFunc func(username string, email string, frequency int) (*Monitor, error)
+
 	// DeleteAPIKeyForSubUserFunc mocks the DeleteAPIKeyForSubUser method.
 	DeleteAPIKeyForSubUserFunc func(id string, keyName string) error
 
 	// DeleteSubUserFunc mocks the DeleteSubUser method.
 	DeleteSubUserFunc func(username string) error
 
+	// DeleteSubUserMonitorFunc mocks the DeleteSubUserMonitor method.
+	DeleteSubUserMonitorFunc func(username string) error
+
 	// GetAPIKeysForSubUserFunc mocks the GetAPIKeysForSubUser method.
 	GetAPIKeysForSubUserFunc func(username string) ([]*APIKey, error)
 
+	// GetCreditsFunc mocks the GetCredits method.
+	GetCreditsFunc func() (*Credits, error)
+
+	// GetEventWebhookSettingsFunc mocks the GetEventWebhookSettings method.
+	GetEventWebhookSettingsFunc func(username string) (*EventWebhookSettings, error)
+
+	// GetIPWarmupStatusFunc mocks the GetIPWarmupStatus method.
+	GetIPWarmupStatusFunc func(ip string) (*WarmupIP, error)
+
 	// GetSubUserByUsernameFunc mocks the GetSubUserByUsername method.
 	GetSubUserByUsernameFunc func(username string) (*SubUser, error)
 
+	// GetSubUserMonitorFunc mocks the GetSubUserMonitor method.
+	GetSubUserMonitorFunc func(username string) (*Monitor, error)
+
 	// ListIPAddressesFunc mocks the ListIPAddresses method.
 	ListIPAddressesFunc func() ([]*IPAddress, error)
 
+	// ListIPPoolsFunc mocks the ListIPPools method.
+	ListIPPoolsFunc func() ([]*IPPool, error)
+
 	// ListSubUsersFunc mocks the ListSubUsers method.
 	ListSubUsersFunc func(query map[string]string) ([]*SubUser, error)
 
+	// ListWarmupIPsFunc mocks the ListWarmupIPs method.
+	ListWarmupIPsFunc func() ([]*WarmupIP, error)
+
+	// RemoveIPFromPoolFunc mocks the RemoveIPFromPool method.
+	RemoveIPFromPoolFunc func(name string, ip string) error
+
+	// StartIPWarmupFunc mocks the StartIPWarmup method.
+	StartIPWarmupFunc func(ip string) (*WarmupIP, error)
+
+	// StopIPWarmupFunc mocks the StopIPWarmup method.
+	StopIPWarmupFunc func(ip string) error
+
+	// TestEventWebhookFunc mocks the TestEventWebhook method.
+	TestEventWebhookFunc func(username string, url string) error
+
+	// UpdateEventWebhookSettingsFunc mocks the UpdateEventWebhookSettings method.
+	UpdateEventWebhookSettingsFunc func(username string, settings *EventWebhookSettings) (*EventWebhookSettings, error)
+
+	// UpdateSubUserMonitorFunc mocks the UpdateSubUserMonitor method.
+	UpdateSubUserMonitorFunc func(username string, email string, frequency int) (*Monitor, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// AddIPToPool holds details about calls to the AddIPToPool method.
+		AddIPToPool []struct {
+			// Name is the name argument value.
+			Name string
+			// IP is the ip argument value.
+			IP string
+		}
 		// CreateAPIKeyForSubUser holds details about calls to the CreateAPIKeyForSubUser method.
 		CreateAPIKeyForSubUser []struct {
 			// Username is the username argument value.
@@ -92,6 +211,11 @@ type APIClientMock struct {
 			// Scopes is the scopes argument value.
 			Scopes []string
 		}
+		// CreateIPPool holds details about calls to the CreateIPPool method.
+		CreateIPPool []struct {
+			// Name is the name argument value.
+			Name string
+		}
 		// CreateSubUser holds details about calls to the CreateSubUser method.
 		CreateSubUser []struct {
 			// ID is the id argument value.
@@ -103,6 +227,15 @@ type APIClientMock struct {
 			// Ips is the ips argument value.
 			Ips []string
 		}
+		// CreateSubUserMonitor holds details about calls to the CreateSubUserMonitor method.
+		CreateSubUserMonitor []struct {
+			// Username is the username argument value.
+			Username string
+			// Email is the email argument value.
+			Email string
+			// Frequency is the frequency argument value.
+			Frequency int
+		}
 		// DeleteAPIKeyForSubUser holds details about calls to the DeleteAPIKeyForSubUser method.
 		DeleteAPIKeyForSubUser []struct {
 			// ID is the id argument value.
@@ -115,25 +248,129 @@ type APIClientMock struct {
 			// Username is the username argument value.
 			Username string
 		}
+		// DeleteSubUserMonitor holds details about calls to the DeleteSubUserMonitor method.
+		DeleteSubUserMonitor []struct {
+			// Username is the username argument value.
+			Username string
+		}
 		// GetAPIKeysForSubUser holds details about calls to the GetAPIKeysForSubUser method.
 		GetAPIKeysForSubUser []struct {
 			// Username is the username argument value.
 			Username string
 		}
+		// GetCredits holds details about calls to the GetCredits method.
+		GetCredits []struct {
+		}
+		// GetEventWebhookSettings holds details about calls to the GetEventWebhookSettings method.
+		GetEventWebhookSettings []struct {
+			// Username is the username argument value.
+			Username string
+		}
+		// GetIPWarmupStatus holds details about calls to the GetIPWarmupStatus method.
+		GetIPWarmupStatus []struct {
+			// IP is the ip argument value.
+			IP string
+		}
 		// GetSubUserByUsername holds details about calls to the GetSubUserByUsername method.
 		GetSubUserByUsername []struct {
 			// Username is the username argument value.
 			Username string
 		}
+		// GetSubUserMonitor holds details about calls to the GetSubUserMonitor method.
+		GetSubUserMonitor []struct {
+			// Username is the username argument value.
+			Username string
+		}
 		// ListIPAddresses holds details about calls to the ListIPAddresses method.
 		ListIPAddresses []struct {
 		}
+		// ListIPPools holds details about calls to the ListIPPools method.
+		ListIPPools []struct {
+		}
 		// ListSubUsers holds details about calls to the ListSubUsers method.
 		ListSubUsers []struct {
 			// Query is the query argument value.
 			Query map[string]string
 		}
+		// ListWarmupIPs holds details about calls to the ListWarmupIPs method.
+		ListWarmupIPs []struct {
+		}
+		// RemoveIPFromPool holds details about calls to the RemoveIPFromPool method.
+		RemoveIPFromPool []struct {
+			// Name is the name argument value.
+			Name string
+			// IP is the ip argument value.
+			IP string
+		}
+		// StartIPWarmup holds details about calls to the StartIPWarmup method.
+		StartIPWarmup []struct {
+			// IP is the ip argument value.
+			IP string
+		}
+		// StopIPWarmup holds details about calls to the StopIPWarmup method.
+		StopIPWarmup []struct {
+			// IP is the ip argument value.
+			IP string
+		}
+		// TestEventWebhook holds details about calls to the TestEventWebhook method.
+		TestEventWebhook []struct {
+			// Username is the username argument value.
+			Username string
+			// URL is the url argument value.
+			URL string
+		}
+		// UpdateEventWebhookSettings holds details about calls to the UpdateEventWebhookSettings method.
+		UpdateEventWebhookSettings []struct {
+			// Username is the username argument value.
+			Username string
+			// Settings is the settings argument value.
+			Settings *EventWebhookSettings
+		}
+		// UpdateSubUserMonitor holds details about calls to the UpdateSubUserMonitor method.
+		UpdateSubUserMonitor []struct {
+			// Username is the username argument value.
+			Username string
+			// Email is the email argument value.
+			Email string
+			// Frequency is the frequency argument value.
+			Frequency int
+		}
+	}
+}
+
+// AddIPToPool calls AddIPToPoolFunc.
+func (mock *APIClientMock) AddIPToPool(name string, ip string) error {
+	if mock.AddIPToPoolFunc == nil {
+		panic("APIClientMock.AddIPToPoolFunc: method is nil but APIClient.AddIPToPool was just called")
+	}
+	callInfo := struct {
+		Name string
+		IP   string
+	}{
+		Name: name,
+		IP:   ip,
+	}
+	lockAPIClientMockAddIPToPool.Lock()
+	mock.calls.AddIPToPool = append(mock.calls.AddIPToPool, callInfo)
+	lockAPIClientMockAddIPToPool.Unlock()
+	return mock.AddIPToPoolFunc(name, ip)
+}
+
+// AddIPToPoolCalls gets all the calls that were made to AddIPToPool.
+// Check the length with:
+//     len(mockedAPIClient.AddIPToPoolCalls())
+func (mock *APIClientMock) AddIPToPoolCalls() []struct {
+	Name string
+	IP   string
+} {
+	var calls []struct {
+		Name string
+		IP   string
 	}
+	lockAPIClientMockAddIPToPool.RLock()
+	calls = mock.calls.AddIPToPool
+	lockAPIClientMockAddIPToPool.RUnlock()
+	return calls
 }
 
 // CreateAPIKeyForSubUser calls CreateAPIKeyForSubUserFunc.
@@ -171,6 +408,37 @@ func (mock *APIClientMock) CreateAPIKeyForSubUserCalls() []struct {
 	return calls
 }
 
+// CreateIPPool calls CreateIPPoolFunc.
+func (mock *APIClientMock) CreateIPPool(name string) (*IPPool, error) {
+	if mock.CreateIPPoolFunc == nil {
+		panic("APIClientMock.CreateIPPoolFunc: method is nil but APIClient.CreateIPPool was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockAPIClientMockCreateIPPool.Lock()
+	mock.calls.CreateIPPool = append(mock.calls.CreateIPPool, callInfo)
+	lockAPIClientMockCreateIPPool.Unlock()
+	return mock.CreateIPPoolFunc(name)
+}
+
+// CreateIPPoolCalls gets all the calls that were made to CreateIPPool.
+// Check the length with:
+//     len(mockedAPIClient.CreateIPPoolCalls())
+func (mock *APIClientMock) CreateIPPoolCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockAPIClientMockCreateIPPool.RLock()
+	calls = mock.calls.CreateIPPool
+	lockAPIClientMockCreateIPPool.RUnlock()
+	return calls
+}
+
 // CreateSubUser calls CreateSubUserFunc.
 func (mock *APIClientMock) CreateSubUser(id string, email string, password string, ips []string) (*SubUser, error) {
 	if mock.CreateSubUserFunc == nil {
@@ -214,6 +482,45 @@ func (mock *APIClientMock) CreateSubUserCalls() []struct {
 	return calls
 }
 
+// CreateSubUserMonitor calls CreateSubUserMonitorFunc.
+func (mock *APIClientMock) CreateSubUserMonitor(username string, email string, frequency int) (*Monitor, error) {
+	if mock.CreateSubUserMonitorFunc == nil {
+		panic("APIClientMock.CreateSubUserMonitorFunc: method is nil but APIClient.CreateSubUserMonitor was just called")
+	}
+	callInfo := struct {
+		Username  string
+		Email     string
+		Frequency int
+	}{
+		Username:  username,
+		Email:     email,
+		Frequency: frequency,
+	}
+	lockAPIClientMockCreateSubUserMonitor.Lock()
+	mock.calls.CreateSubUserMonitor = append(mock.calls.CreateSubUserMonitor, callInfo)
+	lockAPIClientMockCreateSubUserMonitor.Unlock()
+	return mock.CreateSubUserMonitorFunc(username, email, frequency)
+}
+
+// CreateSubUserMonitorCalls gets all the calls that were made to CreateSubUserMonitor.
+// Check the length with:
+//     len(mockedAPIClient.CreateSubUserMonitorCalls())
+func (mock *APIClientMock) CreateSubUserMonitorCalls() []struct {
+	Username  string
+	Email     string
+	Frequency int
+} {
+	var calls []struct {
+		Username  string
+		Email     string
+		Frequency int
+	}
+	lockAPIClientMockCreateSubUserMonitor.RLock()
+	calls = mock.calls.CreateSubUserMonitor
+	lockAPIClientMockCreateSubUserMonitor.RUnlock()
+	return calls
+}
+
 // DeleteAPIKeyForSubUser calls DeleteAPIKeyForSubUserFunc.
 func (mock *APIClientMock) DeleteAPIKeyForSubUser(id string, keyName string) error {
 	if mock.DeleteAPIKeyForSubUserFunc == nil {
@@ -280,6 +587,37 @@ func (mock *APIClientMock) DeleteSubUserCalls() []struct {
 	return calls
 }
 
+// DeleteSubUserMonitor calls DeleteSubUserMonitorFunc.
+func (mock *APIClientMock) DeleteSubUserMonitor(username string) error {
+	if mock.DeleteSubUserMonitorFunc == nil {
+		panic("APIClientMock.DeleteSubUserMonitorFunc: method is nil but APIClient.DeleteSubUserMonitor was just called")
+	}
+	callInfo := struct {
+		Username string
+	}{
+		Username: username,
+	}
+	lockAPIClientMockDeleteSubUserMonitor.Lock()
+	mock.calls.DeleteSubUserMonitor = append(mock.calls.DeleteSubUserMonitor, callInfo)
+	lockAPIClientMockDeleteSubUserMonitor.Unlock()
+	return mock.DeleteSubUserMonitorFunc(username)
+}
+
+// DeleteSubUserMonitorCalls gets all the calls that were made to DeleteSubUserMonitor.
+// Check the length with:
+//     len(mockedAPIClient.DeleteSubUserMonitorCalls())
+func (mock *APIClientMock) DeleteSubUserMonitorCalls() []struct {
+	Username string
+} {
+	var calls []struct {
+		Username string
+	}
+	lockAPIClientMockDeleteSubUserMonitor.RLock()
+	calls = mock.calls.DeleteSubUserMonitor
+	lockAPIClientMockDeleteSubUserMonitor.RUnlock()
+	return calls
+}
+
 // GetAPIKeysForSubUser calls GetAPIKeysForSubUserFunc.
 func (mock *APIClientMock) GetAPIKeysForSubUser(username string) ([]*APIKey, error) {
 	if mock.GetAPIKeysForSubUserFunc == nil {
@@ -311,6 +649,94 @@ func (mock *APIClientMock) GetAPIKeysForSubUserCalls() []struct {
 	return calls
 }
 
+// GetCredits calls GetCreditsFunc.
+func (mock *APIClientMock) GetCredits() (*Credits, error) {
+	if mock.GetCreditsFunc == nil {
+		panic("APIClientMock.GetCreditsFunc: method is nil but APIClient.GetCredits was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockAPIClientMockGetCredits.Lock()
+	mock.calls.GetCredits = append(mock.calls.GetCredits, callInfo)
+	lockAPIClientMockGetCredits.Unlock()
+	return mock.GetCreditsFunc()
+}
+
+// GetCreditsCalls gets all the calls that were made to GetCredits.
+// Check the length with:
+//     len(mockedAPIClient.GetCreditsCalls())
+func (mock *APIClientMock) GetCreditsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockAPIClientMockGetCredits.RLock()
+	calls = mock.calls.GetCredits
+	lockAPIClientMockGetCredits.RUnlock()
+	return calls
+}
+
+// GetEventWebhookSettings calls GetEventWebhookSettingsFunc.
+func (mock *APIClientMock) GetEventWebhookSettings(username string) (*EventWebhookSettings, error) {
+	if mock.GetEventWebhookSettingsFunc == nil {
+		panic("APIClientMock.GetEventWebhookSettingsFunc: method is nil but APIClient.GetEventWebhookSettings was just called")
+	}
+	callInfo := struct {
+		Username string
+	}{
+		Username: username,
+	}
+	lockAPIClientMockGetEventWebhookSettings.Lock()
+	mock.calls.GetEventWebhookSettings = append(mock.calls.GetEventWebhookSettings, callInfo)
+	lockAPIClientMockGetEventWebhookSettings.Unlock()
+	return mock.GetEventWebhookSettingsFunc(username)
+}
+
+// GetEventWebhookSettingsCalls gets all the calls that were made to GetEventWebhookSettings.
+// Check the length with:
+//     len(mockedAPIClient.GetEventWebhookSettingsCalls())
+func (mock *APIClientMock) GetEventWebhookSettingsCalls() []struct {
+	Username string
+} {
+	var calls []struct {
+		Username string
+	}
+	lockAPIClientMockGetEventWebhookSettings.RLock()
+	calls = mock.calls.GetEventWebhookSettings
+	lockAPIClientMockGetEventWebhookSettings.RUnlock()
+	return calls
+}
+
+// GetIPWarmupStatus calls GetIPWarmupStatusFunc.
+func (mock *APIClientMock) GetIPWarmupStatus(ip string) (*WarmupIP, error) {
+	if mock.GetIPWarmupStatusFunc == nil {
+		panic("APIClientMock.GetIPWarmupStatusFunc: method is nil but APIClient.GetIPWarmupStatus was just called")
+	}
+	callInfo := struct {
+		IP string
+	}{
+		IP: ip,
+	}
+	lockAPIClientMockGetIPWarmupStatus.Lock()
+	mock.calls.GetIPWarmupStatus = append(mock.calls.GetIPWarmupStatus, callInfo)
+	lockAPIClientMockGetIPWarmupStatus.Unlock()
+	return mock.GetIPWarmupStatusFunc(ip)
+}
+
+// GetIPWarmupStatusCalls gets all the calls that were made to GetIPWarmupStatus.
+// Check the length with:
+//     len(mockedAPIClient.GetIPWarmupStatusCalls())
+func (mock *APIClientMock) GetIPWarmupStatusCalls() []struct {
+	IP string
+} {
+	var calls []struct {
+		IP string
+	}
+	lockAPIClientMockGetIPWarmupStatus.RLock()
+	calls = mock.calls.GetIPWarmupStatus
+	lockAPIClientMockGetIPWarmupStatus.RUnlock()
+	return calls
+}
+
 // GetSubUserByUsername calls GetSubUserByUsernameFunc.
 func (mock *APIClientMock) GetSubUserByUsername(username string) (*SubUser, error) {
 	if mock.GetSubUserByUsernameFunc == nil {
@@ -342,6 +768,37 @@ func (mock *APIClientMock) GetSubUserByUsernameCalls() []struct {
 	return calls
 }
 
+// GetSubUserMonitor calls GetSubUserMonitorFunc.
+func (mock *APIClientMock) GetSubUserMonitor(username string) (*Monitor, error) {
+	if mock.GetSubUserMonitorFunc == nil {
+		panic("APIClientMock.GetSubUserMonitorFunc: method is nil but APIClient.GetSubUserMonitor was just called")
+	}
+	callInfo := struct {
+		Username string
+	}{
+		Username: username,
+	}
+	lockAPIClientMockGetSubUserMonitor.Lock()
+	mock.calls.GetSubUserMonitor = append(mock.calls.GetSubUserMonitor, callInfo)
+	lockAPIClientMockGetSubUserMonitor.Unlock()
+	return mock.GetSubUserMonitorFunc(username)
+}
+
+// GetSubUserMonitorCalls gets all the calls that were made to GetSubUserMonitor.
+// Check the length with:
+//     len(mockedAPIClient.GetSubUserMonitorCalls())
+func (mock *APIClientMock) GetSubUserMonitorCalls() []struct {
+	Username string
+} {
+	var calls []struct {
+		Username string
+	}
+	lockAPIClientMockGetSubUserMonitor.RLock()
+	calls = mock.calls.GetSubUserMonitor
+	lockAPIClientMockGetSubUserMonitor.RUnlock()
+	return calls
+}
+
 // ListIPAddresses calls ListIPAddressesFunc.
 func (mock *APIClientMock) ListIPAddresses() ([]*IPAddress, error) {
 	if mock.ListIPAddressesFunc == nil {
@@ -368,6 +825,32 @@ func (mock *APIClientMock) ListIPAddressesCalls() []struct {
 	return calls
 }
 
+// ListIPPools calls ListIPPoolsFunc.
+func (mock *APIClientMock) ListIPPools() ([]*IPPool, error) {
+	if mock.ListIPPoolsFunc == nil {
+		panic("APIClientMock.ListIPPoolsFunc: method is nil but APIClient.ListIPPools was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockAPIClientMockListIPPools.Lock()
+	mock.calls.ListIPPools = append(mock.calls.ListIPPools, callInfo)
+	lockAPIClientMockListIPPools.Unlock()
+	return mock.ListIPPoolsFunc()
+}
+
+// ListIPPoolsCalls gets all the calls that were made to ListIPPools.
+// Check the length with:
+//     len(mockedAPIClient.ListIPPoolsCalls())
+func (mock *APIClientMock) ListIPPoolsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockAPIClientMockListIPPools.RLock()
+	calls = mock.calls.ListIPPools
+	lockAPIClientMockListIPPools.RUnlock()
+	return calls
+}
+
 // ListSubUsers calls ListSubUsersFunc.
 func (mock *APIClientMock) ListSubUsers(query map[string]string) ([]*SubUser, error) {
 	if mock.ListSubUsersFunc == nil {
@@ -398,3 +881,235 @@ func (mock *APIClientMock) ListSubUsersCalls() []struct {
 	lockAPIClientMockListSubUsers.RUnlock()
 	return calls
 }
+
+// ListWarmupIPs calls ListWarmupIPsFunc.
+func (mock *APIClientMock) ListWarmupIPs() ([]*WarmupIP, error) {
+	if mock.ListWarmupIPsFunc == nil {
+		panic("APIClientMock.ListWarmupIPsFunc: method is nil but APIClient.ListWarmupIPs was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockAPIClientMockListWarmupIPs.Lock()
+	mock.calls.ListWarmupIPs = append(mock.calls.ListWarmupIPs, callInfo)
+	lockAPIClientMockListWarmupIPs.Unlock()
+	return mock.ListWarmupIPsFunc()
+}
+
+// ListWarmupIPsCalls gets all the calls that were made to ListWarmupIPs.
+// Check the length with:
+//     len(mockedAPIClient.ListWarmupIPsCalls())
+func (mock *APIClientMock) ListWarmupIPsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockAPIClientMockListWarmupIPs.RLock()
+	calls = mock.calls.ListWarmupIPs
+	lockAPIClientMockListWarmupIPs.RUnlock()
+	return calls
+}
+
+// RemoveIPFromPool calls RemoveIPFromPoolFunc.
+func (mock *APIClientMock) RemoveIPFromPool(name string, ip string) error {
+	if mock.RemoveIPFromPoolFunc == nil {
+		panic("APIClientMock.RemoveIPFromPoolFunc: method is nil but APIClient.RemoveIPFromPool was just called")
+	}
+	callInfo := struct {
+		Name string
+		IP   string
+	}{
+		Name: name,
+		IP:   ip,
+	}
+	lockAPIClientMockRemoveIPFromPool.Lock()
+	mock.calls.RemoveIPFromPool = append(mock.calls.RemoveIPFromPool, callInfo)
+	lockAPIClientMockRemoveIPFromPool.Unlock()
+	return mock.RemoveIPFromPoolFunc(name, ip)
+}
+
+// RemoveIPFromPoolCalls gets all the calls that were made to RemoveIPFromPool.
+// Check the length with:
+//     len(mockedAPIClient.RemoveIPFromPoolCalls())
+func (mock *APIClientMock) RemoveIPFromPoolCalls() []struct {
+	Name string
+	IP   string
+} {
+	var calls []struct {
+		Name string
+		IP   string
+	}
+	lockAPIClientMockRemoveIPFromPool.RLock()
+	calls = mock.calls.RemoveIPFromPool
+	lockAPIClientMockRemoveIPFromPool.RUnlock()
+	return calls
+}
+
+// StartIPWarmup calls StartIPWarmupFunc.
+func (mock *APIClientMock) StartIPWarmup(ip string) (*WarmupIP, error) {
+	if mock.StartIPWarmupFunc == nil {
+		panic("APIClientMock.StartIPWarmupFunc: method is nil but APIClient.StartIPWarmup was just called")
+	}
+	callInfo := struct {
+		IP string
+	}{
+		IP: ip,
+	}
+	lockAPIClientMockStartIPWarmup.Lock()
+	mock.calls.StartIPWarmup = append(mock.calls.StartIPWarmup, callInfo)
+	lockAPIClientMockStartIPWarmup.Unlock()
+	return mock.StartIPWarmupFunc(ip)
+}
+
+// StartIPWarmupCalls gets all the calls that were made to StartIPWarmup.
+// Check the length with:
+//     len(mockedAPIClient.StartIPWarmupCalls())
+func (mock *APIClientMock) StartIPWarmupCalls() []struct {
+	IP string
+} {
+	var calls []struct {
+		IP string
+	}
+	lockAPIClientMockStartIPWarmup.RLock()
+	calls = mock.calls.StartIPWarmup
+	lockAPIClientMockStartIPWarmup.RUnlock()
+	return calls
+}
+
+// StopIPWarmup calls StopIPWarmupFunc.
+func (mock *APIClientMock) StopIPWarmup(ip string) error {
+	if mock.StopIPWarmupFunc == nil {
+		panic("APIClientMock.StopIPWarmupFunc: method is nil but APIClient.StopIPWarmup was just called")
+	}
+	callInfo := struct {
+		IP string
+	}{
+		IP: ip,
+	}
+	lockAPIClientMockStopIPWarmup.Lock()
+	mock.calls.StopIPWarmup = append(mock.calls.StopIPWarmup, callInfo)
+	lockAPIClientMockStopIPWarmup.Unlock()
+	return mock.StopIPWarmupFunc(ip)
+}
+
+// StopIPWarmupCalls gets all the calls that were made to StopIPWarmup.
+// Check the length with:
+//     len(mockedAPIClient.StopIPWarmupCalls())
+func (mock *APIClientMock) StopIPWarmupCalls() []struct {
+	IP string
+} {
+	var calls []struct {
+		IP string
+	}
+	lockAPIClientMockStopIPWarmup.RLock()
+	calls = mock.calls.StopIPWarmup
+	lockAPIClientMockStopIPWarmup.RUnlock()
+	return calls
+}
+
+// TestEventWebhook calls TestEventWebhookFunc.
+func (mock *APIClientMock) TestEventWebhook(username string, url string) error {
+	if mock.TestEventWebhookFunc == nil {
+		panic("APIClientMock.TestEventWebhookFunc: method is nil but APIClient.TestEventWebhook was just called")
+	}
+	callInfo := struct {
+		Username string
+		URL      string
+	}{
+		Username: username,
+		URL:      url,
+	}
+	lockAPIClientMockTestEventWebhook.Lock()
+	mock.calls.TestEventWebhook = append(mock.calls.TestEventWebhook, callInfo)
+	lockAPIClientMockTestEventWebhook.Unlock()
+	return mock.TestEventWebhookFunc(username, url)
+}
+
+// TestEventWebhookCalls gets all the calls that were made to TestEventWebhook.
+// Check the length with:
+//     len(mockedAPIClient.TestEventWebhookCalls())
+func (mock *APIClientMock) TestEventWebhookCalls() []struct {
+	Username string
+	URL      string
+} {
+	var calls []struct {
+		Username string
+		URL      string
+	}
+	lockAPIClientMockTestEventWebhook.RLock()
+	calls = mock.calls.TestEventWebhook
+	lockAPIClientMockTestEventWebhook.RUnlock()
+	return calls
+}
+
+// UpdateEventWebhookSettings calls UpdateEventWebhookSettingsFunc.
+func (mock *APIClientMock) UpdateEventWebhookSettings(username string, settings *EventWebhookSettings) (*EventWebhookSettings, error) {
+	if mock.UpdateEventWebhookSettingsFunc == nil {
+		panic("APIClientMock.UpdateEventWebhookSettingsFunc: method is nil but APIClient.UpdateEventWebhookSettings was just called")
+	}
+	callInfo := struct {
+		Username string
+		Settings *EventWebhookSettings
+	}{
+		Username: username,
+		Settings: settings,
+	}
+	lockAPIClientMockUpdateEventWebhookSettings.Lock()
+	mock.calls.UpdateEventWebhookSettings = append(mock.calls.UpdateEventWebhookSettings, callInfo)
+	lockAPIClientMockUpdateEventWebhookSettings.Unlock()
+	return mock.UpdateEventWebhookSettingsFunc(username, settings)
+}
+
+// UpdateEventWebhookSettingsCalls gets all the calls that were made to UpdateEventWebhookSettings.
+// Check the length with:
+//     len(mockedAPIClient.UpdateEventWebhookSettingsCalls())
+func (mock *APIClientMock) UpdateEventWebhookSettingsCalls() []struct {
+	Username string
+	Settings *EventWebhookSettings
+} {
+	var calls []struct {
+		Username string
+		Settings *EventWebhookSettings
+	}
+	lockAPIClientMockUpdateEventWebhookSettings.RLock()
+	calls = mock.calls.UpdateEventWebhookSettings
+	lockAPIClientMockUpdateEventWebhookSettings.RUnlock()
+	return calls
+}
+
+// UpdateSubUserMonitor calls UpdateSubUserMonitorFunc.
+func (mock *APIClientMock) UpdateSubUserMonitor(username string, email string, frequency int) (*Monitor, error) {
+	if mock.UpdateSubUserMonitorFunc == nil {
+		panic("APIClientMock.UpdateSubUserMonitorFunc: method is nil but APIClient.UpdateSubUserMonitor was just called")
+	}
+	callInfo := struct {
+		Username  string
+		Email     string
+		Frequency int
+	}{
+		Username:  username,
+		Email:     email,
+		Frequency: frequency,
+	}
+	lockAPIClientMockUpdateSubUserMonitor.Lock()
+	mock.calls.UpdateSubUserMonitor = append(mock.calls.UpdateSubUserMonitor, callInfo)
+	lockAPIClientMockUpdateSubUserMonitor.Unlock()
+	return mock.UpdateSubUserMonitorFunc(username, email, frequency)
+}
+
+// UpdateSubUserMonitorCalls gets all the calls that were made to UpdateSubUserMonitor.
+// Check the length with:
+//     len(mockedAPIClient.UpdateSubUserMonitorCalls())
+func (mock *APIClientMock) UpdateSubUserMonitorCalls() []struct {
+	Username  string
+	Email     string
+	Frequency int
+} {
+	var calls []struct {
+		Username  string
+		Email     string
+		Frequency int
+	}
+	lockAPIClientMockUpdateSubUserMonitor.RLock()
+	calls = mock.calls.UpdateSubUserMonitor
+	lockAPIClientMockUpdateSubUserMonitor.RUnlock()
+	return calls
+}
@@ -529,6 +529,42 @@ func TestBackendAPIClient_ListSubUsers(t *testing.T) {
 	}
 }
 
+func TestBackendAPIClient_ListSubUsers_Pages(t *testing.T) {
+	var offsets []string
+	restClient := newMockRESTClient(func(c *RESTClientMock) {
+		c.InvokeRequestFunc = func(request rest.Request) (*rest.Response, error) {
+			if request.QueryParams["limit"] != "2" || request.QueryParams["username"] != "test" {
+				t.Fatalf("unexpected query %v", request.QueryParams)
+			}
+			offsets = append(offsets, request.QueryParams["offset"])
+			// three sub users over pages of two
+			page := []*SubUser{{Username: "a"}, {Username: "b"}}
+			if request.QueryParams["offset"] == "2" {
+				page = []*SubUser{{Username: "c"}}
+			}
+			respJSON, err := json.Marshal(page)
+			if err != nil {
+				t.Fatalf("failed to marshal sub users: %v", err)
+			}
+			return &rest.Response{StatusCode: 200, Body: string(respJSON), Headers: map[string][]string{}}, nil
+		}
+	})
+	c := &BackendAPIClient{restClient: restClient, logger: newMockLogger()}
+	got, err := c.ListSubUsers(map[string]string{"limit": "2", "username": "test"})
+	if err != nil {
+		t.Fatalf("ListSubUsers() unexpected error = %v", err)
+	}
+	if want := []*SubUser{{Username: "a"}, {Username: "b"}, {Username: "c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSubUsers() got = %v, want %v", got, want)
+	}
+	if want := []string{"0", "2"}; !reflect.DeepEqual(offsets, want) {
+		t.Errorf("ListSubUsers() requested offsets %v, want %v", offsets, want)
+	}
+	if _, err := c.ListSubUsers(map[string]string{"limit": "0"}); err == nil {
+		t.Errorf("ListSubUsers() invalid limit should cause error")
+	}
+}
+
 func TestBackendAPIClient_DeleteAPIKeyForSubUser(t *testing.T) {
 	type fields struct {
 		restClient RESTClient
@@ -624,3 +660,1020 @@ func TestBackendAPIClient_DeleteAPIKeyForSubUser(t *testing.T) {
 		})
 	}
 }
+
+func newMockRESTClientWithResponse(statusCode int, body string) RESTClient {
+	return newMockRESTClient(func(c *RESTClientMock) {
+		c.InvokeRequestFunc = func(request rest.Request) (response *rest.Response, e error) {
+			return &rest.Response{
+				StatusCode: statusCode,
+				Body:       body,
+				Headers:    map[string][]string{},
+			}, nil
+		}
+	})
+}
+
+func mockJSON(v interface{}) string {
+	j, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(j)
+}
+
+func TestBackendAPIClient_GetSubUserMonitor(t *testing.T) {
+	type fields struct {
+		restClient RESTClient
+		logger     *logrus.Entry
+	}
+	type args struct {
+		username string
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		want         *Monitor
+		wantErr      bool
+		wantNotExist bool
+	}{
+		{
+			name: "successful get",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(200, mockJSON(newMockMonitor())),
+				logger:     newMockLogger(),
+			},
+			args: args{username: "test"},
+			want: newMockMonitor(),
+		},
+		{
+			name: "username not defined",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: ""},
+			wantErr: true,
+		},
+		{
+			name: "monitor not found",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(404, `{"errors":[{"message":"No monitor settings for this user"}]}`),
+				logger:     newMockLogger(),
+			},
+			args:         args{username: "test"},
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "unexpected status code",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(500, "{}"),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+		{
+			name: "get request fails",
+			fields: fields{
+				restClient: mockRESTClientFailedInvoke,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+		{
+			name: "api response invalid json",
+			fields: fields{
+				restClient: mockRESTClientInvalidJSON,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.fields.restClient,
+				logger:     tt.fields.logger,
+			}
+			got, err := c.GetSubUserMonitor(tt.args.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSubUserMonitor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("GetSubUserMonitor() error = %v, wantNotExist %v", err, tt.wantNotExist)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSubUserMonitor() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_CreateSubUserMonitor(t *testing.T) {
+	type fields struct {
+		restClient RESTClient
+		logger     *logrus.Entry
+	}
+	type args struct {
+		username  string
+		email     string
+		frequency int
+	}
+	testArgs := args{
+		username:  "test",
+		email:     newMockMonitor().Email,
+		frequency: newMockMonitor().Frequency,
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		want       *Monitor
+		wantMethod rest.Method
+		wantErr    bool
+	}{
+		{
+			name: "successful create",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(200, mockJSON(newMockMonitor())),
+				logger:     newMockLogger(),
+			},
+			args:       testArgs,
+			want:       newMockMonitor(),
+			wantMethod: rest.Post,
+		},
+		{
+			name: "email not defined",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", email: "", frequency: 1},
+			wantErr: true,
+		},
+		{
+			name: "frequency not positive",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", email: "test@email.com", frequency: 0},
+			wantErr: true,
+		},
+		{
+			name: "unexpected status code",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(400, "{}"),
+				logger:     newMockLogger(),
+			},
+			args:    testArgs,
+			wantErr: true,
+		},
+		{
+			name: "create request fails",
+			fields: fields{
+				restClient: mockRESTClientFailedInvoke,
+				logger:     newMockLogger(),
+			},
+			args:    testArgs,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.fields.restClient,
+				logger:     tt.fields.logger,
+			}
+			got, err := c.CreateSubUserMonitor(tt.args.username, tt.args.email, tt.args.frequency)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateSubUserMonitor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateSubUserMonitor() got = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			calls := tt.fields.restClient.(*RESTClientMock).BuildRequestCalls()
+			if calls[0].Method != tt.wantMethod {
+				t.Errorf("CreateSubUserMonitor() method = %v, want %v", calls[0].Method, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_UpdateSubUserMonitor(t *testing.T) {
+	restClient := newMockRESTClientWithResponse(200, mockJSON(newMockMonitor()))
+	c := &BackendAPIClient{
+		restClient: restClient,
+		logger:     newMockLogger(),
+	}
+	got, err := c.UpdateSubUserMonitor("test", newMockMonitor().Email, newMockMonitor().Frequency)
+	if err != nil {
+		t.Fatalf("UpdateSubUserMonitor() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, newMockMonitor()) {
+		t.Errorf("UpdateSubUserMonitor() got = %v, want %v", got, newMockMonitor())
+	}
+	calls := restClient.(*RESTClientMock).BuildRequestCalls()
+	if calls[0].Method != rest.Put || calls[0].Endpoint != "/v3/subusers/test/monitor" {
+		t.Errorf("UpdateSubUserMonitor() request = %s %s, want PUT /v3/subusers/test/monitor", calls[0].Method, calls[0].Endpoint)
+	}
+}
+
+func TestBackendAPIClient_DeleteSubUserMonitor(t *testing.T) {
+	type fields struct {
+		restClient RESTClient
+		logger     *logrus.Entry
+	}
+	type args struct {
+		username string
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		wantErr      bool
+		wantNotExist bool
+	}{
+		{
+			name: "successful delete",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(204, ""),
+				logger:     newMockLogger(),
+			},
+			args: args{username: "test"},
+		},
+		{
+			name: "username not defined",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: ""},
+			wantErr: true,
+		},
+		{
+			name: "monitor not found",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(404, "{}"),
+				logger:     newMockLogger(),
+			},
+			args:         args{username: "test"},
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "delete request fails",
+			fields: fields{
+				restClient: mockRESTClientFailedInvoke,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.fields.restClient,
+				logger:     tt.fields.logger,
+			}
+			err := c.DeleteSubUserMonitor(tt.args.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteSubUserMonitor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("DeleteSubUserMonitor() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_GetEventWebhookSettings(t *testing.T) {
+	type fields struct {
+		restClient RESTClient
+		logger     *logrus.Entry
+	}
+	type args struct {
+		username string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *EventWebhookSettings
+		wantErr bool
+	}{
+		{
+			name: "successful get",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(200, mockJSON(newMockEventWebhookSettings())),
+				logger:     newMockLogger(),
+			},
+			args: args{username: "test"},
+			want: newMockEventWebhookSettings(),
+		},
+		{
+			name: "username not defined",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: ""},
+			wantErr: true,
+		},
+		{
+			name: "unexpected status code",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(401, "{}"),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+		{
+			name: "get request fails",
+			fields: fields{
+				restClient: mockRESTClientFailedInvoke,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+		{
+			name: "api response invalid json",
+			fields: fields{
+				restClient: mockRESTClientInvalidJSON,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.fields.restClient,
+				logger:     tt.fields.logger,
+			}
+			got, err := c.GetEventWebhookSettings(tt.args.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetEventWebhookSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetEventWebhookSettings() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_UpdateEventWebhookSettings(t *testing.T) {
+	type fields struct {
+		restClient RESTClient
+		logger     *logrus.Entry
+	}
+	type args struct {
+		username string
+		settings *EventWebhookSettings
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *EventWebhookSettings
+		wantErr bool
+	}{
+		{
+			name: "successful update",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(200, mockJSON(newMockEventWebhookSettings())),
+				logger:     newMockLogger(),
+			},
+			args: args{username: "test", settings: newMockEventWebhookSettings()},
+			want: newMockEventWebhookSettings(),
+		},
+		{
+			name: "settings not defined",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", settings: nil},
+			wantErr: true,
+		},
+		{
+			name: "unexpected status code",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(400, "{}"),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", settings: newMockEventWebhookSettings()},
+			wantErr: true,
+		},
+		{
+			name: "update request fails",
+			fields: fields{
+				restClient: mockRESTClientFailedInvoke,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", settings: newMockEventWebhookSettings()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.fields.restClient,
+				logger:     tt.fields.logger,
+			}
+			got, err := c.UpdateEventWebhookSettings(tt.args.username, tt.args.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateEventWebhookSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpdateEventWebhookSettings() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_TestEventWebhook(t *testing.T) {
+	type fields struct {
+		restClient RESTClient
+		logger     *logrus.Entry
+	}
+	type args struct {
+		username string
+		url      string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "successful test",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(204, ""),
+				logger:     newMockLogger(),
+			},
+			args: args{username: "test", url: "https://events.test.com/test"},
+		},
+		{
+			name: "url not defined",
+			fields: fields{
+				restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", url: ""},
+			wantErr: true,
+		},
+		{
+			name: "unexpected status code",
+			fields: fields{
+				restClient: newMockRESTClientWithResponse(400, "{}"),
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", url: "https://events.test.com/test"},
+			wantErr: true,
+		},
+		{
+			name: "test request fails",
+			fields: fields{
+				restClient: mockRESTClientFailedInvoke,
+				logger:     newMockLogger(),
+			},
+			args:    args{username: "test", url: "https://events.test.com/test"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.fields.restClient,
+				logger:     tt.fields.logger,
+			}
+			if err := c.TestEventWebhook(tt.args.username, tt.args.url); (err != nil) != tt.wantErr {
+				t.Errorf("TestEventWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_ListIPPools(t *testing.T) {
+	tests := []struct {
+		name       string
+		restClient RESTClient
+		want       []*IPPool
+		wantErr    bool
+	}{
+		{
+			name:       "successful list",
+			restClient: newMockRESTClientWithResponse(200, `[{"name":"test"}]`),
+			want:       []*IPPool{{Name: "test"}},
+		},
+		{
+			name:       "unexpected status code",
+			restClient: newMockRESTClientWithResponse(403, "{}"),
+			wantErr:    true,
+		},
+		{
+			name:       "list request fails",
+			restClient: mockRESTClientFailedInvoke,
+			wantErr:    true,
+		},
+		{
+			name:       "api response invalid json",
+			restClient: mockRESTClientInvalidJSON,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			got, err := c.ListIPPools()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListIPPools() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListIPPools() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_CreateIPPool(t *testing.T) {
+	tests := []struct {
+		name       string
+		restClient RESTClient
+		poolName   string
+		want       *IPPool
+		wantErr    bool
+	}{
+		{
+			name:       "successful create",
+			restClient: newMockRESTClientWithResponse(200, `{"name":"test"}`),
+			poolName:   "test",
+			want:       &IPPool{Name: "test"},
+		},
+		{
+			name:       "name not defined",
+			restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+			poolName:   "",
+			wantErr:    true,
+		},
+		{
+			name:       "unexpected status code",
+			restClient: newMockRESTClientWithResponse(400, "{}"),
+			poolName:   "test",
+			wantErr:    true,
+		},
+		{
+			name:       "create request fails",
+			restClient: mockRESTClientFailedInvoke,
+			poolName:   "test",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			got, err := c.CreateIPPool(tt.poolName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateIPPool() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateIPPool() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_AddIPToPool(t *testing.T) {
+	tests := []struct {
+		name       string
+		restClient RESTClient
+		poolName   string
+		ip         string
+		wantErr    bool
+	}{
+		{
+			name:       "successful add",
+			restClient: newMockRESTClientWithResponse(201, `{"ip":"127.0.0.1"}`),
+			poolName:   "test",
+			ip:         "127.0.0.1",
+		},
+		{
+			name:       "ip not defined",
+			restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+			poolName:   "test",
+			ip:         "",
+			wantErr:    true,
+		},
+		{
+			name:       "unexpected status code",
+			restClient: newMockRESTClientWithResponse(404, "{}"),
+			poolName:   "test",
+			ip:         "127.0.0.1",
+			wantErr:    true,
+		},
+		{
+			name:       "add request fails",
+			restClient: mockRESTClientFailedInvoke,
+			poolName:   "test",
+			ip:         "127.0.0.1",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			if err := c.AddIPToPool(tt.poolName, tt.ip); (err != nil) != tt.wantErr {
+				t.Errorf("AddIPToPool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_RemoveIPFromPool(t *testing.T) {
+	tests := []struct {
+		name         string
+		restClient   RESTClient
+		poolName     string
+		ip           string
+		wantErr      bool
+		wantNotExist bool
+	}{
+		{
+			name:       "successful remove",
+			restClient: newMockRESTClientWithResponse(204, ""),
+			poolName:   "test",
+			ip:         "127.0.0.1",
+		},
+		{
+			name:       "name not defined",
+			restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+			poolName:   "",
+			ip:         "127.0.0.1",
+			wantErr:    true,
+		},
+		{
+			name:         "ip not in pool",
+			restClient:   newMockRESTClientWithResponse(404, "{}"),
+			poolName:     "test",
+			ip:           "127.0.0.1",
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name:       "remove request fails",
+			restClient: mockRESTClientFailedInvoke,
+			poolName:   "test",
+			ip:         "127.0.0.1",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			err := c.RemoveIPFromPool(tt.poolName, tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RemoveIPFromPool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("RemoveIPFromPool() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_GetCredits(t *testing.T) {
+	tests := []struct {
+		name       string
+		restClient RESTClient
+		want       *Credits
+		wantErr    bool
+	}{
+		{
+			name:       "successful get",
+			restClient: newMockRESTClientWithResponse(200, mockJSON(newMockCredits())),
+			want:       newMockCredits(),
+		},
+		{
+			name:       "unexpected status code",
+			restClient: newMockRESTClientWithResponse(403, "{}"),
+			wantErr:    true,
+		},
+		{
+			name:       "get request fails",
+			restClient: mockRESTClientFailedInvoke,
+			wantErr:    true,
+		},
+		{
+			name:       "api response invalid json",
+			restClient: mockRESTClientInvalidJSON,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			got, err := c.GetCredits()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCredits() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCredits() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_ListWarmupIPs(t *testing.T) {
+	tests := []struct {
+		name       string
+		restClient RESTClient
+		want       []*WarmupIP
+		wantErr    bool
+	}{
+		{
+			name:       "successful list",
+			restClient: newMockRESTClientWithResponse(200, mockJSON([]*WarmupIP{newMockWarmupIP()})),
+			want:       []*WarmupIP{newMockWarmupIP()},
+		},
+		{
+			name:       "unexpected status code",
+			restClient: newMockRESTClientWithResponse(500, "{}"),
+			wantErr:    true,
+		},
+		{
+			name:       "list request fails",
+			restClient: mockRESTClientFailedInvoke,
+			wantErr:    true,
+		},
+		{
+			name:       "api response invalid json",
+			restClient: mockRESTClientInvalidJSON,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			got, err := c.ListWarmupIPs()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListWarmupIPs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListWarmupIPs() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_GetIPWarmupStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		restClient   RESTClient
+		ip           string
+		want         *WarmupIP
+		wantErr      bool
+		wantNotExist bool
+	}{
+		{
+			name:       "successful get",
+			restClient: newMockRESTClientWithResponse(200, mockJSON([]*WarmupIP{newMockWarmupIP()})),
+			ip:         newMockWarmupIP().IP,
+			want:       newMockWarmupIP(),
+		},
+		{
+			name:       "ip not defined",
+			restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+			ip:         "",
+			wantErr:    true,
+		},
+		{
+			name:         "ip not warming up",
+			restClient:   newMockRESTClientWithResponse(404, "{}"),
+			ip:           newMockWarmupIP().IP,
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name:         "ip missing from response",
+			restClient:   newMockRESTClientWithResponse(200, "[]"),
+			ip:           newMockWarmupIP().IP,
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name:       "api response invalid json",
+			restClient: mockRESTClientInvalidJSON,
+			ip:         newMockWarmupIP().IP,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			got, err := c.GetIPWarmupStatus(tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetIPWarmupStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("GetIPWarmupStatus() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetIPWarmupStatus() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_StartIPWarmup(t *testing.T) {
+	tests := []struct {
+		name       string
+		restClient RESTClient
+		ip         string
+		want       *WarmupIP
+		wantErr    bool
+	}{
+		{
+			name:       "successful start",
+			restClient: newMockRESTClientWithResponse(200, mockJSON([]*WarmupIP{newMockWarmupIP()})),
+			ip:         newMockWarmupIP().IP,
+			want:       newMockWarmupIP(),
+		},
+		{
+			name:       "ip not defined",
+			restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+			ip:         "",
+			wantErr:    true,
+		},
+		{
+			name:       "unexpected status code",
+			restClient: newMockRESTClientWithResponse(400, "{}"),
+			ip:         newMockWarmupIP().IP,
+			wantErr:    true,
+		},
+		{
+			name:       "start request fails",
+			restClient: mockRESTClientFailedInvoke,
+			ip:         newMockWarmupIP().IP,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			got, err := c.StartIPWarmup(tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StartIPWarmup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StartIPWarmup() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_StopIPWarmup(t *testing.T) {
+	tests := []struct {
+		name         string
+		restClient   RESTClient
+		ip           string
+		wantErr      bool
+		wantNotExist bool
+	}{
+		{
+			name:       "successful stop",
+			restClient: newMockRESTClientWithResponse(204, ""),
+			ip:         newMockWarmupIP().IP,
+		},
+		{
+			name:       "ip not defined",
+			restClient: newMockRESTClient(func(c *RESTClientMock) {}),
+			ip:         "",
+			wantErr:    true,
+		},
+		{
+			name:         "ip not warming up",
+			restClient:   newMockRESTClientWithResponse(404, "{}"),
+			ip:           newMockWarmupIP().IP,
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name:       "stop request fails",
+			restClient: mockRESTClientFailedInvoke,
+			ip:         newMockWarmupIP().IP,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: tt.restClient,
+				logger:     newMockLogger(),
+			}
+			err := c.StopIPWarmup(tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StopIPWarmup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("StopIPWarmup() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+		})
+	}
+}
+
+func TestBackendAPIClient_APIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(c *BackendAPIClient) error
+		statusCode int
+	}{
+		{
+			name: "list sub users unauthorized",
+			call: func(c *BackendAPIClient) error {
+				_, err := c.ListSubUsers(nil)
+				return err
+			},
+			statusCode: 401,
+		},
+		{
+			name: "list api keys forbidden",
+			call: func(c *BackendAPIClient) error {
+				_, err := c.GetAPIKeysForSubUser("test")
+				return err
+			},
+			statusCode: 403,
+		},
+		{
+			name: "create sub user rate limited",
+			call: func(c *BackendAPIClient) error {
+				_, err := c.CreateSubUser("test", "test@email.com", "password", nil)
+				return err
+			},
+			statusCode: 429,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &BackendAPIClient{
+				restClient: newMockRESTClientWithResponse(tt.statusCode, `{"errors":[]}`),
+				logger:     newMockLogger(),
+			}
+			err := tt.call(c)
+			apiErr, ok := err.(*APIError)
+			if !ok || !IsAPIError(err) {
+				t.Fatalf("expected api error, got %v", err)
+			}
+			if apiErr.StatusCode != tt.statusCode {
+				t.Fatalf("expected status code %d, got %d", tt.statusCode, apiErr.StatusCode)
+			}
+		})
+	}
+}
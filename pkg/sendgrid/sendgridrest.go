@@ -1,6 +1,10 @@
 package sendgrid
 
 import (
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/sendgrid/rest"
 	sg "github.com/sendgrid/sendgrid-go"
 	"github.com/sirupsen/logrus"
@@ -17,9 +21,18 @@ type RESTClient interface {
 
 //BackendRESTClient Thin wrapper around the SendGrid library
 type BackendRESTClient struct {
-	apiHost string
-	apiKey  string
-	logger  *logrus.Entry
+	apiHost          string
+	apiKey           string
+	rateLimitRetries int
+	logger           *logrus.Entry
+}
+
+//WithRateLimitRetries Retry API requests rate limited by SendGrid up to retries times, waiting until the rate limit
+//resets, by default rate limited requests fail immediately
+func WithRateLimitRetries(retries int) ClientOption {
+	return func(c *Client) {
+		c.rateLimitRetries = retries
+	}
 }
 
 //NewBackendRESTClient Create a new BackendAPIClient with default logger labels
@@ -42,5 +55,42 @@ func (c *BackendRESTClient) BuildRequest(endpoint string, method rest.Method) re
 //InvokeRequest Invoke a REST request against the SendGrid API
 func (c *BackendRESTClient) InvokeRequest(request rest.Request) (*rest.Response, error) {
 	c.logger.Debugf("performing api request with details, url=%s method=%s body=%s", request.BaseURL, request.Method, string(request.Body))
-	return sg.API(request)
+	route, method := metricRoute(request.BaseURL), string(request.Method)
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := sg.API(request)
+		apiRequestDuration.Observe(time.Since(start).Seconds(), route, method)
+		if err != nil {
+			apiRequestsTotal.Inc(route, method, MetricStatusError)
+			return nil, err
+		}
+		apiRequestsTotal.Inc(route, method, strconv.Itoa(resp.StatusCode))
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= c.rateLimitRetries {
+			return resp, nil
+		}
+		wait := rateLimitWait(resp, time.Now())
+		c.logger.Warnf("api request rate limited, retrying in %s, url=%s method=%s attempt=%d", wait, request.BaseURL, request.Method, attempt+1)
+		apiRetriesTotal.Inc(route, method)
+		time.Sleep(wait)
+	}
+}
+
+//rateLimitWait Time until the rate limit of a rate limited response resets, from its X-RateLimit-Reset header
+func rateLimitWait(resp *rest.Response, now time.Time) time.Duration {
+	reset := http.Header(resp.Headers).Get(HeaderRateLimitReset)
+	if reset == "" {
+		return DefaultRateLimitWait
+	}
+	unix, err := strconv.ParseInt(reset, 10, 64)
+	if err != nil {
+		return DefaultRateLimitWait
+	}
+	wait := time.Unix(unix, 0).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	if wait > MaxRateLimitWait {
+		return MaxRateLimitWait
+	}
+	return wait
 }
@@ -2,7 +2,11 @@ package sendgrid
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sendgrid/rest"
 	"github.com/sirupsen/logrus"
@@ -76,3 +80,141 @@ func TestBackendRESTClient_BuildRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestBackendRESTClient_InvokeRequest(t *testing.T) {
+	tests := []struct {
+		name             string
+		rateLimitRetries int
+		responses        []int
+		wantStatus       int
+		wantRequests     int
+		wantRetries      float64
+	}{
+		{
+			name:         "successful request should not be retried",
+			responses:    []int{200},
+			wantStatus:   200,
+			wantRequests: 1,
+		},
+		{
+			name:         "rate limited request should not be retried by default",
+			responses:    []int{429, 200},
+			wantStatus:   429,
+			wantRequests: 1,
+		},
+		{
+			name:             "rate limited request should be retried until it succeeds",
+			rateLimitRetries: 3,
+			responses:        []int{429, 429, 200},
+			wantStatus:       200,
+			wantRequests:     3,
+			wantRetries:      2,
+		},
+		{
+			name:             "rate limited request should fail once the retries are used up",
+			rateLimitRetries: 1,
+			responses:        []int{429, 429, 200},
+			wantStatus:       429,
+			wantRequests:     2,
+			wantRetries:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.responses[requests]
+				requests++
+				if status == http.StatusTooManyRequests {
+					w.Header().Set(HeaderRateLimitReset, strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+			c := NewBackendRESTClient(server.URL, testAPIKey, newMockLogger())
+			c.rateLimitRetries = tt.rateLimitRetries
+			retriesBefore := apiRetriesTotal.Value(APIRouteCredits, string(rest.Get))
+			got, err := c.InvokeRequest(c.BuildRequest(APIRouteCredits, rest.Get))
+			if err != nil {
+				t.Fatalf("InvokeRequest() error = %v", err)
+			}
+			if got.StatusCode != tt.wantStatus {
+				t.Errorf("InvokeRequest() status = %d, want %d", got.StatusCode, tt.wantStatus)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("InvokeRequest() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+			if retries := apiRetriesTotal.Value(APIRouteCredits, string(rest.Get)) - retriesBefore; retries != tt.wantRetries {
+				t.Errorf("InvokeRequest() recorded %v retries, want %v", retries, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tests := []struct {
+		name  string
+		reset string
+		want  time.Duration
+	}{
+		{
+			name: "missing reset should wait the default",
+			want: DefaultRateLimitWait,
+		},
+		{
+			name:  "invalid reset should wait the default",
+			reset: "soon",
+			want:  DefaultRateLimitWait,
+		},
+		{
+			name:  "future reset should wait until the reset",
+			reset: "1600000005",
+			want:  5 * time.Second,
+		},
+		{
+			name:  "past reset should not wait",
+			reset: "1599999995",
+			want:  0,
+		},
+		{
+			name:  "distant reset should wait the maximum",
+			reset: "1600003600",
+			want:  MaxRateLimitWait,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &rest.Response{Headers: map[string][]string{}}
+			if tt.reset != "" {
+				http.Header(resp.Headers).Set(HeaderRateLimitReset, tt.reset)
+			}
+			if got := rateLimitWait(resp, now); got != tt.want {
+				t.Errorf("rateLimitWait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricRoute(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: APIHost + APIRouteSubUsers, want: APIRouteSubUsers},
+		{url: APIHost + "/v3/subusers/test", want: "/v3/subusers/{username}"},
+		{url: APIHost + "/v3/subusers/test/monitor", want: "/v3/subusers/{username}/monitor"},
+		{url: APIHost + "/v3/ips/pools", want: APIRouteIPPools},
+		{url: APIHost + "/v3/ips/warmup", want: APIRouteIPWarmup},
+		{url: APIHost + "/v3/ips/warmup/127.0.0.1", want: "/v3/ips/warmup/{ip}"},
+		{url: APIHost + "/v3/ips/pools/test/ips/127.0.0.1", want: "/v3/ips/pools/{name}/ips/{ip}"},
+		{url: APIHost + "/v3/unknown", want: MetricRouteOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := metricRoute(tt.url); got != tt.want {
+				t.Errorf("metricRoute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
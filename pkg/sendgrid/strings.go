@@ -1,28 +1,122 @@
 package sendgrid
 
+import (
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
 const (
 	//ProviderName Standardised name of the SendGrid provider
 	ProviderName = "sendgrid"
 	//EnvAPIKey Name of the env var to retrieve the SendGrid API key
 	EnvAPIKey = "SENDGRID_API_KEY"
+	//EnvTierIPPools Name of the env var to retrieve the comma separated tier=pool IP pool declarations
+	EnvTierIPPools = "SENDGRID_TIER_IP_POOLS"
+	//EnvRegion Name of the env var to retrieve the SendGrid data residency region
+	EnvRegion = "SENDGRID_REGION"
+	//EnvAPIHost Name of the env var to retrieve a SendGrid API host overriding the host of the region
+	EnvAPIHost = "SENDGRID_API_HOST"
+	//EnvAccounts Name of the env var to retrieve the comma separated name=api_key pairs of several master accounts
+	EnvAccounts = "SENDGRID_ACCOUNTS"
+	//EnvPlacement Name of the env var to retrieve the policy placing new sub users in a master account
+	EnvPlacement = "SENDGRID_PLACEMENT"
+	//EnvMaxSubUsers Name of the env var to retrieve the maximum number of sub users of a master account
+	EnvMaxSubUsers = "SENDGRID_MAX_SUB_USERS"
+	//DefaultAccountName Name of the master account of the SENDGRID_API_KEY env var
+	DefaultAccountName = "default"
+	//AnnotationAccount Secret annotation recording the master account holding the sub user of a cluster
+	AnnotationAccount = "smtp.integr8ly.org/sendgrid-account"
 	//APIHost SendGrid API default host
 	APIHost = "https://api.sendgrid.com"
+	//APIHostEU SendGrid API host of the EU data residency region
+	APIHostEU = "https://api.eu.sendgrid.com"
 	//APIRouteSubUsers SendGrid v3 API endpoint for sub user management
 	APIRouteSubUsers = "/v3/subusers"
 	//APIRouteAPIKeys SendGrid v3 API endpoint for api key management
 	APIRouteAPIKeys = "/v3/api_keys"
 	//APIRouteIPAddresses SendGrid v3 API endpoint for ip address management
 	APIRouteIPAddresses = "/v3/ips"
+	//APIRouteIPPools SendGrid v3 API endpoint for ip pool management
+	APIRouteIPPools = "/v3/ips/pools"
+	//APIRouteIPWarmup SendGrid v3 API endpoint for ip warmup management
+	APIRouteIPWarmup = "/v3/ips/warmup"
+	//APIRouteSubUserMonitor SendGrid v3 API endpoint format for sub user monitor management, requires the sub user name
+	APIRouteSubUserMonitor = "/v3/subusers/%s/monitor"
+	//APIRouteEventWebhookSettings SendGrid v3 API endpoint for event webhook settings management
+	APIRouteEventWebhookSettings = "/v3/user/webhooks/event/settings"
+	//APIRouteEventWebhookTest SendGrid v3 API endpoint for sending a test event to an event webhook
+	APIRouteEventWebhookTest = "/v3/user/webhooks/event/test"
+	//APIRouteCredits SendGrid v3 API endpoint for the email credits of the authenticated user
+	APIRouteCredits = "/v3/user/credits"
 	//HeaderOnBehalfOf SendGrid v3 header for declaring an action is on behalf of a sub user
 	HeaderOnBehalfOf = "on-behalf-of"
+	//HeaderRateLimitReset SendGrid v3 response header with the unix time the rate limit of a rate limited request resets
+	HeaderRateLimitReset = "X-RateLimit-Reset"
+	//LogFieldAccount Logging field name for the name of a master account
+	LogFieldAccount = "sendgrid_account"
 	//LogFieldAPIClient Logging field name for a description of the API client
 	LogFieldAPIClient = "sendgrid_service_api_client"
 	//ConnectionDetailsHost Default SendGrid host
 	ConnectionDetailsHost = "smtp.sendgrid.net"
+	//ConnectionDetailsHostEU SendGrid host of the EU data residency region
+	ConnectionDetailsHostEU = "smtp.eu.sendgrid.net"
 	//ConnectionDetailsPort Default SendGrid port
 	ConnectionDetailsPort = 587
 	//ConnectionDetailsTLS Default SendGrid TLS setting
 	ConnectionDetailsTLS = true
+	//ConnectionDetailsTLSMode Default SendGrid TLS mode, SendGrid supports STARTTLS on port 587
+	ConnectionDetailsTLSMode = smtpdetails.TLSModeStartTLS
 	//ConnectionDetailsUsername Default SendGrid SMTP auth username
 	ConnectionDetailsUsername = "apikey"
+	//DefaultRateLimitWait Time waited before retrying a rate limited request without a rate limit reset time
+	DefaultRateLimitWait = time.Second
+	//MaxRateLimitWait Maximum time waited before retrying a rate limited request
+	MaxRateLimitWait = time.Minute
+	//SubUserUsernameMaxLength Maximum length of a sub user username, which is also the local part of its email address
+	SubUserUsernameMaxLength = 64
+	//MaxAPIKeysPerSubUser Maximum number of API keys SendGrid allows a sub user to have
+	MaxAPIKeysPerSubUser = 100
+	//SubUserListPageSize Number of sub users requested per page when listing sub users
+	SubUserListPageSize = 100
+	//DefaultCapacityThreshold Default fraction of a limit in use at which a capacity warning is raised
+	DefaultCapacityThreshold = 0.8
+)
+
+const (
+	//EventProcessed Event webhook type sent when a message has been received and is ready to be delivered
+	EventProcessed = "processed"
+	//EventDropped Event webhook type sent when a message will not be delivered
+	EventDropped = "dropped"
+	//EventDelivered Event webhook type sent when a message has been accepted by the receiving server
+	EventDelivered = "delivered"
+	//EventDeferred Event webhook type sent when the receiving server temporarily rejected a message
+	EventDeferred = "deferred"
+	//EventBounce Event webhook type sent when the receiving server permanently rejected a message
+	EventBounce = "bounce"
+	//EventOpen Event webhook type sent when a recipient opened a message
+	EventOpen = "open"
+	//EventClick Event webhook type sent when a recipient clicked a link in a message
+	EventClick = "click"
+	//EventSpamReport Event webhook type sent when a recipient marked a message as spam
+	EventSpamReport = "spamreport"
+	//EventUnsubscribe Event webhook type sent when a recipient unsubscribed from all messages
+	EventUnsubscribe = "unsubscribe"
+	//EventGroupUnsubscribe Event webhook type sent when a recipient unsubscribed from a suppression group
+	EventGroupUnsubscribe = "group_unsubscribe"
+	//EventGroupResubscribe Event webhook type sent when a recipient resubscribed to a suppression group
+	EventGroupResubscribe = "group_resubscribe"
+)
+
+const (
+	//MetricAPIRequestsTotal Prometheus metric name of the SendGrid API request counters
+	MetricAPIRequestsTotal = "smtp_service_sendgrid_api_requests_total"
+	//MetricAPIRequestDurationSeconds Prometheus metric name of the SendGrid API request duration histograms
+	MetricAPIRequestDurationSeconds = "smtp_service_sendgrid_api_request_duration_seconds"
+	//MetricAPIRetriesTotal Prometheus metric name of the SendGrid API rate limit retry counters
+	MetricAPIRetriesTotal = "smtp_service_sendgrid_api_retries_total"
+	//MetricStatusError Status label value of API requests that failed without a response
+	MetricStatusError = "error"
+	//MetricRouteOther Route label value of API requests to a route not used by the client
+	MetricRouteOther = "other"
 )
@@ -1,5 +1,9 @@
 package sendgrid
 
+import (
+	"time"
+)
+
 //SubUser A SendGrid sub user, from https://sendgrid.com/docs/API_Reference/Web_API_v3/subusers.html
 type SubUser struct {
 	ID       int    `json:"id"`
@@ -25,3 +29,70 @@ type IPAddress struct {
 	RDNS      string   `json:"rdns"`
 	Pools     []string `json:"pools"`
 }
+
+//DaysInWarmup Number of whole days the IP address has been warming up for, zero if it is not warming up
+func (ip *IPAddress) DaysInWarmup(now time.Time) int {
+	if !ip.Warmup {
+		return 0
+	}
+	return daysSince(ip.StartDate, now)
+}
+
+//Monitor A SendGrid sub user monitor, from https://sendgrid.com/docs/API_Reference/Web_API_v3/subusers.html
+type Monitor struct {
+	Email     string `json:"email"`
+	Frequency int    `json:"frequency"`
+}
+
+//EventWebhookSettings SendGrid event webhook settings, from https://sendgrid.com/docs/API_Reference/Web_API_v3/Webhooks/event.html
+type EventWebhookSettings struct {
+	Enabled          bool   `json:"enabled"`
+	URL              string `json:"url"`
+	GroupResubscribe bool   `json:"group_resubscribe"`
+	Delivered        bool   `json:"delivered"`
+	GroupUnsubscribe bool   `json:"group_unsubscribe"`
+	SpamReport       bool   `json:"spam_report"`
+	Bounce           bool   `json:"bounce"`
+	Deferred         bool   `json:"deferred"`
+	Unsubscribe      bool   `json:"unsubscribe"`
+	Processed        bool   `json:"processed"`
+	Open             bool   `json:"open"`
+	Click            bool   `json:"click"`
+	Dropped          bool   `json:"dropped"`
+}
+
+//IPPool A SendGrid IP pool, from https://sendgrid.com/docs/API_Reference/Web_API_v3/IP_Management/ip_pools.html
+type IPPool struct {
+	Name string       `json:"name"`
+	IPs  []*IPAddress `json:"ips,omitempty"`
+}
+
+//Credits The email credits of a SendGrid account, from https://sendgrid.com/docs/API_Reference/Web_API_v3/credits.html
+type Credits struct {
+	Remain         int    `json:"remain"`
+	Total          int    `json:"total"`
+	Overage        int    `json:"overage"`
+	Used           int    `json:"used"`
+	LastReset      string `json:"last_reset"`
+	NextReset      string `json:"next_reset"`
+	ResetFrequency string `json:"reset_frequency"`
+}
+
+//WarmupIP A SendGrid IP address in warmup, from https://sendgrid.com/docs/API_Reference/Web_API_v3/IP_Management/ip_warmup.html
+type WarmupIP struct {
+	IP        string `json:"ip"`
+	StartDate int    `json:"start_date"`
+}
+
+//DaysInWarmup Number of whole days the IP address has been warming up for
+func (w *WarmupIP) DaysInWarmup(now time.Time) int {
+	return daysSince(w.StartDate, now)
+}
+
+func daysSince(unixTime int, now time.Time) int {
+	days := int(now.Sub(time.Unix(int64(unixTime), 0)).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
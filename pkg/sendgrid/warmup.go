@@ -0,0 +1,59 @@
+package sendgrid
+
+import (
+	"sort"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+)
+
+//WithExcludeWarmupIPs Never assign IP addresses that are warming up to sub users created by Create
+func WithExcludeWarmupIPs() ClientOption {
+	return func(c *Client) {
+		c.excludeWarmupIPs = true
+	}
+}
+
+//ListWarmupIPs List the IP addresses of the SendGrid account that are warming up, ordered by IP
+func (c *Client) ListWarmupIPs() ([]*WarmupIP, error) {
+	warmupIPs, err := c.sendgridClient.ListWarmupIPs()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list warmup ip addresses")
+	}
+	sort.Slice(warmupIPs, func(i, j int) bool {
+		return warmupIPs[i].IP < warmupIPs[j].IP
+	})
+	return warmupIPs, nil
+}
+
+//GetIPWarmupStatus Get the warmup status of an IP address of the SendGrid account
+func (c *Client) GetIPWarmupStatus(ip string) (*WarmupIP, error) {
+	warmupIP, err := c.sendgridClient.GetIPWarmupStatus(ip)
+	if err != nil {
+		if IsNotExistError(err) {
+			return nil, &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return nil, errors.Wrapf(err, "failed to get warmup status of ip %s", ip)
+	}
+	return warmupIP, nil
+}
+
+//StartIPWarmup Start warming up an IP address of the SendGrid account
+func (c *Client) StartIPWarmup(ip string) (*WarmupIP, error) {
+	warmupIP, err := c.sendgridClient.StartIPWarmup(ip)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to start warmup of ip %s", ip)
+	}
+	return warmupIP, nil
+}
+
+//StopIPWarmup Stop warming up an IP address of the SendGrid account
+func (c *Client) StopIPWarmup(ip string) error {
+	if err := c.sendgridClient.StopIPWarmup(ip); err != nil {
+		if IsNotExistError(err) {
+			return &smtpdetails.NotExistError{Message: err.Error()}
+		}
+		return errors.Wrapf(err, "failed to stop warmup of ip %s", ip)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package sendgrid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
+func TestWarmupIP_DaysInWarmup(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := &WarmupIP{IP: "127.0.0.1", StartDate: int(start.Unix())}
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{name: "same day", now: start.Add(time.Hour), want: 0},
+		{name: "partial days are not counted", now: start.Add(47 * time.Hour), want: 1},
+		{name: "whole days", now: start.AddDate(0, 0, 30), want: 30},
+		{name: "start date in the future", now: start.Add(-48 * time.Hour), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.DaysInWarmup(tt.now); got != tt.want {
+				t.Errorf("DaysInWarmup() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPAddress_DaysInWarmup(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 5)
+	if got := (&IPAddress{Warmup: true, StartDate: int(start.Unix())}).DaysInWarmup(now); got != 5 {
+		t.Errorf("DaysInWarmup() warming got = %v, want 5", got)
+	}
+	if got := (&IPAddress{Warmup: false, StartDate: int(start.Unix())}).DaysInWarmup(now); got != 0 {
+		t.Errorf("DaysInWarmup() not warming got = %v, want 0", got)
+	}
+}
+
+func TestClient_ListWarmupIPs(t *testing.T) {
+	c := &Client{
+		sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+			c.ListWarmupIPsFunc = func() ([]*WarmupIP, error) {
+				return []*WarmupIP{{IP: "127.0.0.2"}, {IP: "127.0.0.1"}}, nil
+			}
+		}),
+		logger: newMockLogger(),
+	}
+	got, err := c.ListWarmupIPs()
+	if err != nil {
+		t.Fatalf("ListWarmupIPs() error = %v", err)
+	}
+	want := []*WarmupIP{{IP: "127.0.0.1"}, {IP: "127.0.0.2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListWarmupIPs() got = %v, want %v", got, want)
+	}
+}
+
+func TestClient_GetIPWarmupStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		sendgridClient APIClient
+		want           *WarmupIP
+		wantErr        bool
+		wantNotExist   bool
+	}{
+		{
+			name:           "ip warming up",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}),
+			want:           newMockWarmupIP(),
+		},
+		{
+			name: "ip not warming up",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetIPWarmupStatusFunc = func(ip string) (*WarmupIP, error) {
+					return nil, &NotExistError{Message: "test"}
+				}
+			}),
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "request fails",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetIPWarmupStatusFunc = func(ip string) (*WarmupIP, error) {
+					return nil, errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient: tt.sendgridClient,
+				logger:         newMockLogger(),
+			}
+			got, err := c.GetIPWarmupStatus("127.0.0.1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetIPWarmupStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if smtpdetails.IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("GetIPWarmupStatus() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetIPWarmupStatus() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_StopIPWarmup(t *testing.T) {
+	c := &Client{
+		sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+			c.StopIPWarmupFunc = func(ip string) error {
+				return &NotExistError{Message: "test"}
+			}
+		}),
+		logger: newMockLogger(),
+	}
+	if err := c.StopIPWarmup("127.0.0.1"); !smtpdetails.IsNotExistError(err) {
+		t.Errorf("StopIPWarmup() error = %v, want NotExistError", err)
+	}
+}
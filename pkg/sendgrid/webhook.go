@@ -0,0 +1,177 @@
+package sendgrid
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+	"github.com/pkg/errors"
+)
+
+var (
+	//DefaultEventWebhookEvents The event types enabled on an event webhook when none are specified
+	DefaultEventWebhookEvents = []string{EventDelivered, EventBounce, EventDropped, EventSpamReport}
+)
+
+//eventWebhookURLData Data available to an event webhook url template
+type eventWebhookURLData struct {
+	ClusterID string
+}
+
+//WithEventWebhook Configure an event webhook for every sub user handled by Create, see RenderEventWebhookURL for urlTemplate
+func WithEventWebhook(urlTemplate string, events []string) ClientOption {
+	return func(c *Client) {
+		c.eventWebhookURLTemplate = urlTemplate
+		c.eventWebhookEvents = events
+	}
+}
+
+//RenderEventWebhookURL Render an event webhook url template for a cluster, the cluster ID is available as {{.ClusterID}}
+func RenderEventWebhookURL(urlTemplate, id string) (string, error) {
+	tmpl, err := template.New("webhook").Option("missingkey=error").Parse(urlTemplate)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse event webhook url template %s", urlTemplate)
+	}
+	var url bytes.Buffer
+	if err := tmpl.Execute(&url, &eventWebhookURLData{ClusterID: id}); err != nil {
+		return "", errors.Wrapf(err, "failed to render event webhook url template %s", urlTemplate)
+	}
+	return url.String(), nil
+}
+
+//NewEventWebhookSettings Create enabled event webhook settings posting the provided event types to url
+func NewEventWebhookSettings(url string, events []string) (*EventWebhookSettings, error) {
+	if url == "" {
+		return nil, errors.New("url must be a non-empty string")
+	}
+	if len(events) == 0 {
+		events = DefaultEventWebhookEvents
+	}
+	settings := &EventWebhookSettings{
+		Enabled: true,
+		URL:     url,
+	}
+	for _, e := range events {
+		switch e {
+		case EventProcessed:
+			settings.Processed = true
+		case EventDropped:
+			settings.Dropped = true
+		case EventDelivered:
+			settings.Delivered = true
+		case EventDeferred:
+			settings.Deferred = true
+		case EventBounce:
+			settings.Bounce = true
+		case EventOpen:
+			settings.Open = true
+		case EventClick:
+			settings.Click = true
+		case EventSpamReport:
+			settings.SpamReport = true
+		case EventUnsubscribe:
+			settings.Unsubscribe = true
+		case EventGroupUnsubscribe:
+			settings.GroupUnsubscribe = true
+		case EventGroupResubscribe:
+			settings.GroupResubscribe = true
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown event webhook event type %s", e))
+		}
+	}
+	return settings, nil
+}
+
+//EnabledEvents Event types the settings post to the event webhook, in the order of the SendGrid documentation
+func (s *EventWebhookSettings) EnabledEvents() []string {
+	events := []string{}
+	for _, e := range []struct {
+		name    string
+		enabled bool
+	}{
+		{EventProcessed, s.Processed},
+		{EventDropped, s.Dropped},
+		{EventDelivered, s.Delivered},
+		{EventDeferred, s.Deferred},
+		{EventBounce, s.Bounce},
+		{EventOpen, s.Open},
+		{EventClick, s.Click},
+		{EventSpamReport, s.SpamReport},
+		{EventUnsubscribe, s.Unsubscribe},
+		{EventGroupUnsubscribe, s.GroupUnsubscribe},
+		{EventGroupResubscribe, s.GroupResubscribe},
+	} {
+		if e.enabled {
+			events = append(events, e.name)
+		}
+	}
+	return events
+}
+
+//GetEventWebhook Retrieve the event webhook settings of the SendGrid sub user associated with a cluster by it's ID
+func (c *Client) GetEventWebhook(id string) (*EventWebhookSettings, error) {
+	subuser, err := c.getExistingSubUser(id)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := c.sendgridClient.GetEventWebhookSettings(subuser.Username)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get event webhook settings for sub user %s", id)
+	}
+	return settings, nil
+}
+
+//SetEventWebhook Enable the event webhook of the SendGrid sub user associated with a cluster by it's ID
+func (c *Client) SetEventWebhook(id, urlTemplate string, events []string) (*EventWebhookSettings, error) {
+	subuser, err := c.getExistingSubUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.setSubUserEventWebhook(id, subuser.Username, urlTemplate, events)
+}
+
+//TestEventWebhook Send a test event to the event webhook of the SendGrid sub user associated with a cluster by it's ID,
+//if urlTemplate is blank the currently configured event webhook url is used
+func (c *Client) TestEventWebhook(id, urlTemplate string) (string, error) {
+	subuser, err := c.getExistingSubUser(id)
+	if err != nil {
+		return "", err
+	}
+	url := ""
+	if urlTemplate != "" {
+		if url, err = RenderEventWebhookURL(urlTemplate, id); err != nil {
+			return "", err
+		}
+	} else {
+		settings, err := c.sendgridClient.GetEventWebhookSettings(subuser.Username)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get event webhook settings for sub user %s", id)
+		}
+		if settings.URL == "" {
+			return "", &smtpdetails.NotExistError{Message: fmt.Sprintf("no event webhook url configured for sub user %s", id)}
+		}
+		url = settings.URL
+	}
+	if err := c.sendgridClient.TestEventWebhook(subuser.Username, url); err != nil {
+		return "", errors.Wrapf(err, "failed to test event webhook %s for sub user %s", url, id)
+	}
+	return url, nil
+}
+
+func (c *Client) setSubUserEventWebhook(id, username, urlTemplate string, events []string) (*EventWebhookSettings, error) {
+	url, err := RenderEventWebhookURL(urlTemplate, id)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := NewEventWebhookSettings(url, events)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid event webhook settings")
+	}
+	c.logger.Debugf("updating event webhook settings for sub user %s, url=%s", username, url)
+	updated, err := c.sendgridClient.UpdateEventWebhookSettings(username, settings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update event webhook settings for sub user %s", username)
+	}
+	return updated, nil
+}
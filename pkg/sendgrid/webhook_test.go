@@ -0,0 +1,272 @@
+package sendgrid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/integr8ly/smtp-service/pkg/smtpdetails"
+)
+
+func TestRenderEventWebhookURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		urlTemplate string
+		id          string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "cluster id is substituted",
+			urlTemplate: "https://events.test.com/sendgrid/{{.ClusterID}}",
+			id:          "test",
+			want:        "https://events.test.com/sendgrid/test",
+		},
+		{
+			name:        "static url is unchanged",
+			urlTemplate: "https://events.test.com/sendgrid",
+			id:          "test",
+			want:        "https://events.test.com/sendgrid",
+		},
+		{
+			name:        "invalid template",
+			urlTemplate: "https://events.test.com/{{.ClusterID",
+			id:          "test",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown template field",
+			urlTemplate: "https://events.test.com/{{.Unknown}}",
+			id:          "test",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderEventWebhookURL(tt.urlTemplate, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenderEventWebhookURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RenderEventWebhookURL() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEventWebhookSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		events  []string
+		want    *EventWebhookSettings
+		wantErr bool
+	}{
+		{
+			name:   "selected events are enabled",
+			url:    "https://events.test.com",
+			events: []string{EventOpen, EventClick},
+			want: &EventWebhookSettings{
+				Enabled: true,
+				URL:     "https://events.test.com",
+				Open:    true,
+				Click:   true,
+			},
+		},
+		{
+			name: "default events used when none provided",
+			url:  "https://events.test.com",
+			want: &EventWebhookSettings{
+				Enabled:    true,
+				URL:        "https://events.test.com",
+				Delivered:  true,
+				Bounce:     true,
+				Dropped:    true,
+				SpamReport: true,
+			},
+		},
+		{
+			name:    "unknown event type",
+			url:     "https://events.test.com",
+			events:  []string{"unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "url not defined",
+			url:     "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEventWebhookSettings(tt.url, tt.events)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEventWebhookSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewEventWebhookSettings() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_SetEventWebhook(t *testing.T) {
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {}).(*APIClientMock)
+	c := &Client{
+		sendgridClient:              sendgridClient,
+		sendgridSubUserAPIKeyScopes: mockAPIScopes,
+		passwordGenerator:           mockPasswordGen,
+		logger:                      newMockLogger(),
+	}
+	got, err := c.SetEventWebhook("test", "https://events.test.com/{{.ClusterID}}", []string{EventDelivered})
+	if err != nil {
+		t.Fatalf("SetEventWebhook() error = %v", err)
+	}
+	want := &EventWebhookSettings{Enabled: true, URL: "https://events.test.com/test", Delivered: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SetEventWebhook() got = %v, want %v", got, want)
+	}
+	calls := sendgridClient.UpdateEventWebhookSettingsCalls()
+	if len(calls) != 1 || calls[0].Username != "test" {
+		t.Errorf("SetEventWebhook() update calls = %+v, want one call for sub user test", calls)
+	}
+}
+
+func TestClient_TestEventWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		sendgridClient APIClient
+		urlTemplate    string
+		want           string
+		wantErr        bool
+		wantNotExist   bool
+	}{
+		{
+			name:           "configured url is used when no template is provided",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}),
+			want:           newMockEventWebhookSettings().URL,
+		},
+		{
+			name:           "provided template is rendered",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {}),
+			urlTemplate:    "https://other.test.com/{{.ClusterID}}",
+			want:           "https://other.test.com/test",
+		},
+		{
+			name: "no url configured",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.GetEventWebhookSettingsFunc = func(username string) (*EventWebhookSettings, error) {
+					return &EventWebhookSettings{}, nil
+				}
+			}),
+			wantErr:      true,
+			wantNotExist: true,
+		},
+		{
+			name: "test request fails",
+			sendgridClient: newMockAPIClient(func(c *APIClientMock) {
+				c.TestEventWebhookFunc = func(username string, url string) error {
+					return errors.New("test")
+				}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				sendgridClient:              tt.sendgridClient,
+				sendgridSubUserAPIKeyScopes: mockAPIScopes,
+				passwordGenerator:           mockPasswordGen,
+				logger:                      newMockLogger(),
+			}
+			got, err := c.TestEventWebhook("test", tt.urlTemplate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TestEventWebhook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if smtpdetails.IsNotExistError(err) != tt.wantNotExist {
+				t.Errorf("TestEventWebhook() error = %v, wantNotExist %v", err, tt.wantNotExist)
+			}
+			if got != tt.want {
+				t.Errorf("TestEventWebhook() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Create_WithEventWebhook(t *testing.T) {
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {
+		c.GetAPIKeysForSubUserFunc = func(username string) ([]*APIKey, error) {
+			return []*APIKey{}, nil
+		}
+	}).(*APIClientMock)
+	c, err := NewClient(sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithEventWebhook("https://events.test.com/{{.ClusterID}}", nil))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := c.Create("test"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	calls := sendgridClient.UpdateEventWebhookSettingsCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Create() event webhook updates = %d, want 1", len(calls))
+	}
+	if calls[0].Settings.URL != "https://events.test.com/test" {
+		t.Errorf("Create() event webhook url = %s, want https://events.test.com/test", calls[0].Settings.URL)
+	}
+}
+
+func TestClient_Create_WithEventWebhook_ExistingAPIKey(t *testing.T) {
+	sendgridClient := newMockAPIClient(func(c *APIClientMock) {}).(*APIClientMock)
+	c, err := NewClient(sendgridClient, mockAPIScopes, mockPasswordGen, newMockLogger(), WithEventWebhook("https://events.test.com/{{.ClusterID}}", nil))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := c.Create("test"); !smtpdetails.IsAlreadyExistsError(err) {
+		t.Fatalf("Create() error = %v, want already exists error", err)
+	}
+	if calls := sendgridClient.UpdateEventWebhookSettingsCalls(); len(calls) != 0 {
+		t.Errorf("Create() event webhook updates = %d, want 0", len(calls))
+	}
+}
+
+func TestNewClient_WithEventWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		urlTemplate string
+		events      []string
+		wantErr     bool
+	}{
+		{
+			name:        "valid template",
+			urlTemplate: "https://events.test.com/{{.ClusterID}}",
+		},
+		{
+			name:        "unparsable template",
+			urlTemplate: "https://events.test.com/{{.ClusterID}",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown template field",
+			urlTemplate: "https://events.test.com/{{.Cluster}}",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown event",
+			urlTemplate: "https://events.test.com/{{.ClusterID}}",
+			events:      []string{"unknown"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(newMockAPIClient(func(c *APIClientMock) {}), mockAPIScopes, mockPasswordGen, newMockLogger(), WithEventWebhook(tt.urlTemplate, tt.events))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -31,3 +31,99 @@ func IsNotExistError(err error) bool {
 	_, ok := err.(*NotExistError)
 	return ok
 }
+
+//AuthenticationError Error to indicate SMTP details were rejected by the SMTP server
+type AuthenticationError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *AuthenticationError) Error() string {
+	return e.Message
+}
+
+//IsAuthenticationError Compare check for AuthenticationError
+func IsAuthenticationError(err error) bool {
+	_, ok := err.(*AuthenticationError)
+	return ok
+}
+
+//ConnectionError Error to indicate a connection to an SMTP server could not be established
+type ConnectionError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *ConnectionError) Error() string {
+	return e.Message
+}
+
+//IsConnectionError Compare check for ConnectionError
+func IsConnectionError(err error) bool {
+	_, ok := err.(*ConnectionError)
+	return ok
+}
+
+//MissingSecretKeyError Error to indicate a secret does not contain a required SMTP details key
+type MissingSecretKeyError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *MissingSecretKeyError) Error() string {
+	return e.Message
+}
+
+//IsMissingSecretKeyError Compare check for MissingSecretKeyError
+func IsMissingSecretKeyError(err error) bool {
+	_, ok := err.(*MissingSecretKeyError)
+	return ok
+}
+
+//InvalidSecretValueError Error to indicate a secret contains an SMTP details value that cannot be parsed
+type InvalidSecretValueError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *InvalidSecretValueError) Error() string {
+	return e.Message
+}
+
+//IsInvalidSecretValueError Compare check for InvalidSecretValueError
+func IsInvalidSecretValueError(err error) bool {
+	_, ok := err.(*InvalidSecretValueError)
+	return ok
+}
+
+//ValidationError Error to indicate an input such as a cluster id is invalid
+type ValidationError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+//IsValidationError Compare check for ValidationError
+func IsValidationError(err error) bool {
+	_, ok := err.(*ValidationError)
+	return ok
+}
+
+//QuotaExceededError Error to indicate a limit of the provider account has been reached
+type QuotaExceededError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *QuotaExceededError) Error() string {
+	return e.Message
+}
+
+//IsQuotaExceededError Compare check for QuotaExceededError
+func IsQuotaExceededError(err error) bool {
+	_, ok := err.(*QuotaExceededError)
+	return ok
+}
@@ -0,0 +1,56 @@
+package smtpdetails
+
+import (
+	"fmt"
+	"strings"
+)
+
+//ExistsPolicy Behaviour of CreateWithPolicy when the SMTP details of a cluster already exist
+type ExistsPolicy string
+
+const (
+	//ExistsPolicyError Fail with an AlreadyExistsError
+	ExistsPolicyError ExistsPolicy = "error"
+	//ExistsPolicySkip Return the existing SMTP details, without the password as it cannot be retrieved
+	ExistsPolicySkip ExistsPolicy = "skip"
+	//ExistsPolicyRotate Refresh the existing SMTP details, returning the new password
+	ExistsPolicyRotate ExistsPolicy = "rotate"
+)
+
+//ExistsPolicies All supported exists policies
+var ExistsPolicies = []ExistsPolicy{ExistsPolicyError, ExistsPolicySkip, ExistsPolicyRotate}
+
+//ParseExistsPolicy Parse an exists policy by name, case insensitive, a blank name is the error policy
+func ParseExistsPolicy(policy string) (ExistsPolicy, error) {
+	if strings.TrimSpace(policy) == "" {
+		return ExistsPolicyError, nil
+	}
+	for _, p := range ExistsPolicies {
+		if strings.EqualFold(strings.TrimSpace(policy), string(p)) {
+			return p, nil
+		}
+	}
+	return "", &ValidationError{Message: fmt.Sprintf("unknown exists policy %s, supported policies are %v", policy, ExistsPolicies)}
+}
+
+//CreateWithPolicy Create the SMTP details of a cluster, applying policy if they already exist. existed reports whether
+//the SMTP details already existed, in which case the skip policy returns details without a password.
+func CreateWithPolicy(client Client, id string, policy ExistsPolicy) (details *SMTPDetails, existed bool, err error) {
+	details, err = client.Create(id)
+	if err == nil || !IsAlreadyExistsError(err) {
+		return details, false, err
+	}
+	switch policy {
+	case ExistsPolicySkip:
+		details, err = client.Get(id)
+		if err != nil {
+			return nil, true, err
+		}
+		details.Password = ""
+		return details, true, nil
+	case ExistsPolicyRotate:
+		details, err = client.Refresh(id)
+		return details, true, err
+	}
+	return nil, true, err
+}
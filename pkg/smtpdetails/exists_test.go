@@ -0,0 +1,112 @@
+package smtpdetails
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseExistsPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    ExistsPolicy
+		wantErr bool
+	}{
+		{name: "should default to error", policy: "", want: ExistsPolicyError},
+		{name: "should parse skip", policy: "skip", want: ExistsPolicySkip},
+		{name: "should parse case insensitive", policy: " Rotate ", want: ExistsPolicyRotate},
+		{name: "should fail on unknown policy", policy: "ignore", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExistsPolicy(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExistsPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !IsValidationError(err) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseExistsPolicy() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateWithPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		exists       bool
+		createErr    error
+		policy       ExistsPolicy
+		wantPassword string
+		wantExisted  bool
+		wantErr      bool
+	}{
+		{
+			name:         "should create new details",
+			policy:       ExistsPolicyError,
+			wantPassword: "created",
+		},
+		{
+			name:        "should fail when details exist with error policy",
+			exists:      true,
+			policy:      ExistsPolicyError,
+			wantExisted: true,
+			wantErr:     true,
+		},
+		{
+			name:        "should return existing details without password with skip policy",
+			exists:      true,
+			policy:      ExistsPolicySkip,
+			wantExisted: true,
+		},
+		{
+			name:         "should refresh existing details with rotate policy",
+			exists:       true,
+			policy:       ExistsPolicyRotate,
+			wantPassword: "refreshed",
+			wantExisted:  true,
+		},
+		{
+			name:      "should fail on other create errors regardless of policy",
+			createErr: errors.New("test"),
+			policy:    ExistsPolicyRotate,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &ClientMock{
+				CreateFunc: func(id string) (*SMTPDetails, error) {
+					if tt.createErr != nil {
+						return nil, tt.createErr
+					}
+					if tt.exists {
+						return nil, &AlreadyExistsError{Message: "test"}
+					}
+					return &SMTPDetails{ID: id, Password: "created"}, nil
+				},
+				GetFunc: func(id string) (*SMTPDetails, error) {
+					return &SMTPDetails{ID: id, Password: "unavailable"}, nil
+				},
+				RefreshFunc: func(id string) (*SMTPDetails, error) {
+					return &SMTPDetails{ID: id, Password: "refreshed"}, nil
+				},
+			}
+			got, existed, err := CreateWithPolicy(client, "test", tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateWithPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.exists && !IsAlreadyExistsError(err) {
+				t.Fatalf("expected already exists error, got %v", err)
+			}
+			if existed != tt.wantExisted {
+				t.Fatalf("CreateWithPolicy() existed = %t, want %t", existed, tt.wantExisted)
+			}
+			if !tt.wantErr && got.Password != tt.wantPassword {
+				t.Fatalf("CreateWithPolicy() password = %s, want %s", got.Password, tt.wantPassword)
+			}
+		})
+	}
+}
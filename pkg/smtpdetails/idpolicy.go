@@ -0,0 +1,74 @@
+package smtpdetails
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//IDPolicy Rules validating the id of a cluster and mapping it to the username of a provider. The mapping is
+//deterministic, so the same cluster id always finds the same username. The zero value uses ids verbatim and accepts any
+//id, as ids were used before policies were introduced.
+type IDPolicy struct {
+	//MaxLength Maximum length of a username including the prefix, zero means unlimited
+	MaxLength int
+	//Allowed Pattern a username including the prefix must match, nil allows any username
+	Allowed *regexp.Regexp
+	//Lowercase Lowercase cluster ids before they are validated
+	Lowercase bool
+	//Prefix Prefix prepended to the cluster id to form the username, e.g. rhmi-
+	Prefix string
+	//HashLongIDs Shorten cluster ids exceeding the maximum length by truncating them and appending a hash of the
+	//full id, instead of rejecting them
+	HashLongIDs bool
+}
+
+//Normalize Validate a cluster id and convert it to its canonical form, the username without the prefix. Normalizing a
+//canonical id returns it unchanged.
+func (p *IDPolicy) Normalize(id string) (string, error) {
+	// a blank id would map to a username without a cluster, which only matters once ids are prefixed or validated
+	if id == "" && (p.Prefix != "" || p.MaxLength > 0 || p.Allowed != nil) {
+		return "", &ValidationError{Message: "cluster id must be a non-empty string"}
+	}
+	if p.Lowercase {
+		id = strings.ToLower(id)
+	}
+	if p.HashLongIDs && p.MaxLength > 0 && len(p.Prefix)+len(id) > p.MaxLength {
+		// keep as much of the id as fits, followed by a separator and the hash
+		keep := p.MaxLength - len(p.Prefix) - IDHashLength - 1
+		if keep < 1 {
+			return "", &ValidationError{Message: fmt.Sprintf("prefix %s leaves no room for hashed cluster ids of max length %d", p.Prefix, p.MaxLength)}
+		}
+		sum := sha256.Sum256([]byte(id))
+		id = fmt.Sprintf("%s-%s", id[:keep], hex.EncodeToString(sum[:])[:IDHashLength])
+	}
+	username := p.Prefix + id
+	if p.MaxLength > 0 && len(username) > p.MaxLength {
+		return "", &ValidationError{Message: fmt.Sprintf("cluster id %s is too long, username %s exceeds %d characters", id, username, p.MaxLength)}
+	}
+	if p.Allowed != nil && !p.Allowed.MatchString(username) {
+		return "", &ValidationError{Message: fmt.Sprintf("cluster id %s is invalid, username %s must match %s", id, username, p.Allowed)}
+	}
+	return id, nil
+}
+
+//Username Map a cluster id to the username of its provider resources
+func (p *IDPolicy) Username(id string) (string, error) {
+	canonical, err := p.Normalize(id)
+	if err != nil {
+		return "", err
+	}
+	return p.Prefix + canonical, nil
+}
+
+//ClusterID Map a username back to the canonical id of its cluster, false if the username is not managed by the policy.
+//The mapping is lossy, lowercased and hashed ids cannot be recovered, so the canonical id is returned instead of the
+//id the cluster was created with. It maps to the same username, but must not be compared with the original id.
+func (p *IDPolicy) ClusterID(username string) (string, bool) {
+	if !strings.HasPrefix(username, p.Prefix) || len(username) == len(p.Prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(username, p.Prefix), true
+}
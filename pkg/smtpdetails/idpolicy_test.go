@@ -0,0 +1,115 @@
+package smtpdetails
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestIDPolicy_Username(t *testing.T) {
+	allowed := regexp.MustCompile(`^[a-z0-9-]+$`)
+	longID := strings.Repeat("a", 30)
+	tests := []struct {
+		name    string
+		policy  *IDPolicy
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "should use id verbatim by default",
+			policy: &IDPolicy{},
+			id:     "My_Cluster",
+			want:   "My_Cluster",
+		},
+		{
+			name:   "should lowercase and prefix id",
+			policy: &IDPolicy{Lowercase: true, Prefix: "rhmi-", Allowed: allowed},
+			id:     "MyCluster",
+			want:   "rhmi-mycluster",
+		},
+		{
+			name:    "should reject id with invalid characters",
+			policy:  &IDPolicy{Allowed: allowed},
+			id:      "my_cluster",
+			wantErr: true,
+		},
+		{
+			name:   "should accept blank id by default",
+			policy: &IDPolicy{},
+			id:     "",
+			want:   "",
+		},
+		{
+			name:    "should reject blank id when prefixed",
+			policy:  &IDPolicy{Prefix: "rhmi-"},
+			id:      "",
+			wantErr: true,
+		},
+		{
+			name:    "should reject blank id when validated",
+			policy:  &IDPolicy{Allowed: allowed},
+			id:      "",
+			wantErr: true,
+		},
+		{
+			name:    "should reject long id",
+			policy:  &IDPolicy{MaxLength: 20},
+			id:      longID,
+			wantErr: true,
+		},
+		{
+			name:   "should hash long id",
+			policy: &IDPolicy{MaxLength: 20, Prefix: "rhmi-", HashLongIDs: true},
+			id:     longID,
+			want:   "rhmi-aaaaaa-3a54fc0c",
+		},
+		{
+			name:    "should reject prefix leaving no room for the hash",
+			policy:  &IDPolicy{MaxLength: 12, Prefix: "rhmi-", HashLongIDs: true},
+			id:      longID,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Username(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Username() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !IsValidationError(err) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("Username() got = %s, want %s", got, tt.want)
+			}
+			if got == "" {
+				return
+			}
+			// the mapping must be reversible and stable for the canonical id
+			id, ok := tt.policy.ClusterID(got)
+			if !ok {
+				t.Fatalf("ClusterID() did not map username %s back", got)
+			}
+			if again, err := tt.policy.Username(id); err != nil || again != got {
+				t.Fatalf("Username() of canonical id %s got = %s, want %s", id, again, got)
+			}
+		})
+	}
+}
+
+func TestIDPolicy_ClusterID(t *testing.T) {
+	policy := &IDPolicy{Prefix: "rhmi-"}
+	if id, ok := policy.ClusterID("rhmi-cluster"); !ok || id != "cluster" {
+		t.Fatalf("unexpected cluster id %s", id)
+	}
+	if _, ok := policy.ClusterID("other-cluster"); ok {
+		t.Fatal("expected username without prefix not to be mapped")
+	}
+	if _, ok := policy.ClusterID("rhmi-"); ok {
+		t.Fatal("expected bare prefix not to be mapped")
+	}
+}
@@ -0,0 +1,23 @@
+package smtpdetails
+
+import (
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/metrics"
+)
+
+var (
+	operationsTotal   = metrics.Default.NewCounter(MetricOperationsTotal, "Number of SMTP details operations by operation and outcome.", "operation", "outcome")
+	operationDuration = metrics.Default.NewHistogram(MetricOperationDurationSeconds, "Duration of SMTP details operations by operation.", metrics.DefaultBuckets, "operation")
+)
+
+//ObserveOperation Record the outcome and duration of an operation started at start, meant to be deferred with a
+//pointer to the named error result of the operation
+func ObserveOperation(operation string, start time.Time, err *error) {
+	outcome := metrics.OutcomeSuccess
+	if err != nil && *err != nil {
+		outcome = metrics.OutcomeFailure
+	}
+	operationsTotal.Inc(operation, outcome)
+	operationDuration.Observe(time.Since(start).Seconds(), operation)
+}
@@ -0,0 +1,41 @@
+package smtpdetails
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/smtp-service/pkg/metrics"
+)
+
+func TestObserveOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantOutcome string
+	}{
+		{
+			name:        "nil error should record success",
+			wantOutcome: metrics.OutcomeSuccess,
+		},
+		{
+			name:        "error should record failure",
+			err:         errors.New("test"),
+			wantOutcome: metrics.OutcomeFailure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := operationsTotal.Value(OperationRefresh, tt.wantOutcome)
+			countBefore := operationDuration.Count(OperationRefresh)
+			err := tt.err
+			ObserveOperation(OperationRefresh, time.Now(), &err)
+			if got := operationsTotal.Value(OperationRefresh, tt.wantOutcome) - before; got != 1 {
+				t.Errorf("ObserveOperation() recorded %v %s operations, want 1", got, tt.wantOutcome)
+			}
+			if got := operationDuration.Count(OperationRefresh) - countBefore; got != 1 {
+				t.Errorf("ObserveOperation() recorded %v durations, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package smtpdetails
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+//SecretKeys Names of the secret data keys holding each of the SMTP details
+type SecretKeys struct {
+	Host    string
+	Port    string
+	TLS     string
+	TLSMode string
+	//StartTLS Key holding true only if the TLS mode is starttls, for frameworks with separate STARTTLS and implicit TLS
+	//settings
+	StartTLS string
+	//ImplicitTLS Key holding true only if the TLS mode is implicit
+	ImplicitTLS string
+	Username    string
+	Password    string
+}
+
+//DefaultSecretKeys The secret data keys used when no custom keys are provided
+func DefaultSecretKeys() *SecretKeys {
+	return &SecretKeys{
+		Host:     SecretKeyHost,
+		Port:     SecretKeyPort,
+		TLS:      SecretKeyTLS,
+		TLSMode:  SecretKeyTLSMode,
+		Username: SecretKeyUsername,
+		Password: SecretKeyPassword,
+	}
+}
+
+//ParseSecret Parse a Kubernetes Secret manifest in JSON or YAML format
+func ParseSecret(manifest []byte) (*apiv1.Secret, error) {
+	secret := &apiv1.Secret{}
+	if err := yaml.Unmarshal(manifest, secret); err != nil {
+		return nil, errors.Wrap(err, "failed to parse secret manifest")
+	}
+	if secret.Kind != "" && secret.Kind != SecretGVKKind {
+		return nil, errors.New(fmt.Sprintf("manifest is not a secret, kind=%s", secret.Kind))
+	}
+	return secret, nil
+}
+
+//ConvertSecretToSMTPDetails Read SMTPDetails from a Kubernetes Secret, stringData takes precedence over data as it
+//does when applied to a cluster, keys may be nil to use the default keys and a blank key skips its detail
+func ConvertSecretToSMTPDetails(secret *apiv1.Secret, keys *SecretKeys) (*SMTPDetails, error) {
+	if secret == nil {
+		return nil, errors.New("secret must be defined")
+	}
+	if keys == nil {
+		keys = DefaultSecretKeys()
+	}
+	values := map[string]string{}
+	for k, v := range secret.Data {
+		values[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		values[k] = v
+	}
+	// a blank key omits the detail from the secret, it must not match a blank data key
+	delete(values, "")
+	required := func(key string) (string, error) {
+		if key == "" {
+			return "", nil
+		}
+		value, ok := values[key]
+		if !ok || strings.TrimSpace(value) == "" {
+			return "", &MissingSecretKeyError{Message: fmt.Sprintf("secret is missing required key %s, secret=%s", key, secret.Name)}
+		}
+		return value, nil
+	}
+	details := &SMTPDetails{Annotations: secret.Annotations}
+	var err error
+	if details.Host, err = required(keys.Host); err != nil {
+		return nil, err
+	}
+	if details.Username, err = required(keys.Username); err != nil {
+		return nil, err
+	}
+	if details.Password, err = required(keys.Password); err != nil {
+		return nil, err
+	}
+	if keys.Port != "" {
+		port, err := required(keys.Port)
+		if err != nil {
+			return nil, err
+		}
+		details.Port, err = strconv.Atoi(strings.TrimSpace(port))
+		if err != nil || details.Port < 1 || details.Port > 65535 {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key %s must be a port between 1 and 65535, secret=%s value=%s", keys.Port, secret.Name, port)}
+		}
+	}
+	tls, hasTLS := values[keys.TLS]
+	if hasTLS {
+		details.TLS, err = strconv.ParseBool(strings.TrimSpace(tls))
+		if err != nil {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key %s must be true or false, secret=%s value=%s", keys.TLS, secret.Name, tls)}
+		}
+	}
+	tlsMode, hasTLSMode := values[keys.TLSMode]
+	if hasTLSMode {
+		details.TLSMode, err = ParseTLSMode(tlsMode)
+		if err != nil {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key %s is invalid, secret=%s: %v", keys.TLSMode, secret.Name, err)}
+		}
+		if hasTLS && details.TLS != details.TLSMode.RequiresTLS() {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key %s conflicts with key %s, secret=%s %s=%s %s=%s", keys.TLSMode, keys.TLS, secret.Name, keys.TLSMode, tlsMode, keys.TLS, tls)}
+		}
+		details.TLS = details.TLSMode.RequiresTLS()
+	}
+	if !hasTLSMode {
+		mode, hasMode, err := splitTLSMode(values, keys, secret.Name)
+		if err != nil {
+			return nil, err
+		}
+		if hasMode {
+			if hasTLS && details.TLS != mode.RequiresTLS() {
+				return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret keys %s and %s conflict with key %s, secret=%s", keys.StartTLS, keys.ImplicitTLS, keys.TLS, secret.Name)}
+			}
+			details.TLSMode = mode
+			details.TLS = mode.RequiresTLS()
+			hasTLSMode = true
+		}
+	}
+	if !hasTLS && !hasTLSMode {
+		return nil, &MissingSecretKeyError{Message: fmt.Sprintf("secret is missing required key %s or %s, secret=%s", keys.TLSMode, keys.TLS, secret.Name)}
+	}
+	return details, nil
+}
+
+//splitTLSMode Read the TLS mode from the separate starttls and implicit TLS keys, false if neither key is set
+func splitTLSMode(values map[string]string, keys *SecretKeys, secretName string) (TLSMode, bool, error) {
+	enabled := func(key string) (bool, bool, error) {
+		value, ok := values[key]
+		if key == "" || !ok {
+			return false, false, nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return false, false, &InvalidSecretValueError{Message: fmt.Sprintf("secret key %s must be true or false, secret=%s value=%s", key, secretName, value)}
+		}
+		return b, true, nil
+	}
+	startTLS, hasStartTLS, err := enabled(keys.StartTLS)
+	if err != nil {
+		return "", false, err
+	}
+	implicitTLS, hasImplicitTLS, err := enabled(keys.ImplicitTLS)
+	if err != nil {
+		return "", false, err
+	}
+	switch {
+	case !hasStartTLS && !hasImplicitTLS:
+		return "", false, nil
+	case startTLS && implicitTLS:
+		return "", false, &InvalidSecretValueError{Message: fmt.Sprintf("secret keys %s and %s must not both be true, secret=%s", keys.StartTLS, keys.ImplicitTLS, secretName)}
+	case implicitTLS:
+		return TLSModeImplicit, true, nil
+	case startTLS:
+		return TLSModeStartTLS, true, nil
+	}
+	return TLSModeNone, true, nil
+}
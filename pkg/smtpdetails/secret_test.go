@@ -0,0 +1,174 @@
+package smtpdetails
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const mockSecretYAML = `apiVersion: v1
+kind: Secret
+metadata:
+  name: test
+type: Opaque
+data:
+  host: c210cC50ZXN0LmNvbQ==
+  port: NTg3
+  tls: dHJ1ZQ==
+  username: dGVzdA==
+stringData:
+  password: test
+`
+
+func TestParseSecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		expectErr bool
+		validate  func(t *testing.T, secret *apiv1.Secret)
+	}{
+		{
+			name:     "should parse yaml with data and stringData",
+			manifest: mockSecretYAML,
+			validate: func(t *testing.T, secret *apiv1.Secret) {
+				if string(secret.Data[SecretKeyHost]) != mockHost || secret.StringData[SecretKeyPassword] != mockPassword {
+					t.Fatalf("unexpected secret %v", secret)
+				}
+			},
+		},
+		{
+			name:     "should parse json",
+			manifest: `{"kind": "Secret", "metadata": {"name": "test"}, "data": {"host": "c210cC50ZXN0LmNvbQ=="}}`,
+			validate: func(t *testing.T, secret *apiv1.Secret) {
+				if string(secret.Data[SecretKeyHost]) != mockHost {
+					t.Fatalf("unexpected secret %v", secret)
+				}
+			},
+		},
+		{
+			name:      "should fail on other kinds",
+			manifest:  `{"kind": "ConfigMap"}`,
+			expectErr: true,
+		},
+		{
+			name:      "should fail on invalid base64 data",
+			manifest:  `{"kind": "Secret", "data": {"host": "not base64!"}}`,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := ParseSecret([]byte(tt.manifest))
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, secret)
+			}
+		})
+	}
+}
+
+func TestConvertSecretToSMTPDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   *apiv1.Secret
+		keys     *SecretKeys
+		want     *SMTPDetails
+		errCheck func(error) bool
+	}{
+		{
+			name:   "should round trip converted details",
+			secret: ConvertSMTPDetailsToSecret(newMockSMTPDetails(), "test"),
+			want: &SMTPDetails{
+				Host:     mockHost,
+				Port:     mockPort,
+				TLS:      mockTLS,
+				TLSMode:  TLSModeStartTLS,
+				Username: mockUsername,
+				Password: mockPassword,
+			},
+		},
+		{
+			name: "should prefer stringData and accept legacy tls key",
+			secret: &apiv1.Secret{
+				Data:       map[string][]byte{"host": []byte("old"), "port": []byte("465"), "tls": []byte("true"), "username": []byte(mockUsername)},
+				StringData: map[string]string{"host": mockHost, "password": mockPassword},
+			},
+			want: &SMTPDetails{Host: mockHost, Port: 465, TLS: true, Username: mockUsername, Password: mockPassword},
+		},
+		{
+			name: "should use custom keys",
+			secret: &apiv1.Secret{
+				StringData: map[string]string{"SMTP_HOST": mockHost, "SMTP_PORT": "25", "SMTP_TLS_MODE": "opportunistic", "SMTP_USER": mockUsername, "SMTP_PASS": mockPassword},
+			},
+			keys: &SecretKeys{Host: "SMTP_HOST", Port: "SMTP_PORT", TLS: "SMTP_TLS", TLSMode: "SMTP_TLS_MODE", Username: "SMTP_USER", Password: "SMTP_PASS"},
+			want: &SMTPDetails{Host: mockHost, Port: 25, TLSMode: TLSModeOpportunistic, Username: mockUsername, Password: mockPassword},
+		},
+		{
+			name: "should skip blank keys",
+			secret: &apiv1.Secret{
+				StringData: map[string]string{"": "invalid", "EMAIL_HOST": mockHost, "EMAIL_USE_TLS": "true", "EMAIL_HOST_PASSWORD": mockPassword},
+			},
+			keys: &SecretKeys{Host: "EMAIL_HOST", StartTLS: "EMAIL_USE_TLS", ImplicitTLS: "EMAIL_USE_SSL", Password: "EMAIL_HOST_PASSWORD"},
+			want: &SMTPDetails{Host: mockHost, TLS: true, TLSMode: TLSModeStartTLS, Password: mockPassword},
+		},
+		{
+			name: "should fail when starttls and implicit tls are both enabled",
+			secret: &apiv1.Secret{
+				StringData: map[string]string{"EMAIL_HOST": mockHost, "EMAIL_PORT": "465", "EMAIL_USE_TLS": "true", "EMAIL_USE_SSL": "true", "EMAIL_HOST_USER": mockUsername, "EMAIL_HOST_PASSWORD": mockPassword},
+			},
+			keys:     SecretKeyPresets[SecretKeyPresetDjango],
+			errCheck: IsInvalidSecretValueError,
+		},
+		{
+			name:     "should report missing key",
+			secret:   &apiv1.Secret{StringData: map[string]string{"host": mockHost, "port": "587", "tls": "true", "username": mockUsername}},
+			errCheck: IsMissingSecretKeyError,
+		},
+		{
+			name:     "should report missing tls keys",
+			secret:   &apiv1.Secret{StringData: map[string]string{"host": mockHost, "port": "587", "username": mockUsername, "password": mockPassword}},
+			errCheck: IsMissingSecretKeyError,
+		},
+		{
+			name:     "should report invalid port",
+			secret:   &apiv1.Secret{StringData: map[string]string{"host": mockHost, "port": "70000", "tls": "true", "username": mockUsername, "password": mockPassword}},
+			errCheck: IsInvalidSecretValueError,
+		},
+		{
+			name:     "should report invalid tls",
+			secret:   &apiv1.Secret{StringData: map[string]string{"host": mockHost, "port": "587", "tls": "yes please", "username": mockUsername, "password": mockPassword}},
+			errCheck: IsInvalidSecretValueError,
+		},
+		{
+			name:     "should report conflicting tls and tls mode",
+			secret:   &apiv1.Secret{StringData: map[string]string{"host": mockHost, "port": "587", "tls": "false", "tls_mode": "implicit", "username": mockUsername, "password": mockPassword}},
+			errCheck: IsInvalidSecretValueError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertSecretToSMTPDetails(tt.secret, tt.keys)
+			if tt.errCheck != nil {
+				if !tt.errCheck(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ConvertSecretToSMTPDetails() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package smtpdetails
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//SecretKeyPresets Secret data keys expected by common frameworks, the tls key holds the legacy true or false value.
+//Frameworks with separate STARTTLS and implicit TLS settings use the starttls and implicit tls keys instead.
+var SecretKeyPresets = map[string]*SecretKeys{
+	SecretKeyPresetDefault: DefaultSecretKeys(),
+	SecretKeyPresetEnv: {
+		Host:     "SMTP_HOST",
+		Port:     "SMTP_PORT",
+		TLS:      "SMTP_TLS",
+		TLSMode:  "SMTP_TLS_MODE",
+		Username: "SMTP_USERNAME",
+		Password: "SMTP_PASSWORD",
+	},
+	SecretKeyPresetDjango: {
+		Host:        "EMAIL_HOST",
+		Port:        "EMAIL_PORT",
+		StartTLS:    "EMAIL_USE_TLS",
+		ImplicitTLS: "EMAIL_USE_SSL",
+		Username:    "EMAIL_HOST_USER",
+		Password:    "EMAIL_HOST_PASSWORD",
+	},
+	SecretKeyPresetSpring: {
+		Host:        "SPRING_MAIL_HOST",
+		Port:        "SPRING_MAIL_PORT",
+		StartTLS:    "SPRING_MAIL_PROPERTIES_MAIL_SMTP_STARTTLS_ENABLE",
+		ImplicitTLS: "SPRING_MAIL_PROPERTIES_MAIL_SMTP_SSL_ENABLE",
+		Username:    "SPRING_MAIL_USERNAME",
+		Password:    "SPRING_MAIL_PASSWORD",
+	},
+}
+
+//GetSecretKeyPreset Retrieve a copy of a secret key preset by name, a blank name returns the default keys
+func GetSecretKeyPreset(name string) (*SecretKeys, error) {
+	if name == "" {
+		return DefaultSecretKeys(), nil
+	}
+	preset, ok := SecretKeyPresets[strings.ToLower(name)]
+	if !ok {
+		return nil, &NotExistError{Message: fmt.Sprintf("secret key preset %s does not exist, supported presets are %v", name, SecretKeyPresetNames())}
+	}
+	keys := *preset
+	return &keys, nil
+}
+
+//SecretKeyPresetNames Sorted names of all secret key presets
+func SecretKeyPresetNames() []string {
+	var names []string
+	for name := range SecretKeyPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//ParseSecretKeyMap Override the keys of base with detail=key pairs, where detail is one of the default secret keys
+//e.g. host=SMTP_HOST, a blank key omits the detail from the secret
+func ParseSecretKeyMap(base *SecretKeys, pairs []string) (*SecretKeys, error) {
+	keys := *base
+	fields := map[string]*string{
+		SecretKeyHost:        &keys.Host,
+		SecretKeyPort:        &keys.Port,
+		SecretKeyTLS:         &keys.TLS,
+		SecretKeyTLSMode:     &keys.TLSMode,
+		SecretKeyStartTLS:    &keys.StartTLS,
+		SecretKeyImplicitTLS: &keys.ImplicitTLS,
+		SecretKeyUsername:    &keys.Username,
+		SecretKeyPassword:    &keys.Password,
+	}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key mapping must be in the format detail=key, mapping=%s", pair)}
+		}
+		field, ok := fields[strings.ToLower(strings.TrimSpace(parts[0]))]
+		if !ok {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key mapping has unknown detail %s, supported details are host, port, tls, tls_mode, starttls, implicit_tls, username and password", parts[0])}
+		}
+		*field = strings.TrimSpace(parts[1])
+	}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if *field == "" {
+			continue
+		}
+		if seen[*field] {
+			return nil, &InvalidSecretValueError{Message: fmt.Sprintf("secret key %s is mapped to more than one detail", *field)}
+		}
+		seen[*field] = true
+	}
+	return &keys, nil
+}
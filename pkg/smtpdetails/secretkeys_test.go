@@ -0,0 +1,104 @@
+package smtpdetails
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetSecretKeyPreset(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		want      *SecretKeys
+		expectErr bool
+	}{
+		{
+			name: "should return default keys for blank preset",
+			want: DefaultSecretKeys(),
+		},
+		{
+			name:   "should return preset case insensitive",
+			preset: "Django",
+			want:   SecretKeyPresets[SecretKeyPresetDjango],
+		},
+		{
+			name:      "should fail on unknown preset",
+			preset:    "rails",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetSecretKeyPreset(tt.preset)
+			if tt.expectErr {
+				if !IsNotExistError(err) {
+					t.Fatalf("expected not exist error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetSecretKeyPreset() = %v, want %v", got, tt.want)
+			}
+			got.Host = "changed"
+			if reflect.DeepEqual(got, tt.want) {
+				t.Fatal("expected a copy of the preset")
+			}
+		})
+	}
+}
+
+func TestParseSecretKeyMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		pairs     []string
+		want      *SecretKeys
+		expectErr bool
+	}{
+		{
+			name:  "should override mapped keys only",
+			pairs: []string{"host=SMTP_HOST", " Password = MAIL_PASSWORD", "tls_mode="},
+			want: &SecretKeys{
+				Host:     "SMTP_HOST",
+				Port:     SecretKeyPort,
+				TLS:      SecretKeyTLS,
+				Username: SecretKeyUsername,
+				Password: "MAIL_PASSWORD",
+			},
+		},
+		{
+			name:      "should fail on invalid format",
+			pairs:     []string{"host"},
+			expectErr: true,
+		},
+		{
+			name:      "should fail on unknown detail",
+			pairs:     []string{"hostname=SMTP_HOST"},
+			expectErr: true,
+		},
+		{
+			name:      "should fail when a key is mapped twice",
+			pairs:     []string{"host=port"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSecretKeyMap(DefaultSecretKeys(), tt.pairs)
+			if tt.expectErr {
+				if !IsInvalidSecretValueError(err) {
+					t.Fatalf("expected invalid secret value error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseSecretKeyMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
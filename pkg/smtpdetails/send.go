@@ -0,0 +1,186 @@
+package smtpdetails
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const testMessageTemplate = `This is a diagnostic email sent to test the SMTP details of cluster {{.ClusterID}}.
+
+SMTP host: {{.Host}}
+SMTP port: {{.Port}}
+TLS mode:  {{.TLSMode}}
+Username:  {{.Username}}
+Sent at:   {{.SentAt}}
+
+No action is required.
+`
+
+//Message An email to send with a Sender
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+//SendResult Outcome of sending a message, the transcript has credentials redacted
+type SendResult struct {
+	MessageID  string   `json:"messageId"`
+	Response   string   `json:"response"`
+	Transcript []string `json:"transcript"`
+}
+
+//Sender Sends messages using SMTP details
+type Sender struct {
+	dialer
+}
+
+//NewSender Create a new Sender, tlsConfig may be nil to verify the server certificate against the system roots.
+//Credentials are only sent over TLS unless insecureAuth is set.
+func NewSender(timeout time.Duration, tlsConfig *tls.Config, insecureAuth bool) *Sender {
+	return &Sender{dialer: newDialer(timeout, tlsConfig, insecureAuth)}
+}
+
+//NewTestMessage Create a diagnostic message describing the SMTP details of a cluster, without the password
+func NewTestMessage(clusterID, from, to string, details *SMTPDetails) (*Message, error) {
+	tmpl, err := template.New("test").Parse(testMessageTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse test message template")
+	}
+	body := &bytes.Buffer{}
+	err = tmpl.Execute(body, map[string]interface{}{
+		"ClusterID": clusterID,
+		"Host":      details.Host,
+		"Port":      details.Port,
+		"TLSMode":   details.GetTLSMode(),
+		"Username":  details.Username,
+		"SentAt":    time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render test message")
+	}
+	return &Message{
+		From:    from,
+		To:      to,
+		Subject: fmt.Sprintf("SMTP test for cluster %s", clusterID),
+		Body:    body.String(),
+	}, nil
+}
+
+//Send Authenticate with the details and send the message, the result is returned on failure as well so the
+//transcript can be reported
+func (s *Sender) Send(details *SMTPDetails, msg *Message) (*SendResult, error) {
+	result := &SendResult{}
+	if msg == nil || msg.From == "" || msg.To == "" {
+		return result, errors.New("message must have a from and to address")
+	}
+	from, err := parseAddress("from", msg.From)
+	if err != nil {
+		return result, err
+	}
+	to, err := parseAddress("to", msg.To)
+	if err != nil {
+		return result, err
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return result, &ValidationError{Message: "subject must not contain line breaks"}
+	}
+	sess, err := s.dial(details)
+	result.Transcript = sess.transcript
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		result.Transcript = sess.transcript
+	}()
+	result.MessageID, err = newMessageID(from)
+	if err != nil {
+		sess.close()
+		return result, err
+	}
+	if _, err := sess.cmd(250, "", "MAIL FROM:<%s>", from); err != nil {
+		sess.close()
+		return result, errors.Wrapf(err, "smtp server rejected sender %s", from)
+	}
+	if _, err := sess.cmd(250, "", "RCPT TO:<%s>", to); err != nil {
+		sess.close()
+		return result, errors.Wrapf(err, "smtp server rejected recipient %s", to)
+	}
+	if _, err := sess.cmd(354, "", "DATA"); err != nil {
+		sess.close()
+		return result, errors.Wrap(err, "smtp server rejected message data")
+	}
+	data := buildMessageData(msg, result.MessageID)
+	sess.note(fmt.Sprintf("message data, %d bytes", len(data)))
+	writer := sess.text.DotWriter()
+	if _, err := writer.Write(data); err != nil {
+		sess.close()
+		return result, errors.Wrap(err, "failed to write message data")
+	}
+	if err := writer.Close(); err != nil {
+		sess.close()
+		return result, errors.Wrap(err, "failed to write message data")
+	}
+	result.Response, err = sess.readResponse(250)
+	if err != nil {
+		sess.close()
+		return result, errors.Wrap(err, "smtp server rejected message")
+	}
+	if err := sess.quit(); err != nil {
+		return result, errors.Wrap(err, "failed to quit smtp session")
+	}
+	return result, nil
+}
+
+//parseAddress Parse the from or to address of a message, rejecting line breaks which would inject SMTP commands
+func parseAddress(field, address string) (string, error) {
+	if strings.ContainsAny(address, "\r\n") {
+		return "", &ValidationError{Message: fmt.Sprintf("%s address must not contain line breaks", field)}
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", &ValidationError{Message: fmt.Sprintf("invalid %s address %s: %v", field, address, err)}
+	}
+	return parsed.Address, nil
+}
+
+func buildMessageData(msg *Message, messageID string) []byte {
+	data := &bytes.Buffer{}
+	headers := [][2]string{
+		{"From", msg.From},
+		{"To", msg.To},
+		{"Subject", msg.Subject},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(data, "%s: %s\r\n", h[0], h[1])
+	}
+	data.WriteString("\r\n")
+	data.WriteString(strings.Replace(strings.Replace(msg.Body, "\r\n", "\n", -1), "\n", "\r\n", -1))
+	return data.Bytes()
+}
+
+func newMessageID(from string) (string, error) {
+	randomBytes := make([]byte, 8)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", errors.Wrap(err, "failed to generate message id")
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at != -1 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(randomBytes), domain), nil
+}
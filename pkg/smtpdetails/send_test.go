@@ -0,0 +1,159 @@
+package smtpdetails
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSender_Send(t *testing.T) {
+	serverTLS, clientTLS := newMockTLSConfigs(t)
+	tests := []struct {
+		name       string
+		mechanisms string
+		password   string
+		msg        *Message
+		expectErr  bool
+		//expectValidationErr The message is rejected before the smtp session is started
+		expectValidationErr bool
+		validate            func(t *testing.T, result *SendResult, server *mockSMTPServer)
+	}{
+		{
+			name:       "should send message and redact credentials in transcript",
+			mechanisms: "PLAIN",
+			password:   "test",
+			msg:        &Message{From: "from@example.com", To: "to@example.com", Subject: "test", Body: "line one\n.\nline three"},
+			validate: func(t *testing.T, result *SendResult, server *mockSMTPServer) {
+				received := server.received()
+				if len(received) != 1 {
+					t.Fatalf("expected 1 message, got %d", len(received))
+				}
+				if !strings.Contains(received[0], "Message-ID: "+result.MessageID) {
+					t.Fatalf("expected message id %s in message %s", result.MessageID, received[0])
+				}
+				if !strings.HasSuffix(received[0], "line one\r\n..\r\nline three") {
+					t.Fatalf("expected dot stuffed body, got %s", received[0])
+				}
+				if !strings.HasSuffix(result.MessageID, "@example.com>") {
+					t.Fatalf("unexpected message id %s", result.MessageID)
+				}
+				if !strings.Contains(result.Response, "queued") {
+					t.Fatalf("unexpected response %s", result.Response)
+				}
+				transcript := strings.Join(result.Transcript, "\n")
+				if !strings.Contains(transcript, "C: AUTH PLAIN <redacted>") || !strings.Contains(transcript, "-- tls negotiated") {
+					t.Fatalf("unexpected transcript %s", transcript)
+				}
+			},
+		},
+		{
+			name:       "should redact password when using LOGIN",
+			mechanisms: "LOGIN",
+			password:   "test",
+			msg:        &Message{From: "from@example.com", To: "to@example.com"},
+			validate: func(t *testing.T, result *SendResult, server *mockSMTPServer) {
+				transcript := strings.Join(result.Transcript, "\n")
+				if strings.Contains(transcript, "dGVzdA==") || !strings.Contains(transcript, "C: <redacted>") {
+					t.Fatalf("expected password to be redacted in transcript %s", transcript)
+				}
+			},
+		},
+		{
+			name:       "should return transcript on authentication failure",
+			mechanisms: "PLAIN",
+			password:   "wrong",
+			msg:        &Message{From: "from@example.com", To: "to@example.com"},
+			expectErr:  true,
+			validate: func(t *testing.T, result *SendResult, server *mockSMTPServer) {
+				if len(server.received()) != 0 {
+					t.Fatal("expected no message to be sent")
+				}
+				transcript := strings.Join(result.Transcript, "\n")
+				if !strings.Contains(transcript, "S: 535") {
+					t.Fatalf("expected rejection in transcript %s", transcript)
+				}
+			},
+		},
+		{
+			name:                "should reject smtp commands injected in the recipient",
+			mechanisms:          "PLAIN",
+			password:            "test",
+			msg:                 &Message{From: "from@example.com", To: "to@example.com>\r\nRCPT TO:<other@example.com"},
+			expectErr:           true,
+			expectValidationErr: true,
+			validate: func(t *testing.T, result *SendResult, server *mockSMTPServer) {
+				if len(server.received()) != 0 || len(result.Transcript) != 0 {
+					t.Fatalf("expected no smtp session, got transcript %v", result.Transcript)
+				}
+			},
+		},
+		{
+			name:                "should reject invalid sender",
+			mechanisms:          "PLAIN",
+			password:            "test",
+			msg:                 &Message{From: "not an address", To: "to@example.com"},
+			expectErr:           true,
+			expectValidationErr: true,
+		},
+		{
+			name:       "should send to the address of a recipient with a display name",
+			mechanisms: "PLAIN",
+			password:   "test",
+			msg:        &Message{From: "from@example.com", To: "Test <to@example.com>"},
+			validate: func(t *testing.T, result *SendResult, server *mockSMTPServer) {
+				if transcript := strings.Join(result.Transcript, "\n"); !strings.Contains(transcript, "C: RCPT TO:<to@example.com>") {
+					t.Fatalf("expected bare recipient address in transcript %s", transcript)
+				}
+			},
+		},
+		{
+			name:       "should fail without recipient",
+			mechanisms: "PLAIN",
+			password:   "test",
+			msg:        &Message{From: "from@example.com"},
+			expectErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockSMTPServer(t, serverTLS, false, true, tt.mechanisms)
+			defer server.close()
+			details := &SMTPDetails{
+				Host:     "127.0.0.1",
+				Port:     server.port(),
+				TLS:      true,
+				Username: "apikey",
+				Password: tt.password,
+			}
+			result, err := NewSender(5*time.Second, clientTLS, false).Send(details, tt.msg)
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if tt.expectValidationErr && !IsValidationError(err) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Fatal("expected result to be returned")
+			}
+			if tt.validate != nil {
+				tt.validate(t, result, server)
+			}
+		})
+	}
+}
+
+func TestNewTestMessage(t *testing.T) {
+	msg, err := NewTestMessage("cluster", "from@example.com", "to@example.com", newMockSMTPDetails())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "SMTP test for cluster cluster" {
+		t.Fatalf("unexpected subject %s", msg.Subject)
+	}
+	if !strings.Contains(msg.Body, mockHost) || strings.Contains(msg.Body, "Password") {
+		t.Fatalf("unexpected body %s", msg.Body)
+	}
+}
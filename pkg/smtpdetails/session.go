@@ -0,0 +1,260 @@
+package smtpdetails
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	transcriptClientPrefix = "C: "
+	transcriptServerPrefix = "S: "
+	transcriptRedacted     = "<redacted>"
+)
+
+//dialer Connection settings shared by the Verifier and Sender
+type dialer struct {
+	timeout      time.Duration
+	tlsConfig    *tls.Config
+	insecureAuth bool
+}
+
+func newDialer(timeout time.Duration, tlsConfig *tls.Config, insecureAuth bool) dialer {
+	if timeout <= 0 {
+		timeout = DefaultVerifyTimeout
+	}
+	return dialer{
+		timeout:      timeout,
+		tlsConfig:    tlsConfig,
+		insecureAuth: insecureAuth,
+	}
+}
+
+//session An SMTP session recording a transcript of the conversation, with credentials redacted
+type session struct {
+	conn       net.Conn
+	text       *textproto.Conn
+	addr       string
+	extensions map[string]string
+	transcript []string
+}
+
+//dial Open an authenticated SMTP session using the details, the returned session is always non-nil so the transcript
+//can be reported on failure
+func (d dialer) dial(details *SMTPDetails) (*session, error) {
+	s := &session{}
+	if details == nil {
+		return s, errors.New("smtp details must be defined")
+	}
+	s.addr = net.JoinHostPort(details.Host, strconv.Itoa(details.Port))
+	tlsConfig := d.clientTLSConfig(details.Host)
+	netDialer := &net.Dialer{Timeout: d.timeout}
+	var conn net.Conn
+	var err error
+	tlsMode := details.GetTLSMode()
+	if tlsMode == TLSModeImplicit {
+		conn, err = tls.DialWithDialer(netDialer, "tcp", s.addr, tlsConfig)
+	} else {
+		conn, err = netDialer.Dial("tcp", s.addr)
+	}
+	if err != nil {
+		return s, &ConnectionError{Message: fmt.Sprintf("failed to connect to smtp server %s: %v", s.addr, err)}
+	}
+	if err := conn.SetDeadline(time.Now().Add(d.timeout)); err != nil {
+		conn.Close()
+		return s, errors.Wrap(err, "failed to set smtp connection deadline")
+	}
+	s.setConn(conn)
+	if tlsMode == TLSModeImplicit {
+		s.note("tls negotiated on connect")
+	}
+	if _, err := s.readResponse(220); err != nil {
+		s.close()
+		return s, &ConnectionError{Message: fmt.Sprintf("failed to start smtp session with %s: %v", s.addr, err)}
+	}
+	if err := s.hello(); err != nil {
+		s.close()
+		return s, &ConnectionError{Message: fmt.Sprintf("failed to start smtp session with %s: %v", s.addr, err)}
+	}
+	encrypted := tlsMode == TLSModeImplicit
+	startTLSSupported, _ := s.extension("STARTTLS")
+	if tlsMode == TLSModeStartTLS || (tlsMode == TLSModeOpportunistic && startTLSSupported) {
+		if err := s.startTLS(tlsConfig); err != nil {
+			s.close()
+			return s, &ConnectionError{Message: fmt.Sprintf("failed to negotiate STARTTLS with %s: %v", s.addr, err)}
+		}
+		encrypted = true
+	}
+	if !encrypted && !d.insecureAuth {
+		// the password is an api key, it must not be sent in cleartext unless explicitly allowed
+		s.note("tls not negotiated, authentication refused")
+		s.close()
+		return s, &ConnectionError{Message: fmt.Sprintf("refusing to authenticate with %s without tls, tls mode is %s and STARTTLS supported=%t", s.addr, tlsMode, startTLSSupported)}
+	}
+	if err := s.auth(details); err != nil {
+		s.close()
+		return s, err
+	}
+	return s, nil
+}
+
+func (d dialer) clientTLSConfig(host string) *tls.Config {
+	if d.tlsConfig == nil {
+		return &tls.Config{ServerName: host}
+	}
+	tlsConfig := d.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+	return tlsConfig
+}
+
+func (s *session) setConn(conn net.Conn) {
+	s.conn = conn
+	s.text = textproto.NewConn(conn)
+}
+
+func (s *session) close() {
+	if s.text != nil {
+		s.text.Close()
+	}
+}
+
+func (s *session) note(message string) {
+	s.transcript = append(s.transcript, "-- "+message)
+}
+
+//cmd Send a command and read the response, logged is recorded in the transcript in place of the command when set
+func (s *session) cmd(expectCode int, logged string, format string, args ...interface{}) (string, error) {
+	if logged == "" {
+		logged = fmt.Sprintf(format, args...)
+	}
+	s.transcript = append(s.transcript, transcriptClientPrefix+logged)
+	if err := s.text.PrintfLine(format, args...); err != nil {
+		return "", err
+	}
+	return s.readResponse(expectCode)
+}
+
+func (s *session) readResponse(expectCode int) (string, error) {
+	code, message, err := s.text.ReadResponse(expectCode)
+	if code != 0 {
+		for _, line := range strings.Split(message, "\n") {
+			s.transcript = append(s.transcript, fmt.Sprintf("%s%d %s", transcriptServerPrefix, code, line))
+		}
+	}
+	return message, err
+}
+
+//hello Send EHLO and record the extensions advertised by the server
+func (s *session) hello() error {
+	message, err := s.cmd(250, "", "EHLO localhost")
+	if err != nil {
+		return err
+	}
+	s.extensions = map[string]string{}
+	lines := strings.Split(message, "\n")
+	for _, line := range lines[1:] {
+		parts := strings.SplitN(line, " ", 2)
+		params := ""
+		if len(parts) > 1 {
+			params = parts[1]
+		}
+		s.extensions[strings.ToUpper(parts[0])] = params
+	}
+	return nil
+}
+
+func (s *session) extension(name string) (bool, string) {
+	params, ok := s.extensions[name]
+	return ok, params
+}
+
+func (s *session) startTLS(tlsConfig *tls.Config) error {
+	if ok, _ := s.extension("STARTTLS"); !ok {
+		return errors.New("server does not support STARTTLS")
+	}
+	if _, err := s.cmd(220, "", "STARTTLS"); err != nil {
+		return err
+	}
+	tlsConn := tls.Client(s.conn, tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+	s.setConn(tlsConn)
+	s.note("tls negotiated")
+	return s.hello()
+}
+
+//auth Authenticate with PLAIN or LOGIN based on the mechanisms advertised by the server
+func (s *session) auth(details *SMTPDetails) error {
+	ok, mechanisms := s.extension("AUTH")
+	if !ok {
+		return &AuthenticationError{Message: fmt.Sprintf("smtp server %s does not support authentication", s.addr)}
+	}
+	var err error
+	switch selectAuthMechanism(mechanisms) {
+	case "PLAIN":
+		credentials := base64.StdEncoding.EncodeToString([]byte("\x00" + details.Username + "\x00" + details.Password))
+		_, err = s.cmd(235, "AUTH PLAIN "+transcriptRedacted, "AUTH PLAIN %s", credentials)
+	case "LOGIN":
+		err = s.authLogin(details)
+	default:
+		return &AuthenticationError{Message: fmt.Sprintf("smtp server %s does not support PLAIN or LOGIN authentication, supported=%s", s.addr, mechanisms)}
+	}
+	if err != nil {
+		return &AuthenticationError{Message: fmt.Sprintf("smtp server %s rejected credentials of user %s: %v", s.addr, details.Username, err)}
+	}
+	return nil
+}
+
+func (s *session) authLogin(details *SMTPDetails) error {
+	challenge, err := s.cmd(334, "", "AUTH LOGIN")
+	for i := 0; i < 2; i++ {
+		if err != nil {
+			return err
+		}
+		decoded, decodeErr := base64.StdEncoding.DecodeString(challenge)
+		if decodeErr != nil {
+			return errors.Wrap(decodeErr, "failed to decode LOGIN challenge")
+		}
+		expectCode := 334
+		if i == 1 {
+			expectCode = 235
+		}
+		switch strings.ToLower(strings.TrimSpace(string(decoded))) {
+		case "username:":
+			challenge, err = s.cmd(expectCode, "", "%s", base64.StdEncoding.EncodeToString([]byte(details.Username)))
+		case "password:":
+			challenge, err = s.cmd(expectCode, transcriptRedacted, "%s", base64.StdEncoding.EncodeToString([]byte(details.Password)))
+		default:
+			return errors.New(fmt.Sprintf("unexpected server challenge during LOGIN authentication: %s", decoded))
+		}
+	}
+	return err
+}
+
+func selectAuthMechanism(mechanisms string) string {
+	supported := strings.Fields(strings.ToUpper(mechanisms))
+	for _, preferred := range []string{"PLAIN", "LOGIN"} {
+		for _, m := range supported {
+			if m == preferred {
+				return m
+			}
+		}
+	}
+	return ""
+}
+
+func (s *session) quit() error {
+	defer s.close()
+	_, err := s.cmd(221, "", "QUIT")
+	return err
+}
@@ -13,11 +13,15 @@ type SMTPDetails struct {
 	Host     string
 	Port     int
 	TLS      bool
+	TLSMode  TLSMode
 	Username string
 	Password string
+	//Annotations Metadata about where the details were issued, e.g. the provider account, added to the secret
+	Annotations map[string]string `json:",omitempty"`
 }
 
 //Client Client to create SMTP details for an OpenShift cluster by it's ID
+//go:generate moq -out smtpdetails_moq.go . Client
 type Client interface {
 	Create(id string) (*SMTPDetails, error)
 	Get(id string) (*SMTPDetails, error)
@@ -25,23 +29,53 @@ type Client interface {
 	Delete(id string) error
 }
 
+//SecretOption Option to customise the secret created from SMTPDetails
+type SecretOption func(keys *SecretKeys)
+
+//WithSecretKeys Write the SMTP details to custom secret data keys, a blank key omits the detail from the secret
+func WithSecretKeys(custom *SecretKeys) SecretOption {
+	return func(keys *SecretKeys) {
+		*keys = *custom
+	}
+}
+
 //ConvertSMTPDetailsToSecret Format a standard set of SMTPDetails as a Kubernetes Secret
-func ConvertSMTPDetailsToSecret(smtpDetails *SMTPDetails, secretName string) *apiv1.Secret {
+func ConvertSMTPDetailsToSecret(smtpDetails *SMTPDetails, secretName string, opts ...SecretOption) *apiv1.Secret {
+	keys := DefaultSecretKeys()
+	for _, opt := range opts {
+		opt(keys)
+	}
+	data := map[string][]byte{}
+	setData := func(key, value string) {
+		if key != "" {
+			data[key] = []byte(value)
+		}
+	}
+	setData(keys.Host, smtpDetails.Host)
+	setData(keys.Port, strconv.Itoa(smtpDetails.Port))
+	setData(keys.TLS, strconv.FormatBool(smtpDetails.GetTLSMode().RequiresTLS()))
+	setData(keys.TLSMode, string(smtpDetails.GetTLSMode()))
+	setData(keys.StartTLS, strconv.FormatBool(smtpDetails.GetTLSMode() == TLSModeStartTLS))
+	setData(keys.ImplicitTLS, strconv.FormatBool(smtpDetails.GetTLSMode() == TLSModeImplicit))
+	setData(keys.Username, smtpDetails.Username)
+	setData(keys.Password, smtpDetails.Password)
+	var annotations map[string]string
+	if len(smtpDetails.Annotations) > 0 {
+		annotations = map[string]string{}
+		for k, v := range smtpDetails.Annotations {
+			annotations[k] = v
+		}
+	}
 	return &apiv1.Secret{
 		TypeMeta: v1.TypeMeta{
 			Kind:       SecretGVKKind,
 			APIVersion: SecretGVKVersion,
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name: secretName,
-		},
-		Data: map[string][]byte{
-			SecretKeyHost:     []byte(smtpDetails.Host),
-			SecretKeyPort:     []byte(strconv.Itoa(smtpDetails.Port)),
-			SecretKeyTLS:      []byte(strconv.FormatBool(smtpDetails.TLS)),
-			SecretKeyUsername: []byte(smtpDetails.Username),
-			SecretKeyPassword: []byte(smtpDetails.Password),
+			Name:        secretName,
+			Annotations: annotations,
 		},
+		Data: data,
 		Type: apiv1.SecretTypeOpaque,
 	}
 }
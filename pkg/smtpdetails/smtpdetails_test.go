@@ -34,6 +34,7 @@ func TestConvertSMTPDetailsToSecret(t *testing.T) {
 	type args struct {
 		smtpDetails *SMTPDetails
 		secretName  string
+		opts        []SecretOption
 	}
 	tests := []struct {
 		name string
@@ -58,18 +59,136 @@ func TestConvertSMTPDetailsToSecret(t *testing.T) {
 					SecretKeyPassword: []byte(mockPassword),
 					SecretKeyUsername: []byte(mockUsername),
 					SecretKeyTLS:      []byte(strconv.FormatBool(mockTLS)),
+					SecretKeyTLSMode:  []byte(TLSModeStartTLS),
 					SecretKeyPort:     []byte(strconv.Itoa(mockPort)),
 					SecretKeyHost:     []byte(mockHost),
 				},
 				Type: apiv1.SecretTypeOpaque,
 			},
 		},
+		{
+			name: "should write legacy tls key from tls mode",
+			args: args{
+				smtpDetails: &SMTPDetails{
+					Host:     mockHost,
+					Port:     mockPort,
+					TLSMode:  TLSModeOpportunistic,
+					Username: mockUsername,
+					Password: mockPassword,
+				},
+				secretName: "testSec",
+			},
+			want: &apiv1.Secret{
+				TypeMeta: v1.TypeMeta{
+					Kind:       SecretGVKKind,
+					APIVersion: SecretGVKVersion,
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name: "testSec",
+				},
+				Data: map[string][]byte{
+					SecretKeyPassword: []byte(mockPassword),
+					SecretKeyUsername: []byte(mockUsername),
+					SecretKeyTLS:      []byte("false"),
+					SecretKeyTLSMode:  []byte(TLSModeOpportunistic),
+					SecretKeyPort:     []byte(strconv.Itoa(mockPort)),
+					SecretKeyHost:     []byte(mockHost),
+				},
+				Type: apiv1.SecretTypeOpaque,
+			},
+		},
+		{
+			name: "should write custom keys and omit blank keys",
+			args: args{
+				smtpDetails: newMockSMTPDetails(),
+				secretName:  "testSec",
+				opts:        []SecretOption{WithSecretKeys(SecretKeyPresets[SecretKeyPresetDjango])},
+			},
+			want: &apiv1.Secret{
+				TypeMeta: v1.TypeMeta{
+					Kind:       SecretGVKKind,
+					APIVersion: SecretGVKVersion,
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name: "testSec",
+				},
+				Data: map[string][]byte{
+					"EMAIL_HOST":          []byte(mockHost),
+					"EMAIL_PORT":          []byte(strconv.Itoa(mockPort)),
+					"EMAIL_USE_TLS":       []byte(strconv.FormatBool(mockTLS)),
+					"EMAIL_USE_SSL":       []byte("false"),
+					"EMAIL_HOST_USER":     []byte(mockUsername),
+					"EMAIL_HOST_PASSWORD": []byte(mockPassword),
+				},
+				Type: apiv1.SecretTypeOpaque,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := ConvertSMTPDetailsToSecret(tt.args.smtpDetails, tt.args.secretName); !reflect.DeepEqual(got, tt.want) {
+			if got := ConvertSMTPDetailsToSecret(tt.args.smtpDetails, tt.args.secretName, tt.args.opts...); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ConvertSMTPDetailsToSecret() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestConvertSMTPDetailsToSecret_TLSModeKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         TLSMode
+		wantStartTLS string
+		wantImplicit string
+	}{
+		{
+			name:         "should disable starttls and ssl for no tls",
+			mode:         TLSModeNone,
+			wantStartTLS: "false",
+			wantImplicit: "false",
+		},
+		{
+			name:         "should disable starttls and ssl for opportunistic tls",
+			mode:         TLSModeOpportunistic,
+			wantStartTLS: "false",
+			wantImplicit: "false",
+		},
+		{
+			name:         "should enable starttls only for starttls",
+			mode:         TLSModeStartTLS,
+			wantStartTLS: "true",
+			wantImplicit: "false",
+		},
+		{
+			name:         "should enable ssl only for implicit tls",
+			mode:         TLSModeImplicit,
+			wantStartTLS: "false",
+			wantImplicit: "true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := newMockSMTPDetails()
+			details.TLSMode = tt.mode
+			keys := SecretKeyPresets[SecretKeyPresetSpring]
+			got := ConvertSMTPDetailsToSecret(details, "test", WithSecretKeys(keys))
+			if value := string(got.Data[keys.StartTLS]); value != tt.wantStartTLS {
+				t.Errorf("ConvertSMTPDetailsToSecret() %s = %s, want %s", keys.StartTLS, value, tt.wantStartTLS)
+			}
+			if value := string(got.Data[keys.ImplicitTLS]); value != tt.wantImplicit {
+				t.Errorf("ConvertSMTPDetailsToSecret() %s = %s, want %s", keys.ImplicitTLS, value, tt.wantImplicit)
+			}
+			read, err := ConvertSecretToSMTPDetails(got, keys)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wantMode := tt.mode
+			if wantMode == TLSModeOpportunistic {
+				// opportunistic tls is not distinguishable from no tls by the starttls and ssl keys
+				wantMode = TLSModeNone
+			}
+			if read.TLSMode != wantMode {
+				t.Errorf("ConvertSecretToSMTPDetails() tls mode = %s, want %s", read.TLSMode, wantMode)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package smtpdetails
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//mockSMTPServer Minimal in-process SMTP server supporting STARTTLS, implicit TLS and PLAIN/LOGIN authentication
+type mockSMTPServer struct {
+	listener   net.Listener
+	tlsConfig  *tls.Config
+	username   string
+	password   string
+	mechanisms string
+	startTLS   bool
+	mu         sync.Mutex
+	authed     bool
+	messages   []string
+}
+
+//newMockTLSConfigs Create a server TLS config with a self-signed certificate for 127.0.0.1 and a client config trusting it
+func newMockTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	serverConfig := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	clientConfig := &tls.Config{RootCAs: pool}
+	return serverConfig, clientConfig
+}
+
+//newMockSMTPServer Start a mock SMTP server, implicitTLS wraps the listener in TLS, otherwise STARTTLS is offered if
+//startTLS is set
+func newMockSMTPServer(t *testing.T, serverTLS *tls.Config, implicitTLS, startTLS bool, mechanisms string) *mockSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	if implicitTLS {
+		listener = tls.NewListener(listener, serverTLS)
+	}
+	s := &mockSMTPServer{
+		listener:   listener,
+		tlsConfig:  serverTLS,
+		username:   "apikey",
+		password:   "test",
+		mechanisms: mechanisms,
+		startTLS:   startTLS,
+	}
+	go s.serve()
+	return s
+}
+
+func (s *mockSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *mockSMTPServer) close() {
+	s.listener.Close()
+}
+
+func (s *mockSMTPServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
+
+func (s *mockSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *mockSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	_, isTLS := conn.(*tls.Conn)
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	reply := func(line string) {
+		rw.WriteString(line + "\r\n")
+		rw.Flush()
+	}
+	readLine := func() (string, bool) {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return "", false
+		}
+		return strings.TrimRight(line, "\r\n"), true
+	}
+	checkCredentials := func(username, password string) {
+		if username == s.username && password == s.password {
+			reply("235 2.7.0 Authentication successful")
+			return
+		}
+		reply("535 5.7.8 Authentication failed")
+	}
+	reply("220 127.0.0.1 ESMTP mock")
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			reply("500 5.5.2 Syntax error")
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "EHLO", "HELO":
+			rw.WriteString("250-127.0.0.1\r\n")
+			if s.startTLS && !isTLS {
+				rw.WriteString("250-STARTTLS\r\n")
+			}
+			if s.mechanisms != "" {
+				rw.WriteString("250-AUTH " + s.mechanisms + "\r\n")
+			}
+			reply("250 8BITMIME")
+		case "STARTTLS":
+			reply("220 2.0.0 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			isTLS = true
+			rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+		case "AUTH":
+			if len(fields) < 2 {
+				reply("501 5.5.4 Syntax error")
+				continue
+			}
+			switch strings.ToUpper(fields[1]) {
+			case "PLAIN":
+				if len(fields) < 3 {
+					reply("501 5.5.4 Syntax error")
+					continue
+				}
+				decoded, _ := base64.StdEncoding.DecodeString(fields[2])
+				parts := strings.Split(string(decoded), "\x00")
+				if len(parts) != 3 {
+					reply("501 5.5.4 Syntax error")
+					continue
+				}
+				checkCredentials(parts[1], parts[2])
+			case "LOGIN":
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				userLine, ok := readLine()
+				if !ok {
+					return
+				}
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				passLine, ok := readLine()
+				if !ok {
+					return
+				}
+				username, _ := base64.StdEncoding.DecodeString(userLine)
+				password, _ := base64.StdEncoding.DecodeString(passLine)
+				checkCredentials(string(username), string(password))
+			default:
+				reply("504 5.5.4 Unrecognized authentication type")
+			}
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 2.0.0 OK")
+		case "DATA":
+			reply("354 Start mail input; end with <CRLF>.<CRLF>")
+			var data []string
+			for {
+				dataLine, ok := readLine()
+				if !ok {
+					return
+				}
+				if dataLine == "." {
+					break
+				}
+				data = append(data, dataLine)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, strings.Join(data, "\r\n"))
+			s.mu.Unlock()
+			reply("250 2.0.0 OK queued")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not implemented")
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package smtpdetails
 
+import (
+	"time"
+)
+
 const (
 	//LogFieldDetailProvider Logging key for specifying the SMTP details provider e.g. SendGrid
 	LogFieldDetailProvider = "smtp_service_detail_provider"
@@ -9,6 +13,14 @@ const (
 	SecretKeyPort = "port"
 	//SecretKeyTLS Default secret data key for SMTP TLS
 	SecretKeyTLS = "tls"
+	//SecretKeyTLSMode Default secret data key for SMTP TLS mode, one of none, opportunistic, starttls or implicit
+	SecretKeyTLSMode = "tls_mode"
+	//SecretKeyStartTLS Name of the secret key mapping detail holding true only for the starttls TLS mode, not written by
+	//default
+	SecretKeyStartTLS = "starttls"
+	//SecretKeyImplicitTLS Name of the secret key mapping detail holding true only for the implicit TLS mode, not written
+	//by default
+	SecretKeyImplicitTLS = "implicit_tls"
 	//SecretKeyUsername Default secret data key for SMTP auth username
 	SecretKeyUsername = "username"
 	//SecretKeyPassword Default secret data key for SMTP auth password
@@ -18,3 +30,38 @@ const (
 	//SecretGVKVersion GVK Version of an OpenShift/Kubernetes Secret
 	SecretGVKVersion = "v1"
 )
+
+const (
+	//DefaultVerifyTimeout Default time allowed for verifying SMTP details against an SMTP server
+	DefaultVerifyTimeout = 10 * time.Second
+	//ImplicitTLSPort SMTP port on which the legacy TLS setting means TLS is negotiated on connect instead of STARTTLS
+	ImplicitTLSPort = 465
+	//IDHashLength Number of hex characters of the hash appended to shortened cluster ids
+	IDHashLength = 8
+)
+
+const (
+	//SecretKeyPresetDefault Name of the preset using the default secret keys
+	SecretKeyPresetDefault = "default"
+	//SecretKeyPresetEnv Name of the preset using SMTP_ prefixed environment variable style keys
+	SecretKeyPresetEnv = "env"
+	//SecretKeyPresetDjango Name of the preset using the keys of the Django email settings
+	SecretKeyPresetDjango = "django"
+	//SecretKeyPresetSpring Name of the preset using the environment variables of the Spring Boot mail properties
+	SecretKeyPresetSpring = "spring"
+)
+
+const (
+	//OperationCreate Operation label value of creating SMTP details
+	OperationCreate = "create"
+	//OperationGet Operation label value of retrieving SMTP details
+	OperationGet = "get"
+	//OperationRefresh Operation label value of refreshing SMTP details
+	OperationRefresh = "refresh"
+	//OperationDelete Operation label value of deleting SMTP details
+	OperationDelete = "delete"
+	//MetricOperationsTotal Prometheus metric name of the SMTP details operation counters
+	MetricOperationsTotal = "smtp_service_operations_total"
+	//MetricOperationDurationSeconds Prometheus metric name of the SMTP details operation duration histograms
+	MetricOperationDurationSeconds = "smtp_service_operation_duration_seconds"
+)
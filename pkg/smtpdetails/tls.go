@@ -0,0 +1,57 @@
+package smtpdetails
+
+import (
+	"fmt"
+	"strings"
+)
+
+//TLSMode How TLS is negotiated with an SMTP server
+type TLSMode string
+
+const (
+	//TLSModeNone Connect without TLS
+	TLSModeNone TLSMode = "none"
+	//TLSModeOpportunistic Upgrade the connection with STARTTLS if the server supports it, otherwise continue without TLS
+	TLSModeOpportunistic TLSMode = "opportunistic"
+	//TLSModeStartTLS Upgrade the connection with STARTTLS, failing if the server does not support it, usually on port 587
+	TLSModeStartTLS TLSMode = "starttls"
+	//TLSModeImplicit Negotiate TLS on connect, usually on port 465
+	TLSModeImplicit TLSMode = "implicit"
+)
+
+//TLSModes All supported TLS modes
+var TLSModes = []TLSMode{TLSModeNone, TLSModeOpportunistic, TLSModeStartTLS, TLSModeImplicit}
+
+//ParseTLSMode Parse a TLS mode by name, case insensitive
+func ParseTLSMode(mode string) (TLSMode, error) {
+	for _, m := range TLSModes {
+		if strings.EqualFold(strings.TrimSpace(mode), string(m)) {
+			return m, nil
+		}
+	}
+	return "", &ValidationError{Message: fmt.Sprintf("tls mode %s is invalid, supported modes are %v", mode, TLSModes)}
+}
+
+//RequiresTLS Whether the connection must be encrypted, used for the legacy tls secret key
+func (m TLSMode) RequiresTLS() bool {
+	return m == TLSModeStartTLS || m == TLSModeImplicit
+}
+
+//GetTLSMode The TLS mode of the details, derived from the legacy TLS field and port if the mode is not set
+func (d *SMTPDetails) GetTLSMode() TLSMode {
+	if d.TLSMode != "" {
+		return d.TLSMode
+	}
+	return legacyTLSMode(d.TLS, d.Port)
+}
+
+//legacyTLSMode Map the legacy tls bool to a TLS mode, TLS on port 465 is always implicit
+func legacyTLSMode(tls bool, port int) TLSMode {
+	if !tls {
+		return TLSModeNone
+	}
+	if port == ImplicitTLSPort {
+		return TLSModeImplicit
+	}
+	return TLSModeStartTLS
+}
@@ -0,0 +1,83 @@
+package smtpdetails
+
+import (
+	"testing"
+)
+
+func TestParseTLSMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		want      TLSMode
+		expectErr bool
+	}{
+		{
+			name: "should parse mode case insensitive",
+			mode: " STARTTLS",
+			want: TLSModeStartTLS,
+		},
+		{
+			name: "should parse implicit mode",
+			mode: "implicit",
+			want: TLSModeImplicit,
+		},
+		{
+			name:      "should fail on unknown mode",
+			mode:      "ssl",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTLSMode(tt.mode)
+			if tt.expectErr {
+				if !IsValidationError(err) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseTLSMode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSMTPDetails_GetTLSMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		details *SMTPDetails
+		want    TLSMode
+	}{
+		{
+			name:    "should prefer explicit mode",
+			details: &SMTPDetails{TLS: true, Port: 587, TLSMode: TLSModeOpportunistic},
+			want:    TLSModeOpportunistic,
+		},
+		{
+			name:    "should derive starttls from legacy setting",
+			details: &SMTPDetails{TLS: true, Port: 587},
+			want:    TLSModeStartTLS,
+		},
+		{
+			name:    "should derive implicit from legacy setting on port 465",
+			details: &SMTPDetails{TLS: true, Port: 465},
+			want:    TLSModeImplicit,
+		},
+		{
+			name:    "should derive none from legacy setting",
+			details: &SMTPDetails{Port: 25},
+			want:    TLSModeNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.details.GetTLSMode(); got != tt.want {
+				t.Fatalf("GetTLSMode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
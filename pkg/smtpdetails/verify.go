@@ -0,0 +1,31 @@
+package smtpdetails
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//Verifier Checks SMTP details can be used to authenticate against their SMTP server, without sending any mail
+type Verifier struct {
+	dialer
+}
+
+//NewVerifier Create a new Verifier, tlsConfig may be nil to verify the server certificate against the system roots.
+//Credentials are only sent over TLS unless insecureAuth is set.
+func NewVerifier(timeout time.Duration, tlsConfig *tls.Config, insecureAuth bool) *Verifier {
+	return &Verifier{dialer: newDialer(timeout, tlsConfig, insecureAuth)}
+}
+
+//Verify Connect to the SMTP server of the details, negotiate TLS, authenticate and quit
+func (v *Verifier) Verify(details *SMTPDetails) error {
+	s, err := v.dial(details)
+	if err != nil {
+		return err
+	}
+	if err := s.quit(); err != nil {
+		return errors.Wrap(err, "failed to quit smtp session")
+	}
+	return nil
+}
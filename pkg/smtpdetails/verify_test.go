@@ -0,0 +1,140 @@
+package smtpdetails
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	serverTLS, clientTLS := newMockTLSConfigs(t)
+	tests := []struct {
+		name         string
+		implicitTLS  bool
+		startTLS     bool
+		mechanisms   string
+		details      *SMTPDetails
+		insecureAuth bool
+		expectErr    bool
+		errCheck     func(error) bool
+	}{
+		{
+			name:       "should authenticate with PLAIN after STARTTLS",
+			startTLS:   true,
+			mechanisms: "PLAIN LOGIN",
+			details:    &SMTPDetails{TLSMode: TLSModeStartTLS, Password: "test"},
+		},
+		{
+			name:       "should use STARTTLS for the legacy TLS setting",
+			startTLS:   true,
+			mechanisms: "PLAIN",
+			details:    &SMTPDetails{TLS: true, Password: "test"},
+		},
+		{
+			name:       "should authenticate with LOGIN when PLAIN is not supported",
+			startTLS:   true,
+			mechanisms: "LOGIN",
+			details:    &SMTPDetails{TLSMode: TLSModeStartTLS, Password: "test"},
+		},
+		{
+			name:        "should authenticate with implicit TLS",
+			implicitTLS: true,
+			mechanisms:  "PLAIN",
+			details:     &SMTPDetails{TLSMode: TLSModeImplicit, Password: "test"},
+		},
+		{
+			name:         "should authenticate without TLS when not required and insecure auth is allowed",
+			mechanisms:   "PLAIN",
+			details:      &SMTPDetails{TLSMode: TLSModeNone, Password: "test"},
+			insecureAuth: true,
+		},
+		{
+			name:       "should refuse to authenticate without TLS when insecure auth is not allowed",
+			mechanisms: "PLAIN",
+			details:    &SMTPDetails{TLSMode: TLSModeNone, Password: "test"},
+			expectErr:  true,
+			errCheck:   IsConnectionError,
+		},
+		{
+			name:       "should use STARTTLS when opportunistic and supported",
+			startTLS:   true,
+			mechanisms: "PLAIN",
+			details:    &SMTPDetails{TLSMode: TLSModeOpportunistic, Password: "test"},
+		},
+		{
+			name:         "should continue without TLS when opportunistic, STARTTLS is not supported and insecure auth is allowed",
+			mechanisms:   "PLAIN",
+			details:      &SMTPDetails{TLSMode: TLSModeOpportunistic, Password: "test"},
+			insecureAuth: true,
+		},
+		{
+			name:       "should refuse to authenticate when opportunistic and STARTTLS is not supported",
+			mechanisms: "PLAIN",
+			details:    &SMTPDetails{TLSMode: TLSModeOpportunistic, Password: "test"},
+			expectErr:  true,
+			errCheck:   IsConnectionError,
+		},
+		{
+			name:       "should fail with authentication error on bad password",
+			startTLS:   true,
+			mechanisms: "PLAIN",
+			details:    &SMTPDetails{TLSMode: TLSModeStartTLS, Password: "wrong"},
+			expectErr:  true,
+			errCheck:   IsAuthenticationError,
+		},
+		{
+			name:       "should fail with authentication error on bad password with LOGIN",
+			startTLS:   true,
+			mechanisms: "LOGIN",
+			details:    &SMTPDetails{TLSMode: TLSModeStartTLS, Password: "wrong"},
+			expectErr:  true,
+			errCheck:   IsAuthenticationError,
+		},
+		{
+			name:       "should fail when TLS is required and STARTTLS is not supported",
+			mechanisms: "PLAIN",
+			details:    &SMTPDetails{TLSMode: TLSModeStartTLS, Password: "test"},
+			expectErr:  true,
+			errCheck:   IsConnectionError,
+		},
+		{
+			name:       "should fail when no supported mechanism is offered",
+			startTLS:   true,
+			mechanisms: "CRAM-MD5",
+			details:    &SMTPDetails{TLSMode: TLSModeStartTLS, Password: "test"},
+			expectErr:  true,
+			errCheck:   IsAuthenticationError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockSMTPServer(t, serverTLS, tt.implicitTLS, tt.startTLS, tt.mechanisms)
+			defer server.close()
+			tt.details.Host = "127.0.0.1"
+			tt.details.Port = server.port()
+			tt.details.Username = "apikey"
+			err := NewVerifier(5*time.Second, clientTLS, tt.insecureAuth).Verify(tt.details)
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.errCheck != nil && !tt.errCheck(err) {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+			if len(server.received()) != 0 {
+				t.Fatal("expected no mail to be sent")
+			}
+		})
+	}
+}
+
+func TestVerifier_VerifyConnectionRefused(t *testing.T) {
+	server := newMockSMTPServer(t, nil, false, false, "PLAIN")
+	port := server.port()
+	server.close()
+	err := NewVerifier(time.Second, nil, false).Verify(&SMTPDetails{Host: "127.0.0.1", Port: port, Username: "apikey", Password: "test"})
+	if !IsConnectionError(err) {
+		t.Fatalf("expected connection error, got %v", err)
+	}
+}
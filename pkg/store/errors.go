@@ -0,0 +1,17 @@
+package store
+
+//NotExistError Error to indicate a record does not exist in the store
+type NotExistError struct {
+	Message string
+}
+
+//Error String representation of error
+func (e *NotExistError) Error() string {
+	return e.Message
+}
+
+//IsNotExistError Compare check for NotExistError
+func IsNotExistError(err error) bool {
+	_, ok := err.(*NotExistError)
+	return ok
+}
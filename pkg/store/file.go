@@ -0,0 +1,185 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var _ Store = &FileStore{}
+
+//FileStore Store keeping all records in a single JSON file, written atomically on every change. Every read and change
+//holds a lock file next to the store file, so processes sharing the file, e.g. apply and rotate-daemon, do not overwrite
+//each other's changes.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+//NewFileStore Create a FileStore at path, the file is created on the first write
+func NewFileStore(path string) *FileStore {
+	if path == "" {
+		path = DefaultStorePath
+	}
+	return &FileStore{path: path}
+}
+
+//Get Retrieve the record of a cluster
+func (s *FileStore) Get(clusterID string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	record, ok := records[clusterID]
+	if !ok {
+		return nil, &NotExistError{Message: fmt.Sprintf("record for cluster %s does not exist", clusterID)}
+	}
+	return record, nil
+}
+
+//Put Create or replace the record of a cluster
+func (s *FileStore) Put(record *Record) error {
+	if record == nil || record.ClusterID == "" {
+		return errors.New("record must have a cluster id")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	records[record.ClusterID] = record
+	return s.write(records)
+}
+
+//Delete Remove the record of a cluster
+func (s *FileStore) Delete(clusterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := records[clusterID]; !ok {
+		return &NotExistError{Message: fmt.Sprintf("record for cluster %s does not exist", clusterID)}
+	}
+	delete(records, clusterID)
+	return s.write(records)
+}
+
+//List Retrieve all records sorted by cluster id
+func (s *FileStore) List() ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Record, 0, len(records))
+	for _, r := range records {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ClusterID < list[j].ClusterID
+	})
+	return list, nil
+}
+
+//lock Take the lock file of the store, waiting up to LockTimeout for another process to release it, and return the func
+//releasing it. A lock file older than LockStaleAge is removed, as operations on the store take milliseconds.
+func (s *FileStore) lock() (func(), error) {
+	lockPath := s.path + LockFileSuffix
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(lockPath)
+			}, nil
+		}
+		if os.IsNotExist(err) {
+			// the directory of the store does not exist, there is nothing to read and writing fails
+			return func() {}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrapf(err, "failed to lock store %s", s.path)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > LockStaleAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New(fmt.Sprintf("timed out waiting for lock %s of store %s, remove it if no other process is using the store", lockPath, s.path))
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (s *FileStore) read() (map[string]*Record, error) {
+	records := map[string]*Record{}
+	raw, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read store %s", s.path)
+	}
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse store %s", s.path)
+	}
+	return records, nil
+}
+
+//write Replace the store file by renaming a temporary file, so a failed write never leaves a partial store
+func (s *FileStore) write(records map[string]*Record) error {
+	raw, err := json.MarshalIndent(records, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal store")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary file for store %s", s.path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "failed to write store %s", s.path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write store %s", s.path)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return errors.Wrapf(err, "failed to set permissions of store %s", s.path)
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), s.path), "failed to replace store %s", s.path)
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+var mockNow = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newMockFileStore(t *testing.T) (*FileStore, func()) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	return NewFileStore(filepath.Join(dir, "state.json")), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	s, cleanup := newMockFileStore(t)
+	defer cleanup()
+	if _, err := s.Get("test"); !IsNotExistError(err) {
+		t.Fatalf("expected not exist error from empty store, got %v", err)
+	}
+	records := []*Record{
+		{ClusterID: "cluster-b", CreatedAt: mockNow},
+		{ClusterID: "cluster-a", CreatedAt: mockNow, RotatedAt: mockNow.Add(time.Hour)},
+	}
+	for _, r := range records {
+		if err := s.Put(r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// a new store on the same path must read the persisted records
+	reopened := NewFileStore(s.path)
+	got, err := reopened.Get("cluster-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, records[1]) {
+		t.Fatalf("Get() got = %v, want %v", got, records[1])
+	}
+	list, err := reopened.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 2 || list[0].ClusterID != "cluster-a" || list[1].ClusterID != "cluster-b" {
+		t.Fatalf("List() expected records sorted by cluster id, got %v", list)
+	}
+	if err := reopened.Delete("cluster-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reopened.Delete("cluster-a"); !IsNotExistError(err) {
+		t.Fatalf("expected not exist error deleting missing record, got %v", err)
+	}
+	if list, _ := s.List(); len(list) != 1 {
+		t.Fatalf("expected 1 record after delete, got %d", len(list))
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected store file mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestFileStore_Put(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  *Record
+		wantErr bool
+	}{
+		{
+			name:   "should store record",
+			record: &Record{ClusterID: "test", CreatedAt: mockNow},
+		},
+		{
+			name:    "should fail without record",
+			wantErr: true,
+		},
+		{
+			name:    "should fail without cluster id",
+			record:  &Record{CreatedAt: mockNow},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cleanup := newMockFileStore(t)
+			defer cleanup()
+			if err := s.Put(tt.record); (err != nil) != tt.wantErr {
+				t.Fatalf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileStore_Corrupt(t *testing.T) {
+	s, cleanup := newMockFileStore(t)
+	defer cleanup()
+	if err := ioutil.WriteFile(s.path, []byte("{"), 0600); err != nil {
+		t.Fatalf("failed to write store: %v", err)
+	}
+	if _, err := s.List(); err == nil {
+		t.Fatal("expected error reading corrupt store")
+	}
+	if err := s.Put(&Record{ClusterID: "test"}); err == nil {
+		t.Fatal("expected error writing to corrupt store")
+	}
+}
+
+func TestRecord_KeyIssuedAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		record *Record
+		want   time.Time
+	}{
+		{
+			name:   "should use creation time when never rotated",
+			record: &Record{CreatedAt: mockNow},
+			want:   mockNow,
+		},
+		{
+			name:   "should use rotation time when rotated",
+			record: &Record{CreatedAt: mockNow, RotatedAt: mockNow.Add(time.Hour)},
+			want:   mockNow.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.KeyIssuedAt(); !got.Equal(tt.want) {
+				t.Fatalf("KeyIssuedAt() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStore_ConcurrentStores(t *testing.T) {
+	s, cleanup := newMockFileStore(t)
+	defer cleanup()
+	// separate stores on the same path share nothing but the lock file, as separate processes would
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := NewFileStore(s.path).Put(&Record{ClusterID: fmt.Sprintf("cluster-%d", i), CreatedAt: mockNow}); err != nil {
+				t.Errorf("Put() unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 20 {
+		t.Fatalf("expected 20 records, got %d", len(list))
+	}
+	if _, err := os.Stat(s.path + LockFileSuffix); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file to be removed, got %v", err)
+	}
+}
+
+func TestFileStore_StaleLock(t *testing.T) {
+	s, cleanup := newMockFileStore(t)
+	defer cleanup()
+	lockPath := s.path + LockFileSuffix
+	if err := ioutil.WriteFile(lockPath, nil, 0600); err != nil {
+		t.Fatalf("failed to create lock file: %v", err)
+	}
+	stale := time.Now().Add(-LockStaleAge - time.Minute)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+	if err := s.Put(&Record{ClusterID: "test", CreatedAt: mockNow}); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//Status State of a cluster when comparing the store against the provider
+type Status string
+
+//Entry Result of reconciling a cluster between the store and the provider
+type Entry struct {
+	ClusterID string  `json:"clusterId"`
+	Status    Status  `json:"status"`
+	Record    *Record `json:"record,omitempty"`
+}
+
+//Reconcile Compare the records of the store against the clusters of the provider, sorted by cluster id.
+//
+//If update is true the store is changed to match the provider: unmanaged clusters are tracked from now, records of
+//missing clusters are deleted and the provider details of managed clusters are refreshed.
+func Reconcile(s Store, inventory Inventory, update bool, now time.Time) ([]*Entry, error) {
+	records, err := s.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list records")
+	}
+	ids, err := inventory.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters of provider")
+	}
+	recordsByID := map[string]*Record{}
+	for _, record := range records {
+		recordsByID[record.ClusterID] = record
+	}
+	var entries []*Entry
+	for _, id := range ids {
+		record, ok := recordsByID[id]
+		delete(recordsByID, id)
+		entry := &Entry{ClusterID: id, Status: StatusManaged, Record: record}
+		if !ok {
+			entry.Status = StatusUnmanaged
+		}
+		if update {
+			described, err := inventory.Describe(id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to describe cluster %s", id)
+			}
+			if record == nil {
+				record = &Record{ClusterID: id, CreatedAt: now}
+			}
+			record.UpdateFrom(described)
+			if err := s.Put(record); err != nil {
+				return nil, errors.Wrapf(err, "failed to update record of cluster %s", id)
+			}
+			entry.Record = record
+		}
+		entries = append(entries, entry)
+	}
+	for id, record := range recordsByID {
+		if update {
+			if err := s.Delete(id); err != nil {
+				return nil, errors.Wrapf(err, "failed to delete record of cluster %s", id)
+			}
+		}
+		entries = append(entries, &Entry{ClusterID: id, Status: StatusMissing, Record: record})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ClusterID < entries[j].ClusterID
+	})
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type mockInventory struct {
+	ids     []string
+	listErr error
+}
+
+func (i *mockInventory) List() ([]string, error) {
+	return i.ids, i.listErr
+}
+
+func (i *mockInventory) Describe(clusterID string) (*Record, error) {
+	return &Record{ClusterID: clusterID, Provider: "test", SubUser: clusterID, KeyID: "key-" + clusterID}, nil
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name        string
+		records     []*Record
+		inventory   *mockInventory
+		update      bool
+		want        map[string]Status
+		wantRecords []string
+		wantErr     bool
+	}{
+		{
+			name:        "should report managed, unmanaged and missing clusters",
+			records:     []*Record{{ClusterID: "managed", CreatedAt: mockNow}, {ClusterID: "missing", CreatedAt: mockNow}},
+			inventory:   &mockInventory{ids: []string{"managed", "unmanaged"}},
+			want:        map[string]Status{"managed": StatusManaged, "unmanaged": StatusUnmanaged, "missing": StatusMissing},
+			wantRecords: []string{"managed", "missing"},
+		},
+		{
+			name:        "should update store to match provider",
+			records:     []*Record{{ClusterID: "managed", CreatedAt: mockNow}, {ClusterID: "missing", CreatedAt: mockNow}},
+			inventory:   &mockInventory{ids: []string{"managed", "unmanaged"}},
+			update:      true,
+			want:        map[string]Status{"managed": StatusManaged, "unmanaged": StatusUnmanaged, "missing": StatusMissing},
+			wantRecords: []string{"managed", "unmanaged"},
+		},
+		{
+			name:      "should fail when listing provider clusters fails",
+			inventory: &mockInventory{listErr: errors.New("test")},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cleanup := newMockFileStore(t)
+			defer cleanup()
+			for _, r := range tt.records {
+				if err := s.Put(r); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			entries, err := Reconcile(s, tt.inventory, tt.update, mockNow.Add(time.Hour))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("Reconcile() got %d entries, want %d", len(entries), len(tt.want))
+			}
+			for i, entry := range entries {
+				if i > 0 && entries[i-1].ClusterID > entry.ClusterID {
+					t.Fatalf("expected entries sorted by cluster id, got %s before %s", entries[i-1].ClusterID, entry.ClusterID)
+				}
+				if entry.Status != tt.want[entry.ClusterID] {
+					t.Fatalf("cluster %s got status %s, want %s", entry.ClusterID, entry.Status, tt.want[entry.ClusterID])
+				}
+			}
+			records, err := s.List()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(records) != len(tt.wantRecords) {
+				t.Fatalf("store has %d records, want %v", len(records), tt.wantRecords)
+			}
+			for i, record := range records {
+				if record.ClusterID != tt.wantRecords[i] {
+					t.Fatalf("store has record %s, want %s", record.ClusterID, tt.wantRecords[i])
+				}
+				if tt.update && (record.KeyID != "key-"+record.ClusterID || record.Provider != "test") {
+					t.Fatalf("expected provider details of %s to be updated, got %+v", record.ClusterID, record)
+				}
+			}
+			if tt.update {
+				managed, _ := s.Get("managed")
+				if !managed.CreatedAt.Equal(mockNow) {
+					t.Fatalf("expected creation time of managed cluster to be kept, got %v", managed.CreatedAt)
+				}
+				unmanaged, _ := s.Get("unmanaged")
+				if !unmanaged.CreatedAt.Equal(mockNow.Add(time.Hour)) {
+					t.Fatalf("expected unmanaged cluster to be tracked from now, got %v", unmanaged.CreatedAt)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"time"
+)
+
+//Record State tracked for a cluster managed by the service
+type Record struct {
+	ClusterID string    `json:"clusterId"`
+	Provider  string    `json:"provider,omitempty"`
+	Account   string    `json:"account,omitempty"`
+	SubUser   string    `json:"subUser,omitempty"`
+	KeyID     string    `json:"keyId,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	RotatedAt time.Time `json:"rotatedAt,omitempty"`
+}
+
+//KeyIssuedAt Time the current API key of the cluster was issued
+func (r *Record) KeyIssuedAt() time.Time {
+	if r.RotatedAt.After(r.CreatedAt) {
+		return r.RotatedAt
+	}
+	return r.CreatedAt
+}
+
+//UpdateFrom Copy the provider details of a record described by an Inventory, keeping the timestamps
+func (r *Record) UpdateFrom(described *Record) {
+	r.Provider = described.Provider
+	r.Account = described.Account
+	r.SubUser = described.SubUser
+	r.KeyID = described.KeyID
+	r.Scopes = described.Scopes
+}
+
+//Store Persistent state of the clusters managed by the service
+//go:generate moq -out store_moq.go . Store
+type Store interface {
+	Get(clusterID string) (*Record, error)
+	Put(record *Record) error
+	Delete(clusterID string) error
+	List() ([]*Record, error)
+}
+
+//Inventory Provider able to list and describe the clusters it holds SMTP details for
+type Inventory interface {
+	List() ([]string, error)
+	Describe(clusterID string) (*Record, error)
+}
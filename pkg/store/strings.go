@@ -0,0 +1,27 @@
+package store
+
+import "time"
+
+const (
+	//EnvStorePath Name of the env var to retrieve the path of the state store file
+	EnvStorePath = "SMTP_SERVICE_STORE"
+	//DefaultStorePath Path of the state store file when none is configured
+	DefaultStorePath = "smtp-service-state.json"
+	//LockFileSuffix Suffix appended to the path of the state store file to form the path of its lock file
+	LockFileSuffix = ".lock"
+	//LockTimeout Time to wait for the lock of the state store file held by another process
+	LockTimeout = 30 * time.Second
+	//LockStaleAge Age after which a lock file is assumed to be left behind by a process that died holding it
+	LockStaleAge = 5 * time.Minute
+	//lockRetryInterval Time between attempts to take the lock of the state store file
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+const (
+	//StatusManaged The cluster is tracked in the store and exists in the provider
+	StatusManaged Status = "managed"
+	//StatusUnmanaged The cluster exists in the provider but is not tracked in the store, e.g. it was created manually
+	StatusUnmanaged Status = "unmanaged"
+	//StatusMissing The cluster is tracked in the store but no longer exists in the provider
+	StatusMissing Status = "missing"
+)